@@ -15,6 +15,7 @@
 package core
 
 import (
+	"application/core/interfaces"
 	"application/core/model"
 	"time"
 
@@ -38,11 +39,11 @@ func (a appAdmin) GetSurvey(id string, orgID string, appID string) (*model.Surve
 
 // GetSurvey returns surveys matching the provided query
 func (a appAdmin) GetSurveys(orgID string, appID string, userID *string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, []model.SurveyResponse, error) {
-	return a.app.shared.getSurveys(orgID, appID, userID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+	return a.app.shared.getSurveys(orgID, appID, userID, nil, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed, true)
 }
 
 // GetAllSurveyResponses returns survey responses matching the provided query
-func (a appAdmin) GetAllSurveyResponses(orgID string, appID string, surveyID string, userID string, externalIDs map[string]string, startDate *time.Time, endDate *time.Time, limit *int, offset *int) ([]model.SurveyResponse, error) {
+func (a appAdmin) GetAllSurveyResponses(orgID string, appID string, surveyID string, userID string, externalIDs map[string]string, startDate *time.Time, endDate *time.Time, limit *int, offset *int, validate bool) ([]model.SurveyResponse, error) {
 	var allResponses []model.SurveyResponse
 	var err error
 
@@ -69,6 +70,11 @@ func (a appAdmin) GetAllSurveyResponses(orgID string, appID string, surveyID str
 		return nil, errors.ErrorData(logutils.StatusInvalid, "user", &logutils.FieldArgs{"calendar_event_id": survey.CalendarEventID, "admin": false})
 	}
 
+	err = a.app.shared.checkRawSurveyResponses(*survey)
+	if err != nil {
+		return nil, err
+	}
+
 	allResponses, err = a.app.storage.GetSurveyResponses(&orgID, &appID, nil, []string{surveyID}, nil, startDate, endDate, limit, offset)
 	if err != nil {
 		return nil, err
@@ -81,11 +87,15 @@ func (a appAdmin) GetAllSurveyResponses(orgID string, appID string, surveyID str
 		}
 	}
 
+	if validate {
+		validateSurveyResponses(*survey, allResponses)
+	}
+
 	return allResponses, nil
 }
 
 // GetAllSurveysResponses returns survey responses matching the provided query
-func (a appAdmin) GetAllSurveysResponses(orgID string, appID string, surveyID string, userID string, externalIDs map[string]string, startDate *time.Time, endDate *time.Time, limit *int, offset *int) ([]model.SurveyResponse, error) {
+func (a appAdmin) GetAllSurveysResponses(orgID string, appID string, surveyID string, userID string, externalIDs map[string]string, startDate *time.Time, endDate *time.Time, limit *int, offset *int, validate bool) ([]model.SurveyResponse, error) {
 	var allResponses []model.SurveyResponse
 	var err error
 
@@ -111,22 +121,228 @@ func (a appAdmin) GetAllSurveysResponses(orgID string, appID string, surveyID st
 		}
 	}
 
+	if validate {
+		validateSurveyResponses(*survey, allResponses)
+	}
+
 	return allResponses, nil
 }
 
+// ExportSurveyResponses writes the responses to the survey with the provided ID as flattened rows
+func (a appAdmin) ExportSurveyResponses(orgID string, appID string, surveyID string, startDate *time.Time, endDate *time.Time, writer interfaces.ExportWriter) error {
+	survey, err := a.app.shared.getSurvey(surveyID, orgID, appID)
+	if err != nil {
+		return err
+	}
+
+	// Check if survey is sensitive
+	if survey.Sensitive {
+		return errors.Newf("Survey is sensitive and responses are not available")
+	}
+
+	return a.app.shared.exportSurveyResponses(*survey, startDate, endDate, writer)
+}
+
 // CreateSurvey creates a new survey
-func (a appAdmin) CreateSurvey(survey model.Survey, externalIDs map[string]string) (*model.Survey, error) {
-	return a.app.shared.createSurvey(survey, externalIDs)
+func (a appAdmin) CreateSurvey(survey model.Survey, externalIDs map[string]string, strict bool, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.createSurvey(survey, externalIDs, strict, audit)
 }
 
 // UpdateSurvey updates the provided survey
-func (a appAdmin) UpdateSurvey(survey model.Survey, userID string, externalIDs map[string]string) error {
-	return a.app.shared.updateSurvey(survey, userID, externalIDs, true)
+func (a appAdmin) UpdateSurvey(survey model.Survey, userID string, externalIDs map[string]string, strict bool, audit model.AuditContext) error {
+	return a.app.shared.updateSurvey(survey, userID, externalIDs, true, strict, audit)
+}
+
+// ValidateSurvey runs a static analysis of the provided survey definition
+func (a appAdmin) ValidateSurvey(survey model.Survey) model.SurveyLint {
+	return lintSurvey(survey)
 }
 
 // DeleteSurvey deletes the survey with the specified ID
-func (a appAdmin) DeleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string) error {
-	return a.app.shared.deleteSurvey(id, orgID, appID, userID, externalIDs, true)
+func (a appAdmin) DeleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, responsesPolicy string, audit model.AuditContext) error {
+	return a.app.shared.deleteSurvey(id, orgID, appID, userID, externalIDs, true, responsesPolicy, audit)
+}
+
+// PublishSurvey publishes the survey with the specified ID
+func (a appAdmin) PublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.publishSurvey(id, orgID, appID, userID, externalIDs, true, audit)
+}
+
+// UnpublishSurvey moves the survey with the specified ID back to draft
+func (a appAdmin) UnpublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.unpublishSurvey(id, orgID, appID, userID, externalIDs, true, audit)
+}
+
+// SetSurveyResponseWindowOverride forces the survey response window open (true) or closed (false). A nil override restores the survey dates.
+func (a appAdmin) SetSurveyResponseWindowOverride(id string, orgID string, appID string, override *bool, audit model.AuditContext) (*model.Survey, error) {
+	before, err := a.app.shared.getSurvey(id, orgID, appID)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": id, "app_id": appID, "org_id": orgID})
+	}
+
+	err = a.app.storage.UpdateSurveyResponseWindowOverride(id, orgID, appID, override)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, &logutils.FieldArgs{"id": id, "response_window_override": override}, err)
+	}
+
+	survey, err := a.app.shared.getSurvey(id, orgID, appID)
+	if err != nil {
+		return nil, err
+	}
+	a.app.shared.recordAuditEvent(audit, model.AuditEntitySurvey, id, orgID, appID, *before, *survey)
+	return survey, nil
+}
+
+// GetDeletedSurveys returns the surveys in the trash, most recently deleted first
+func (a appAdmin) GetDeletedSurveys(orgID string, appID string, limit *int, offset *int) ([]model.Survey, error) {
+	return a.app.storage.GetDeletedSurveys(orgID, appID, limit, offset)
+}
+
+// RestoreSurvey moves the survey with the specified ID out of the trash
+func (a appAdmin) RestoreSurvey(id string, orgID string, appID string, audit model.AuditContext) (*model.Survey, error) {
+	var restored *model.Survey
+	transaction := func(storage interfaces.Storage) error {
+		deleted, err := storage.GetDeletedSurvey(id, orgID, appID)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+		}
+
+		err = storage.RestoreSurvey(id, orgID, appID)
+		if err != nil {
+			return err
+		}
+
+		restored, err = storage.GetSurvey(id, orgID, appID)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+		}
+
+		// the responses to the survey are no longer marked as deleted, they are only deleted or archived when the survey is purged
+		_, err = storage.SetSurveyResponsesSurveyDeleted(id, orgID, appID, nil)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurveyResponse, &logutils.FieldArgs{"survey_id": id}, err)
+		}
+		return insertAuditEvent(storage, audit, model.AuditEntitySurvey, id, orgID, appID, *deleted, *restored)
+	}
+
+	err := a.app.storage.PerformTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// GetSurveyTemplates returns the templates matching the filter
+func (a appAdmin) GetSurveyTemplates(orgID string, appID string, filter model.SurveyTemplateFilter) ([]model.Survey, error) {
+	return a.app.storage.GetSurveyTemplates(orgID, appID, filter)
+}
+
+// SetSurveyTemplate marks the survey with the specified ID as a template and lists it in the catalog or removes it from the catalog
+func (a appAdmin) SetSurveyTemplate(id string, orgID string, appID string, userID string, template model.SurveyTemplate, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.setSurveyTemplate(id, orgID, appID, userID, true, &template, audit)
+}
+
+// RemoveSurveyTemplate removes the template mark from the survey with the specified ID
+func (a appAdmin) RemoveSurveyTemplate(id string, orgID string, appID string, userID string, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.setSurveyTemplate(id, orgID, appID, userID, true, nil, audit)
+}
+
+// CloneSurvey creates a new survey from the template with the specified ID
+func (a appAdmin) CloneSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, overrides model.SurveyCloneOverrides, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.cloneSurvey(id, orgID, appID, userID, externalIDs, true, overrides, audit)
+}
+
+// GetEventSurveySubscriptions returns the event survey subscriptions, sorted by event end date
+func (a appAdmin) GetEventSurveySubscriptions(orgID string, appID string, status *string, limit *int, offset *int) ([]model.EventSurveySubscription, error) {
+	return a.app.storage.GetEventSurveySubscriptions(orgID, appID, status, limit, offset)
+}
+
+// GetSurveyReminders returns the reminders sent for a survey, newest first
+func (a appAdmin) GetSurveyReminders(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyReminder, error) {
+	return a.app.storage.GetSurveyReminders(surveyID, orgID, appID, limit, offset)
+}
+
+// GetSurveyAudience lists the known users matching the audience of a survey
+func (a appAdmin) GetSurveyAudience(surveyID string, orgID string, appID string) (*model.SurveyAudiencePreview, error) {
+	survey, err := a.app.storage.GetSurvey(surveyID, orgID, appID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+	}
+	if survey == nil {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": surveyID, "app_id": appID, "org_id": orgID})
+	}
+	return a.app.surveyAudiencePreview(*survey)
+}
+
+// GetSurveyRevisions returns the revisions of the survey with the specified ID, newest first
+func (a appAdmin) GetSurveyRevisions(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyRevision, error) {
+	return a.app.storage.GetSurveyRevisions(surveyID, orgID, appID, limit, offset)
+}
+
+// GetSurveyRevision returns a single revision of the survey with the specified ID
+func (a appAdmin) GetSurveyRevision(surveyID string, orgID string, appID string, revision int) (*model.SurveyRevision, error) {
+	return a.app.storage.GetSurveyRevision(surveyID, orgID, appID, revision)
+}
+
+// GetSurveyRevisionDiff returns the changes made to the survey definition between two revisions
+func (a appAdmin) GetSurveyRevisionDiff(surveyID string, orgID string, appID string, from int, to int) (*model.SurveyRevisionDiff, error) {
+	fromRevision, err := a.app.storage.GetSurveyRevision(surveyID, orgID, appID, from)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurveyRevision, &logutils.FieldArgs{"survey_id": surveyID, "revision": from}, err)
+	}
+	toRevision, err := a.app.storage.GetSurveyRevision(surveyID, orgID, appID, to)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurveyRevision, &logutils.FieldArgs{"survey_id": surveyID, "revision": to}, err)
+	}
+
+	changes, err := diffSurveys(fromRevision.Survey, toRevision.Survey)
+	if err != nil {
+		return nil, err
+	}
+	return &model.SurveyRevisionDiff{SurveyID: surveyID, From: from, To: to, Changes: changes}, nil
+}
+
+// RestoreSurveyRevision restores the survey definition of the provided revision. The restored definition is saved as a new revision.
+func (a appAdmin) RestoreSurveyRevision(surveyID string, orgID string, appID string, revision int, userID string, audit model.AuditContext) (*model.Survey, error) {
+	var restored *model.Survey
+	transaction := func(storage interfaces.Storage) error {
+		current, err := storage.GetSurvey(surveyID, orgID, appID)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+		}
+		if current == nil {
+			return errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": surveyID, "app_id": appID, "org_id": orgID})
+		}
+		surveyRevision, err := storage.GetSurveyRevision(surveyID, orgID, appID, revision)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurveyRevision, &logutils.FieldArgs{"survey_id": surveyID, "revision": revision}, err)
+		}
+
+		restoredDefinition := restoredSurvey(*current, surveyRevision.Survey)
+		err = validateSurveyAudience(restoredDefinition)
+		if err != nil {
+			return err
+		}
+		err = updateSurveyWithRevision(storage, restoredDefinition, userID, true, &revision, audit)
+		if err != nil {
+			return err
+		}
+
+		restored, err = storage.GetSurvey(surveyID, orgID, appID)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+		}
+		return nil
+	}
+
+	err := a.app.storage.PerformTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
 }
 
 // GetAlertContacts returns all alert contacts for the provided app/org
@@ -140,21 +356,69 @@ func (a appAdmin) GetAlertContact(id string, orgID string, appID string) (*model
 }
 
 // CreateAlertContact creates a new alert contact
-func (a appAdmin) CreateAlertContact(alertContact model.AlertContact) (*model.AlertContact, error) {
+func (a appAdmin) CreateAlertContact(alertContact model.AlertContact, audit model.AuditContext) (*model.AlertContact, error) {
+	err := a.app.validateAlertContact(alertContact)
+	if err != nil {
+		return nil, err
+	}
+
 	alertContact.ID = uuid.NewString()
 	alertContact.DateCreated = time.Now().UTC()
 	alertContact.DateUpdated = nil
-	return a.app.storage.CreateAlertContact(alertContact)
+	created, err := a.app.storage.CreateAlertContact(alertContact)
+	if err != nil {
+		return nil, err
+	}
+
+	a.app.shared.recordAuditEvent(audit, model.AuditEntityAlertContact, created.ID, created.OrgID, created.AppID, nil, *created)
+	return created, nil
 }
 
 // UpdateAlertContact updates an existing alert contact
-func (a appAdmin) UpdateAlertContact(alertContact model.AlertContact) error {
-	return a.app.storage.UpdateAlertContact(alertContact)
+func (a appAdmin) UpdateAlertContact(alertContact model.AlertContact, audit model.AuditContext) error {
+	err := a.app.validateAlertContact(alertContact)
+	if err != nil {
+		return err
+	}
+
+	before, err := a.app.storage.GetAlertContact(alertContact.ID, alertContact.OrgID, alertContact.AppID)
+	if err != nil {
+		return err
+	}
+
+	err = a.app.storage.UpdateAlertContact(alertContact)
+	if err != nil {
+		return err
+	}
+
+	after := *before
+	after.Key = alertContact.Key
+	after.Type = alertContact.Type
+	after.Address = alertContact.Address
+	after.Params = alertContact.Params
+	a.app.shared.recordAuditEvent(audit, model.AuditEntityAlertContact, alertContact.ID, alertContact.OrgID, alertContact.AppID, *before, after)
+	return nil
 }
 
 // DeleteAlertContact deletes an existing alert contact with the provided id
-func (a appAdmin) DeleteAlertContact(id string, orgID string, appID string) error {
-	return a.app.storage.DeleteAlertContact(id, orgID, appID)
+func (a appAdmin) DeleteAlertContact(id string, orgID string, appID string, audit model.AuditContext) error {
+	before, err := a.app.storage.GetAlertContact(id, orgID, appID)
+	if err != nil {
+		return err
+	}
+
+	err = a.app.storage.DeleteAlertContact(id, orgID, appID)
+	if err != nil {
+		return err
+	}
+
+	a.app.shared.recordAuditEvent(audit, model.AuditEntityAlertContact, id, orgID, appID, *before, nil)
+	return nil
+}
+
+// GetAuditEvents returns the audit events of the provided app/org matching the provided filter, newest first
+func (a appAdmin) GetAuditEvents(orgID string, appID string, filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	return a.app.storage.GetAuditEvents(orgID, appID, filter)
 }
 
 func (a appAdmin) GetConfig(id string, claims *tokenauth.Claims) (*model.Config, error) {
@@ -189,7 +453,7 @@ func (a appAdmin) GetConfigs(configType *string, claims *tokenauth.Claims) ([]mo
 	return allowedConfigs, nil
 }
 
-func (a appAdmin) CreateConfig(config model.Config, claims *tokenauth.Claims) (*model.Config, error) {
+func (a appAdmin) CreateConfig(config model.Config, claims *tokenauth.Claims, audit model.AuditContext) (*model.Config, error) {
 	// must be a system config if applying to all orgs
 	if config.OrgID == authutils.AllOrgs && !config.System {
 		return nil, errors.ErrorData(logutils.StatusInvalid, "config system status", &logutils.FieldArgs{"config.org_id": authutils.AllOrgs})
@@ -206,10 +470,12 @@ func (a appAdmin) CreateConfig(config model.Config, claims *tokenauth.Claims) (*
 	if err != nil {
 		return nil, errors.WrapErrorAction(logutils.ActionInsert, model.TypeConfig, nil, err)
 	}
+
+	a.app.shared.recordAuditEvent(audit, model.AuditEntityConfig, config.ID, config.OrgID, config.AppID, nil, config)
 	return &config, nil
 }
 
-func (a appAdmin) UpdateConfig(config model.Config, claims *tokenauth.Claims) error {
+func (a appAdmin) UpdateConfig(config model.Config, claims *tokenauth.Claims, audit model.AuditContext) error {
 	// must be a system config if applying to all orgs
 	if config.OrgID == authutils.AllOrgs && !config.System {
 		return errors.ErrorData(logutils.StatusInvalid, "config system status", &logutils.FieldArgs{"config.org_id": authutils.AllOrgs})
@@ -234,16 +500,19 @@ func (a appAdmin) UpdateConfig(config model.Config, claims *tokenauth.Claims) er
 
 	now := time.Now().UTC()
 	config.ID = oldConfig.ID
+	config.DateCreated = oldConfig.DateCreated
 	config.DateUpdated = &now
 
 	err = a.app.storage.UpdateConfig(config)
 	if err != nil {
 		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeConfig, nil, err)
 	}
+
+	a.app.shared.recordAuditEvent(audit, model.AuditEntityConfig, config.ID, config.OrgID, config.AppID, *oldConfig, config)
 	return nil
 }
 
-func (a appAdmin) DeleteConfig(id string, claims *tokenauth.Claims) error {
+func (a appAdmin) DeleteConfig(id string, claims *tokenauth.Claims, audit model.AuditContext) error {
 	config, err := a.app.storage.FindConfigByID(id)
 	if err != nil {
 		return errors.WrapErrorAction(logutils.ActionFind, model.TypeConfig, nil, err)
@@ -261,6 +530,8 @@ func (a appAdmin) DeleteConfig(id string, claims *tokenauth.Claims) error {
 	if err != nil {
 		return errors.WrapErrorAction(logutils.ActionDelete, model.TypeConfig, nil, err)
 	}
+
+	a.app.shared.recordAuditEvent(audit, model.AuditEntityConfig, id, config.OrgID, config.AppID, *config, nil)
 	return nil
 }
 
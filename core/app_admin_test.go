@@ -13,3 +13,299 @@
 // limitations under the License.
 
 package core_test
+
+import (
+	"application/core/interfaces/mocks"
+	"application/core/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rokwire/core-auth-library-go/v3/tokenauth"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAppAdmin_UpdateSurvey_Revisions(t *testing.T) {
+	current := model.Survey{ID: "survey", OrgID: "org", AppID: "app", CreatorID: "creator", Title: "Old"}
+	updated := model.Survey{ID: "survey", OrgID: "org", AppID: "app", CreatorID: "creator", Title: "New", Revision: 1}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	mockPerformTransaction(storage)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&current, nil).Once()
+	storage.On("UpdateSurvey", mock.MatchedBy(func(survey model.Survey) bool { return survey.Revision == 1 }), true).Return(nil)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&updated, nil).Once()
+	var revisions []int
+	storage.On("InsertSurveyRevision", mock.AnythingOfType("model.SurveyRevision")).Run(func(args mock.Arguments) {
+		revisions = append(revisions, args.Get(0).(model.SurveyRevision).Revision)
+	}).Return(nil)
+	app := buildTestApplication(storage)
+
+	err := app.Admin.UpdateSurvey(model.Survey{ID: "survey", OrgID: "org", AppID: "app", CreatorID: "creator", Title: "New"}, "admin", nil, false, model.AuditContext{})
+	if err != nil {
+		t.Fatalf("Admin.UpdateSurvey() error = %v", err)
+	}
+	// the untracked definition is kept as revision 0 before the update is stored as revision 1
+	if want := []int{0, 1}; !reflect.DeepEqual(revisions, want) {
+		t.Errorf("Admin.UpdateSurvey() revisions = %v, want %v", revisions, want)
+	}
+}
+
+func TestAppAdmin_GetSurveyRevisionDiff(t *testing.T) {
+	from := model.Survey{Title: "Check-in", Revision: 1, Data: map[string]model.SurveyData{
+		"mood":  {Type: model.SurveyDataTypeText, Text: "How are you?"},
+		"sleep": {Type: model.SurveyDataTypeNumeric, Text: "Hours slept"},
+	}}
+	to := model.Survey{Title: "Check-in", Revision: 2, Data: map[string]model.SurveyData{
+		"mood":   {Type: model.SurveyDataTypeText, Text: "How do you feel?"},
+		"stress": {Type: model.SurveyDataTypeTrueFalse, Text: "Stressed?"},
+	}}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurveyRevision", "survey", "org", "app", 1).Return(&model.SurveyRevision{Revision: 1, Survey: from}, nil)
+	storage.On("GetSurveyRevision", "survey", "org", "app", 2).Return(&model.SurveyRevision{Revision: 2, Survey: to}, nil)
+	app := buildTestApplication(storage)
+
+	diff, err := app.Admin.GetSurveyRevisionDiff("survey", "org", "app", 1, 2)
+	if err != nil {
+		t.Fatalf("Admin.GetSurveyRevisionDiff() error = %v", err)
+	}
+	got := make(map[string]string)
+	for _, change := range diff.Changes {
+		got[change.Path] = change.Type
+	}
+	want := map[string]string{"data.mood.text": model.SurveyChangeModified, "data.sleep": model.SurveyChangeRemoved, "data.stress": model.SurveyChangeAdded}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Admin.GetSurveyRevisionDiff() changes = %v, want %v", got, want)
+	}
+}
+
+type testExportWriter struct {
+	rows [][]string
+}
+
+func (w *testExportWriter) WriteHeader(columns []string) error {
+	w.rows = append(w.rows, columns)
+	return nil
+}
+
+func (w *testExportWriter) WriteRow(values []string) error {
+	w.rows = append(w.rows, values)
+	return nil
+}
+
+func TestAppAdmin_ExportSurveyResponses(t *testing.T) {
+	first, second := "first", "second"
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", Anonymous: true, DefaultDataKey: &first, Data: map[string]model.SurveyData{
+		"first":  {Type: model.SurveyDataTypeMultipleChoice, Text: "Pick some", DefaultFollowUpKey: &second},
+		"second": {Type: model.SurveyDataTypeText},
+		"extra":  {Type: model.SurveyDataTypeNumeric, Text: "How many?"},
+	}}
+	response := model.SurveyResponse{ID: "response", UserID: "user", SurveyRevision: 3, DateCreated: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Survey: model.Survey{Data: map[string]model.SurveyData{
+			"first":  {Response: []interface{}{"a", "b"}},
+			"second": {Response: "text"},
+			"extra":  {Response: int32(2)},
+		}}}
+	formulas := model.SurveyResponse{ID: "formulas", SurveyRevision: 3, DateCreated: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Survey: model.Survey{Data: map[string]model.SurveyData{
+			"first":  {Response: []interface{}{"@SUM(A1)", "b"}},
+			"second": {Response: "=HYPERLINK(\"http://example.com\")"},
+			"extra":  {Response: -2.0},
+		}}}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+	storage.On("StreamSurveyResponses", "org", "app", "survey", (*time.Time)(nil), (*time.Time)(nil), mock.Anything).Return(
+		func(orgID string, appID string, surveyID string, startDate *time.Time, endDate *time.Time, handle func(model.SurveyResponse) error) error {
+			if err := handle(response); err != nil {
+				return err
+			}
+			return handle(formulas)
+		})
+	storage.On("FindConfig", model.ConfigTypePrivacy, "app", "org").Return(&model.Config{Type: model.ConfigTypePrivacy, Data: model.PrivacyConfigData{MinGroupSize: 2}}, nil)
+	storage.On("CountSurveyResponsesBySurvey", "org", "app", "survey").Return(int64(2), nil).Once()
+	app := buildTestApplication(storage)
+
+	writer := testExportWriter{}
+	err := app.Admin.ExportSurveyResponses("org", "app", "survey", nil, nil, &writer)
+	if err != nil {
+		t.Fatalf("Admin.ExportSurveyResponses() error = %v", err)
+	}
+	want := [][]string{
+		{"id", "date_created", "survey_revision", "Pick some", "second", "How many?"},
+		{"response", "2024-05-01T12:00:00Z", "3", "a; b", "text", "2"},
+		// text answers are not evaluated as formulas, numbers are kept as they are
+		{"formulas", "2024-05-01T12:00:00Z", "3", "'@SUM(A1); b", "'=HYPERLINK(\"http://example.com\")", "-2"},
+	}
+	if !reflect.DeepEqual(writer.rows, want) {
+		t.Errorf("Admin.ExportSurveyResponses() rows = %v, want %v", writer.rows, want)
+	}
+
+	// the survey is anonymous, so its responses are withheld until enough users responded
+	storage.On("CountSurveyResponsesBySurvey", "org", "app", "survey").Return(int64(1), nil).Once()
+	err = app.Admin.ExportSurveyResponses("org", "app", "survey", nil, nil, &testExportWriter{})
+	if _, ok := err.(*model.SurveyPrivacyError); !ok {
+		t.Errorf("Admin.ExportSurveyResponses() error = %v, want privacy error", err)
+	}
+}
+
+func TestAppAdmin_UpdateConfig_Audit(t *testing.T) {
+	current := model.Config{ID: "config", Type: model.ConfigTypeEnv, AppID: "app", OrgID: "org",
+		Data: model.EnvConfigData{ExternalID: "uin", AnalyticsToken: "old-token"}}
+	audit := model.AuditContext{Actor: model.AuditActor{AccountID: "admin", AppID: "app", OrgID: "org", Admin: true}, RequestID: "request"}
+
+	storage := mocks.NewStorage(t)
+	storage.On("FindConfig", model.ConfigTypeEnv, "app", "org").Return(&current, nil)
+	storage.On("UpdateConfig", mock.AnythingOfType("model.Config")).Return(nil)
+	var events []model.AuditEvent
+	storage.On("InsertAuditEvent", mock.AnythingOfType("model.AuditEvent")).Run(func(args mock.Arguments) {
+		events = append(events, args.Get(0).(model.AuditEvent))
+	}).Return(nil)
+	app := buildTestApplication(storage)
+
+	config := model.Config{Type: model.ConfigTypeEnv, AppID: "app", OrgID: "org", Data: model.EnvConfigData{ExternalID: "uin", AnalyticsToken: "new-token"}}
+	err := app.Admin.UpdateConfig(config, &tokenauth.Claims{AppID: "app", OrgID: "org", Admin: true}, audit)
+	if err != nil {
+		t.Fatalf("Admin.UpdateConfig() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Admin.UpdateConfig() audit events = %d, want 1", len(events))
+	}
+	event := events[0]
+	if event.Entity != model.AuditEntityConfig || event.EntityID != "config" || event.Action != model.AuditActionUpdate ||
+		event.Actor != audit.Actor || event.RequestID != "request" {
+		t.Errorf("Admin.UpdateConfig() audit event = %+v", event)
+	}
+	// only the changed secret is recorded, as a digest
+	if len(event.Changes) != 1 || event.Changes[0].Path != "data.analytics_token" {
+		t.Fatalf("Admin.UpdateConfig() audit changes = %+v", event.Changes)
+	}
+	for _, value := range []interface{}{event.Changes[0].Old, event.Changes[0].New} {
+		if digest, ok := value.(string); !ok || !strings.HasPrefix(digest, "sha256:") {
+			t.Errorf("Admin.UpdateConfig() audit change value = %v, want a digest", value)
+		}
+	}
+}
+
+func TestAppAdmin_CreateAlertContact_Audit(t *testing.T) {
+	contact := model.AlertContact{OrgID: "org", AppID: "app", Key: "staff", Type: model.AlertChannelWebhook, Address: "https://example.com/alerts",
+		Params: map[string]interface{}{"secret": "signing-secret", "header": "X-Source"}}
+
+	storage := mocks.NewStorage(t)
+	storage.On("CreateAlertContact", mock.AnythingOfType("model.AlertContact")).Return(func(alertContact model.AlertContact) *model.AlertContact {
+		return &alertContact
+	}, nil)
+	var events []model.AuditEvent
+	storage.On("InsertAuditEvent", mock.AnythingOfType("model.AuditEvent")).Run(func(args mock.Arguments) {
+		events = append(events, args.Get(0).(model.AuditEvent))
+	}).Return(nil)
+	app := buildTestApplication(storage)
+
+	_, err := app.Admin.CreateAlertContact(contact, model.AuditContext{})
+	if err != nil {
+		t.Fatalf("Admin.CreateAlertContact() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Admin.CreateAlertContact() audit events = %d, want 1", len(events))
+	}
+	// the contact key and the other params are recorded as they are, the secret only as a digest
+	changes := map[string]interface{}{}
+	for _, change := range events[0].Changes {
+		changes[change.Path] = change.New
+	}
+	params, _ := changes["params"].(map[string]interface{})
+	if changes["key"] != "staff" || params["header"] != "X-Source" {
+		t.Errorf("Admin.CreateAlertContact() audit changes = %+v", events[0].Changes)
+	}
+	if digest, ok := params["secret"].(string); !ok || !strings.HasPrefix(digest, "sha256:") {
+		t.Errorf("Admin.CreateAlertContact() audit secret = %v, want a digest", params["secret"])
+	}
+}
+
+func TestAppAdmin_RestoreSurvey(t *testing.T) {
+	deletedAt := time.Now().UTC()
+	deleted := model.Survey{ID: "survey", OrgID: "org", AppID: "app", Title: "Survey", CalendarEventID: "event", DeletedAt: &deletedAt}
+	restored := model.Survey{ID: "survey", OrgID: "org", AppID: "app", Title: "Survey", CalendarEventID: "event"}
+
+	tests := []struct {
+		name       string
+		restoreErr error
+		wantErr    bool
+	}{
+		{name: "restored"},
+		{name: "calendar event taken", restoreErr: &model.DuplicateKeyError{Type: string(model.TypeSurvey)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			mockPerformTransaction(storage)
+			storage.On("GetDeletedSurvey", "survey", "org", "app").Return(&deleted, nil)
+			storage.On("RestoreSurvey", "survey", "org", "app").Return(tt.restoreErr)
+			if !tt.wantErr {
+				storage.On("GetSurvey", "survey", "org", "app").Return(&restored, nil)
+				storage.On("SetSurveyResponsesSurveyDeleted", "survey", "org", "app", (*time.Time)(nil)).Return(int64(2), nil)
+				mockInsertAuditEvent(storage)
+			}
+			app := buildTestApplication(storage)
+
+			survey, err := app.Admin.RestoreSurvey("survey", "org", "app", model.AuditContext{})
+			if tt.wantErr {
+				if _, ok := err.(*model.DuplicateKeyError); !ok {
+					t.Fatalf("Admin.RestoreSurvey() error = %v, want duplicate key error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Admin.RestoreSurvey() error = %v", err)
+			}
+			if survey.DeletedAt != nil || survey.CalendarEventID != "event" {
+				t.Errorf("Admin.RestoreSurvey() = %+v", survey)
+			}
+		})
+	}
+}
+
+func TestAppAdmin_DeleteSurvey_Responses(t *testing.T) {
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", CreatorID: "creator", Title: "Survey"}
+
+	tests := []struct {
+		name      string
+		requested string
+		config    string
+		want      string
+	}{
+		{name: "default keeps responses", want: model.DeletedSurveyResponsesKeep},
+		{name: "config cascade", config: model.DeletedSurveyResponsesCascade, want: model.DeletedSurveyResponsesCascade},
+		{name: "request overrides config", requested: model.DeletedSurveyResponsesArchive, config: model.DeletedSurveyResponsesCascade, want: model.DeletedSurveyResponsesArchive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			mockPerformTransaction(storage)
+			mockInsertAuditEvent(storage)
+			config := model.Config{Type: model.ConfigTypeEnv, Data: model.EnvConfigData{DeletedSurveyResponses: tt.config}}
+			storage.On("FindConfig", model.ConfigTypeEnv, mock.Anything, mock.Anything).Return(&config, nil).Maybe()
+			storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+			// the policy is recorded with the survey in the trash and the responses are only marked as deleted, so that
+			// restoring the survey restores them. They are deleted or archived when the survey is purged.
+			storage.On("DeleteSurvey", "survey", "org", "app", "admin", true, tt.want).Return(nil)
+			storage.On("SetSurveyResponsesSurveyDeleted", "survey", "org", "app", mock.AnythingOfType("*time.Time")).Return(int64(2), nil)
+			app := buildTestApplication(storage)
+
+			err := app.Admin.DeleteSurvey("survey", "org", "app", "admin", nil, tt.requested, model.AuditContext{})
+			if err != nil {
+				t.Fatalf("Admin.DeleteSurvey() error = %v", err)
+			}
+		})
+	}
+
+	t.Run("invalid policy", func(t *testing.T) {
+		app := buildTestApplication(mocks.NewStorage(t))
+		err := app.Admin.DeleteSurvey("survey", "org", "app", "admin", nil, "shred", model.AuditContext{})
+		if err == nil {
+			t.Errorf("Admin.DeleteSurvey() error = nil, want invalid policy error")
+		}
+	})
+}
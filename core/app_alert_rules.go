@@ -0,0 +1,178 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"application/utils"
+	"bytes"
+	"text/template"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// surveyAlertTemplateData is the data survey alert rule templates are rendered with
+type surveyAlertTemplateData struct {
+	SurveyID    string
+	SurveyTitle string
+	ResponseID  string
+	// UserID is empty for anonymous surveys
+	UserID      string
+	DateCreated time.Time
+
+	Section   string
+	Score     float64
+	Threshold float64
+	Key       string
+	Answer    interface{}
+
+	Scores map[string]float64
+}
+
+// validateSurveyAlertRules checks that the alert rules of a survey definition can be evaluated and rendered
+func validateSurveyAlertRules(survey model.Survey) error {
+	for i, rule := range survey.AlertRules {
+		if rule.ContactKey == "" {
+			return errors.ErrorData(logutils.StatusMissing, "alert rule contact key", &logutils.FieldArgs{"index": i})
+		}
+		switch rule.Type {
+		case model.AlertRuleScore:
+			if rule.Threshold == nil {
+				return errors.ErrorData(logutils.StatusMissing, "alert rule threshold", &logutils.FieldArgs{"index": i})
+			}
+		case model.AlertRuleAnswer:
+			if _, ok := survey.Data[rule.Key]; !ok {
+				return errors.ErrorData(logutils.StatusInvalid, "alert rule key", &logutils.FieldArgs{"index": i, "key": rule.Key})
+			}
+			if rule.Value == nil {
+				return errors.ErrorData(logutils.StatusMissing, "alert rule value", &logutils.FieldArgs{"index": i})
+			}
+		default:
+			return errors.ErrorData(logutils.StatusInvalid, "alert rule type", &logutils.FieldArgs{"index": i, "type": rule.Type})
+		}
+		if rule.Body == "" {
+			return errors.ErrorData(logutils.StatusMissing, "alert rule body", &logutils.FieldArgs{"index": i})
+		}
+		for _, text := range []string{rule.Subject, rule.Body} {
+			if _, err := template.New("alert").Parse(text); err != nil {
+				return errors.WrapErrorData(logutils.StatusInvalid, "alert rule template", &logutils.FieldArgs{"index": i}, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchSurveyAlertRule returns true and the template data if the evaluated survey response matches the rule
+func matchSurveyAlertRule(rule model.SurveyAlertRule, survey model.Survey, surveyResponse model.SurveyResponse) (bool, surveyAlertTemplateData) {
+	data := surveyAlertTemplateData{SurveyID: survey.ID, SurveyTitle: survey.Title, ResponseID: surveyResponse.ID, DateCreated: surveyResponse.DateCreated,
+		Section: rule.Section, Key: rule.Key, Scores: map[string]float64{}}
+	if !survey.Anonymous {
+		data.UserID = surveyResponse.UserID
+	}
+	if stats := surveyResponse.Survey.SurveyStats; stats != nil && stats.Scores != nil {
+		data.Scores = stats.Scores
+	}
+
+	switch rule.Type {
+	case model.AlertRuleScore:
+		if rule.Threshold == nil {
+			return false, data
+		}
+		data.Threshold = *rule.Threshold
+		if rule.Section == "" {
+			for _, score := range data.Scores {
+				data.Score += score
+			}
+		} else {
+			score, ok := data.Scores[rule.Section]
+			if !ok {
+				return false, data
+			}
+			data.Score = score
+		}
+		return data.Score+scoreTolerance >= data.Threshold, data
+	case model.AlertRuleAnswer:
+		answer := utils.NormalizeValue(surveyResponse.Survey.Data[rule.Key].Response)
+		data.Answer = answer
+		value := utils.NormalizeValue(rule.Value)
+		for _, item := range responseValues(answer) {
+			if utils.ValuesEqual(value, item) {
+				return true, data
+			}
+		}
+	}
+	return false, data
+}
+
+// renderSurveyAlert renders the templates of a matched alert rule into the alert sent to its contacts
+func renderSurveyAlert(rule model.SurveyAlertRule, survey model.Survey, data surveyAlertTemplateData) (*model.SurveyAlert, error) {
+	subject := survey.Title
+	if rule.Subject != "" {
+		rendered, err := renderAlertTemplate(rule.Subject, data)
+		if err != nil {
+			return nil, err
+		}
+		subject = rendered
+	}
+	body, err := renderAlertTemplate(rule.Body, data)
+	if err != nil {
+		return nil, err
+	}
+
+	content := map[string]interface{}{"subject": subject, "body": body, "type": rule.Type, "survey_id": survey.ID, "response_id": data.ResponseID}
+	return &model.SurveyAlert{OrgID: survey.OrgID, AppID: survey.AppID, ContactKey: rule.ContactKey, Content: content}, nil
+}
+
+func renderAlertTemplate(text string, data surveyAlertTemplateData) (string, error) {
+	tmpl, err := template.New("alert").Parse(text)
+	if err != nil {
+		return "", errors.WrapErrorAction(logutils.ActionParse, "alert rule template", nil, err)
+	}
+	var rendered bytes.Buffer
+	err = tmpl.Execute(&rendered, data)
+	if err != nil {
+		return "", errors.WrapErrorAction("rendering", "alert rule template", nil, err)
+	}
+	return rendered.String(), nil
+}
+
+// triggerSurveyAlerts sends the alerts of the rules matched by a new survey response. Alert failures are logged and do
+// not fail the response.
+func (a *Application) triggerSurveyAlerts(survey model.Survey, surveyResponse model.SurveyResponse) {
+	for _, rule := range survey.AlertRules {
+		matched, data := matchSurveyAlertRule(rule, survey, surveyResponse)
+		if !matched {
+			continue
+		}
+
+		alert, err := renderSurveyAlert(rule, survey, data)
+		if err != nil {
+			a.logger.Errorf("error rendering %s alert for survey %s response %s - %s", rule.Type, survey.ID, surveyResponse.ID, err)
+			continue
+		}
+		deliveries, err := a.sendSurveyAlert(*alert)
+		if err != nil {
+			a.logger.Errorf("error sending %s alert for survey %s response %s - %s", rule.Type, survey.ID, surveyResponse.ID, err)
+			continue
+		}
+		for _, delivery := range deliveries {
+			if delivery.Status == model.AlertDeliveryFailed || delivery.Status == model.AlertDeliveryInvalid {
+				a.logger.Errorf("survey %s alert to contact %s was not delivered (%s) - %s", survey.ID, delivery.ContactID, delivery.Status, delivery.Error)
+			}
+		}
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	// webhookSignatureHeader carries the hex encoded HMAC-SHA256 of "<timestamp>.<body>" signed with the secret param of the contact
+	webhookSignatureHeader = "X-Surveys-Signature"
+	// webhookTimestampHeader carries the unix time the webhook was signed at
+	webhookTimestampHeader = "X-Surveys-Timestamp"
+)
+
+// phoneNumberPattern matches phone numbers in E.164 format
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// alertChannel delivers survey alerts to the alert contacts of one type
+type alertChannel interface {
+	// validateContact checks the address and params of a contact
+	validateContact(contact model.AlertContact) error
+	// send delivers the alert to the contact and returns the delivery status. Content that the channel cannot deliver
+	// returns the invalid status.
+	send(contact model.AlertContact, alert model.SurveyAlert) (string, error)
+}
+
+// newAlertChannels returns the alert channels by alert contact type
+func newAlertChannels(app *Application) map[string]alertChannel {
+	return map[string]alertChannel{
+		model.AlertChannelEmail:   emailAlertChannel{app: app},
+		model.AlertChannelPush:    pushAlertChannel{app: app},
+		model.AlertChannelWebhook: webhookAlertChannel{app: app},
+		model.AlertChannelSMS:     smsAlertChannel{app: app},
+	}
+}
+
+// validateAlertContact checks that the contact has a supported type and the address and params its channel requires
+func (a *Application) validateAlertContact(contact model.AlertContact) error {
+	channel, ok := a.alertChannels[contact.Type]
+	if !ok {
+		return errors.ErrorData(logutils.StatusInvalid, "alert contact type", logutils.StringArgs(contact.Type))
+	}
+	return channel.validateContact(contact)
+}
+
+// sendSurveyAlert sends the alert to all the alert contacts sharing its contact key. A contact that cannot be reached
+// does not stop the delivery to the others, the result of each delivery is returned.
+func (a *Application) sendSurveyAlert(alert model.SurveyAlert) ([]model.AlertDelivery, error) {
+	contacts, err := a.storage.GetAlertContactsByKey(alert.ContactKey, alert.OrgID, alert.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]model.AlertDelivery, len(contacts))
+	for i, contact := range contacts {
+		deliveries[i] = a.deliverSurveyAlert(contact, alert)
+	}
+	return deliveries, nil
+}
+
+func (a *Application) deliverSurveyAlert(contact model.AlertContact, alert model.SurveyAlert) model.AlertDelivery {
+	delivery := model.AlertDelivery{ContactID: contact.ID, Type: contact.Type, Address: contact.Address}
+
+	err := a.validateAlertContact(contact)
+	if err != nil {
+		delivery.Status = model.AlertDeliveryInvalid
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	delivery.Status, err = a.alertChannels[contact.Type].send(contact, alert)
+	if err != nil {
+		delivery.Error = err.Error()
+		a.logger.Errorf("error sending survey alert %s to contact %s - %s", alert.ContactKey, contact.ID, err)
+	}
+	return delivery
+}
+
+// alertContentString returns the string content field or an error if it is missing
+func alertContentString(alert model.SurveyAlert, field string) (string, error) {
+	value, ok := alert.Content[field].(string)
+	if !ok || value == "" {
+		return "", errors.ErrorData(logutils.StatusMissing, logutils.MessageDataType(field), nil)
+	}
+	return value, nil
+}
+
+// alertContactParam returns the string param of the contact. Params of other types are invalid.
+func alertContactParam(contact model.AlertContact, name string) (string, error) {
+	raw, ok := contact.Params[name]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	value, ok := raw.(string)
+	if !ok {
+		return "", errors.ErrorData(logutils.StatusInvalid, "alert contact param", &logutils.FieldArgs{"name": name})
+	}
+	return value, nil
+}
+
+// emailAlertChannel sends the subject and body of alerts by email through the Notifications BB
+type emailAlertChannel struct {
+	app *Application
+}
+
+func (c emailAlertChannel) validateContact(contact model.AlertContact) error {
+	if _, err := mail.ParseAddress(contact.Address); err != nil {
+		return errors.WrapErrorData(logutils.StatusInvalid, "email address", logutils.StringArgs(contact.Address), err)
+	}
+	return nil
+}
+
+func (c emailAlertChannel) send(contact model.AlertContact, alert model.SurveyAlert) (string, error) {
+	subject, err := alertContentString(alert, "subject")
+	if err != nil {
+		return model.AlertDeliveryInvalid, err
+	}
+	body, err := alertContentString(alert, "body")
+	if err != nil {
+		return model.AlertDeliveryInvalid, err
+	}
+	c.app.notifications.SendMail(contact.Address, subject, body)
+	return model.AlertDeliveryQueued, nil
+}
+
+// pushAlertChannel sends the subject and body of alerts as a push notification through the Notifications BB, to the
+// account ID in the contact address or to the subscribers of the topic param
+type pushAlertChannel struct {
+	app *Application
+}
+
+func (c pushAlertChannel) validateContact(contact model.AlertContact) error {
+	topic, err := alertContactParam(contact, "topic")
+	if err != nil {
+		return err
+	}
+	if contact.Address == "" && topic == "" {
+		return errors.ErrorData(logutils.StatusMissing, "push recipient", &logutils.FieldArgs{"address": "", "topic": ""})
+	}
+	return nil
+}
+
+func (c pushAlertChannel) send(contact model.AlertContact, alert model.SurveyAlert) (string, error) {
+	subject, err := alertContentString(alert, "subject")
+	if err != nil {
+		return model.AlertDeliveryInvalid, err
+	}
+	body, err := alertContentString(alert, "body")
+	if err != nil {
+		return model.AlertDeliveryInvalid, err
+	}
+
+	message := model.NotificationMessage{OrgID: alert.OrgID, AppID: alert.AppID, Subject: subject, Body: body,
+		Data: map[string]string{"type": "survey_alert", "contact_key": alert.ContactKey}}
+	if topic, _ := alertContactParam(contact, "topic"); topic != "" {
+		message.Topic = &topic
+	} else {
+		message.Recipients = []model.NotificationMessageRecipient{{UserID: contact.Address}}
+	}
+	c.app.notifications.SendNotification(message)
+	return model.AlertDeliveryQueued, nil
+}
+
+// webhookAlertChannel posts alerts as JSON to the URL in the contact address. Requests are signed with the secret param
+// so the endpoint can check that they come from this service.
+type webhookAlertChannel struct {
+	app *Application
+}
+
+func (c webhookAlertChannel) validateContact(contact model.AlertContact) error {
+	endpoint, err := url.Parse(contact.Address)
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		return errors.ErrorData(logutils.StatusInvalid, "webhook url", logutils.StringArgs(contact.Address))
+	}
+	secret, err := alertContactParam(contact, "secret")
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return errors.ErrorData(logutils.StatusMissing, "alert contact param", &logutils.FieldArgs{"name": "secret"})
+	}
+	return nil
+}
+
+func (c webhookAlertChannel) send(contact model.AlertContact, alert model.SurveyAlert) (string, error) {
+	now := time.Now().UTC()
+	payload := map[string]interface{}{
+		"org_id":      alert.OrgID,
+		"app_id":      alert.AppID,
+		"contact_key": alert.ContactKey,
+		"content":     alert.Content,
+		"date_sent":   now,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return model.AlertDeliveryInvalid, errors.WrapErrorAction(logutils.ActionMarshal, model.TypeSurveyAlert, nil, err)
+	}
+
+	secret, _ := alertContactParam(contact, "secret")
+	timestamp := fmt.Sprintf("%d", now.Unix())
+	headers := map[string]string{
+		webhookTimestampHeader: timestamp,
+		webhookSignatureHeader: "sha256=" + signWebhook(secret, timestamp, body),
+	}
+	err = c.app.webhooks.Post(contact.Address, body, headers)
+	if err != nil {
+		return model.AlertDeliveryFailed, err
+	}
+	return model.AlertDeliverySent, nil
+}
+
+// signWebhook returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// smsAlertChannel sends the body of alerts as a text message through the SMS provider to the phone number in the
+// contact address
+type smsAlertChannel struct {
+	app *Application
+}
+
+func (c smsAlertChannel) validateContact(contact model.AlertContact) error {
+	if !phoneNumberPattern.MatchString(contact.Address) {
+		return errors.ErrorData(logutils.StatusInvalid, "phone number", logutils.StringArgs(contact.Address))
+	}
+	return nil
+}
+
+func (c smsAlertChannel) send(contact model.AlertContact, alert model.SurveyAlert) (string, error) {
+	body, err := alertContentString(alert, "body")
+	if err != nil {
+		return model.AlertDeliveryInvalid, err
+	}
+	err = c.app.sms.SendSMS(contact.Address, body)
+	if err != nil {
+		return model.AlertDeliveryFailed, err
+	}
+	return model.AlertDeliverySent, nil
+}
@@ -36,16 +36,72 @@ func (a appAnalytics) GetAnonymousSurveyResponses(surveyTypes []string, startDat
 		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurveyResponse, nil, err)
 	}
 
-	anonResData := make([]model.SurveyResponseAnonymous, len(responses))
-	for i, surveyRes := range responses {
-		anonResData[i] = model.SurveyResponseAnonymous{ID: surveyRes.Survey.ID, CreatorID: surveyRes.Survey.CreatorID, AppID: surveyRes.Survey.AppID,
-			OrgID: surveyRes.Survey.OrgID, Title: surveyRes.Survey.Title, Type: surveyRes.Survey.Type, SurveyStats: surveyRes.Survey.SurveyStats,
-			DateCreated: surveyRes.Survey.DateCreated, DateUpdated: surveyRes.Survey.DateUpdated}
+	anonymizer, err := a.surveyResponseAnonymizer()
+	if err != nil {
+		return nil, err
+	}
+
+	anonResData := make([]model.SurveyResponseAnonymous, 0, len(responses))
+	for _, surveyRes := range responses {
+		include, err := anonymizer.include(surveyRes)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+		anonymous, err := anonymizer.anonymize(surveyRes)
+		if err != nil {
+			return nil, err
+		}
+		anonResData = append(anonResData, anonymous)
 	}
 
 	return anonResData, nil
 }
 
+// StreamAnonymousSurveyResponses calls handle for each anonymized survey response matching the provided filters, sorted by
+// creation date and ID. The opaque cursor passed with each response can be used to resume the stream after it.
+func (a appAnalytics) StreamAnonymousSurveyResponses(surveyTypes []string, startDate *time.Time, endDate *time.Time, after string,
+	handle func(surveyResponse model.SurveyResponseAnonymous, cursor string) error) error {
+	anonymizer, err := a.surveyResponseAnonymizer()
+	if err != nil {
+		return err
+	}
+
+	var afterCursor *model.SurveyResponseCursor
+	if after != "" {
+		afterCursor, err = anonymizer.decodeCursor(after)
+		if err != nil {
+			return err
+		}
+	}
+
+	return a.app.storage.StreamSurveyResponsesByType(surveyTypes, startDate, endDate, afterCursor, func(surveyRes model.SurveyResponse) error {
+		include, err := anonymizer.include(surveyRes)
+		if err != nil || !include {
+			return err
+		}
+		anonymous, err := anonymizer.anonymize(surveyRes)
+		if err != nil {
+			return err
+		}
+		cursor, err := anonymizer.encodeCursor(model.SurveyResponseCursor{DateCreated: surveyRes.DateCreated, ID: surveyRes.ID})
+		if err != nil {
+			return err
+		}
+		return handle(anonymous, cursor)
+	})
+}
+
+func (a appAnalytics) surveyResponseAnonymizer() (*surveyResponseAnonymizer, error) {
+	envConfig, err := a.app.GetEnvConfigs()
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeConfig, logutils.StringArgs(model.ConfigTypeEnv), err)
+	}
+	return newSurveyResponseAnonymizer(*envConfig, a.app.storage), nil
+}
+
 // newAppAnalytics creates new appAnalytics
 func newAppAnalytics(app *Application) appAnalytics {
 	return appAnalytics{app: app}
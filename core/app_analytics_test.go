@@ -0,0 +1,149 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core_test
+
+import (
+	"application/core/interfaces/mocks"
+	"application/core/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rokwire/core-auth-library-go/v3/authutils"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAppAnalytics_StreamAnonymousSurveyResponses(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	response := model.SurveyResponse{ID: "second", UserID: "user", SurveyRevision: 2, DateCreated: created,
+		Survey: model.Survey{ID: "survey", CreatorID: "creator", OrgID: "org", AppID: "app", Type: "user", Data: map[string]model.SurveyData{
+			"mood":    {Type: model.SurveyDataTypeMultipleChoice, Response: "good"},
+			"comment": {Type: model.SurveyDataTypeText, Response: "Call me at 555-0100"},
+			"age":     {Type: model.SurveyDataTypeNumeric, Response: int32(42)},
+			"zip":     {Type: model.SurveyDataTypeNumeric, Response: int32(61801), ExcludeFromAnalytics: true},
+		}}}
+	// age was excluded after the response was submitted
+	survey := model.Survey{ID: "survey", Data: map[string]model.SurveyData{"age": {Type: model.SurveyDataTypeNumeric, ExcludeFromAnalytics: true}}}
+	envConfig := model.EnvConfigData{AnalyticsSecret: "secret", AnalyticsProfile: &model.AnalyticsProfile{FreeText: model.AnalyticsFreeTextRemove,
+		TimeGranularity: model.ResponsePeriodWeek}}
+
+	storage := mocks.NewStorage(t)
+	storage.On("FindConfig", model.ConfigTypeEnv, authutils.AllApps, authutils.AllOrgs).Return(&model.Config{Type: model.ConfigTypeEnv, Data: envConfig}, nil)
+	storage.On("GetSurveys", "org", "app", (*string)(nil), []string{"survey"}, []string(nil), "", (*int)(nil), (*int)(nil), mock.Anything, (*bool)(nil), (*bool)(nil), (*bool)(nil)).Return([]model.Survey{survey}, nil)
+	storage.On("FindConfig", model.ConfigTypePrivacy, mock.Anything, mock.Anything).Return(nil, nil)
+	storage.On("CountSurveyResponsesBySurvey", "org", "app", "survey").Return(int64(5), nil)
+	var resumedAfter *model.SurveyResponseCursor
+	storage.On("StreamSurveyResponsesByType", []string{"user"}, (*time.Time)(nil), (*time.Time)(nil), mock.Anything, mock.Anything).Return(
+		func(surveyTypes []string, startDate *time.Time, endDate *time.Time, after *model.SurveyResponseCursor, handle func(model.SurveyResponse) error) error {
+			resumedAfter = after
+			for i := 0; i < 2; i++ {
+				if err := handle(response); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	app := buildTestApplication(storage)
+
+	var responses []model.SurveyResponseAnonymous
+	var cursors []string
+	err := app.Analytics.StreamAnonymousSurveyResponses([]string{"user"}, nil, nil, "",
+		func(surveyResponse model.SurveyResponseAnonymous, cursor string) error {
+			responses = append(responses, surveyResponse)
+			cursors = append(cursors, cursor)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("Analytics.StreamAnonymousSurveyResponses() error = %v", err)
+	}
+	if len(responses) != 2 || responses[0].ID != "survey" || responses[0].SurveyRevision != 2 {
+		t.Fatalf("Analytics.StreamAnonymousSurveyResponses() responses = %v", responses)
+	}
+	if cursors[0] == "" || strings.Contains(cursors[0], "second") || cursors[0] == cursors[1] {
+		t.Errorf("Analytics.StreamAnonymousSurveyResponses() cursors = %v, want opaque tokens", cursors)
+	}
+
+	// the stream resumes after the position encrypted in the cursor
+	err = app.Analytics.StreamAnonymousSurveyResponses([]string{"user"}, nil, nil, cursors[0],
+		func(surveyResponse model.SurveyResponseAnonymous, cursor string) error { return nil })
+	if err != nil {
+		t.Fatalf("Analytics.StreamAnonymousSurveyResponses() resume error = %v", err)
+	}
+	if resumedAfter == nil || resumedAfter.ID != "second" || !resumedAfter.DateCreated.Equal(created) {
+		t.Errorf("Analytics.StreamAnonymousSurveyResponses() resumed after %v, want the position of response second", resumedAfter)
+	}
+
+	tampered := []byte(cursors[0])
+	tampered[len(tampered)/2] ^= 1
+	err = app.Analytics.StreamAnonymousSurveyResponses([]string{"user"}, nil, nil, string(tampered),
+		func(surveyResponse model.SurveyResponseAnonymous, cursor string) error { return nil })
+	if _, ok := err.(*model.InvalidCursorError); !ok {
+		t.Errorf("Analytics.StreamAnonymousSurveyResponses() tampered cursor error = %v, want invalid cursor error", err)
+	}
+
+	anonymous := responses[0]
+	if anonymous.RespondentID == "" || anonymous.RespondentID == "user" || anonymous.RespondentID != responses[1].RespondentID {
+		t.Errorf("Analytics.StreamAnonymousSurveyResponses() respondent IDs = %s, %s, want a stable pseudonym", anonymous.RespondentID, responses[1].RespondentID)
+	}
+	week := time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)
+	if anonymous.DateResponded == nil || !anonymous.DateResponded.Equal(week) {
+		t.Errorf("Analytics.StreamAnonymousSurveyResponses() date responded = %v, want %v", anonymous.DateResponded, week)
+	}
+	want := map[string]interface{}{"mood": "good"}
+	if !reflect.DeepEqual(anonymous.Answers, want) {
+		t.Errorf("Analytics.StreamAnonymousSurveyResponses() answers = %v, want %v", anonymous.Answers, want)
+	}
+}
+
+func TestAppAnalytics_StreamAnonymousSurveyResponses_DeletedSurvey(t *testing.T) {
+	response := model.SurveyResponse{ID: "response", UserID: "user", DateCreated: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Survey: model.Survey{ID: "deleted", OrgID: "org", AppID: "app", Type: "user", Data: map[string]model.SurveyData{
+			"mood": {Type: model.SurveyDataTypeMultipleChoice, Response: "good"},
+			"zip":  {Type: model.SurveyDataTypeNumeric, Response: int32(61801), ExcludeFromAnalytics: true},
+		}}}
+	envConfig := model.EnvConfigData{AnalyticsSecret: "secret", AnalyticsProfile: &model.AnalyticsProfile{FreeText: model.AnalyticsFreeTextRemove}}
+
+	storage := mocks.NewStorage(t)
+	storage.On("FindConfig", model.ConfigTypeEnv, authutils.AllApps, authutils.AllOrgs).Return(&model.Config{Type: model.ConfigTypeEnv, Data: envConfig}, nil)
+	// the survey was deleted, so it has no current definition
+	storage.On("GetSurveys", "org", "app", (*string)(nil), []string{"deleted"}, []string(nil), "", (*int)(nil), (*int)(nil), mock.Anything, (*bool)(nil), (*bool)(nil), (*bool)(nil)).Return(nil, nil).Once()
+	storage.On("FindConfig", model.ConfigTypePrivacy, mock.Anything, mock.Anything).Return(nil, nil)
+	storage.On("CountSurveyResponsesBySurvey", "org", "app", "deleted").Return(int64(5), nil)
+	storage.On("StreamSurveyResponsesByType", []string{"user"}, (*time.Time)(nil), (*time.Time)(nil), mock.Anything, mock.Anything).Return(
+		func(surveyTypes []string, startDate *time.Time, endDate *time.Time, after *model.SurveyResponseCursor, handle func(model.SurveyResponse) error) error {
+			for i := 0; i < 2; i++ {
+				if err := handle(response); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	app := buildTestApplication(storage)
+
+	var responses []model.SurveyResponseAnonymous
+	err := app.Analytics.StreamAnonymousSurveyResponses([]string{"user"}, nil, nil, "",
+		func(surveyResponse model.SurveyResponseAnonymous, cursor string) error {
+			responses = append(responses, surveyResponse)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("Analytics.StreamAnonymousSurveyResponses() error = %v", err)
+	}
+	want := map[string]interface{}{"mood": "good"}
+	if len(responses) != 2 || !reflect.DeepEqual(responses[0].Answers, want) || !reflect.DeepEqual(responses[1].Answers, want) {
+		t.Errorf("Analytics.StreamAnonymousSurveyResponses() responses = %v, want answers %v", responses, want)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/interfaces"
+	"application/core/model"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// surveyResponseAnonymizer anonymizes survey responses for analytics following the configured analytics profile
+type surveyResponseAnonymizer struct {
+	profile *model.AnalyticsProfile
+	secret  string
+
+	storage interfaces.Storage
+	// excluded caches the keys of the questions excluded from analytics by survey ID
+	excluded map[string]map[string]bool
+	// withheld caches whether the responses to a survey are withheld by survey ID
+	withheld map[string]bool
+}
+
+func newSurveyResponseAnonymizer(envConfig model.EnvConfigData, storage interfaces.Storage) *surveyResponseAnonymizer {
+	return &surveyResponseAnonymizer{profile: envConfig.AnalyticsProfile, secret: envConfig.AnalyticsSecret, storage: storage,
+		excluded: map[string]map[string]bool{}, withheld: map[string]bool{}}
+}
+
+// anonymize returns the anonymized survey response. Answers to questions excluded from analytics are left out, free
+// text answers are removed or hashed and the respondent is identified by a keyed hash of the user ID.
+func (a *surveyResponseAnonymizer) anonymize(surveyRes model.SurveyResponse) (model.SurveyResponseAnonymous, error) {
+	anonymous := model.SurveyResponseAnonymous{ID: surveyRes.Survey.ID, CreatorID: surveyRes.Survey.CreatorID, AppID: surveyRes.Survey.AppID,
+		OrgID: surveyRes.Survey.OrgID, Title: surveyRes.Survey.Title, Type: surveyRes.Survey.Type, SurveyStats: surveyRes.Survey.SurveyStats,
+		DateCreated: surveyRes.Survey.DateCreated, DateUpdated: surveyRes.Survey.DateUpdated, SurveyRevision: surveyRes.SurveyRevision}
+	if a.profile == nil {
+		return anonymous, nil
+	}
+
+	// responses to anonymous surveys cannot be linked to each other
+	if a.secret != "" && surveyRes.UserID != "" && !surveyRes.Survey.Anonymous {
+		anonymous.RespondentID = a.hash("user", surveyRes.UserID)
+	}
+	dateResponded := coarsenTime(surveyRes.DateCreated, a.profile.TimeGranularity)
+	anonymous.DateResponded = &dateResponded
+
+	// the answers to sensitive surveys are never sent to analytics
+	if surveyRes.Survey.Sensitive {
+		return anonymous, nil
+	}
+	excluded, err := a.excludedQuestions(surveyRes.Survey)
+	if err != nil {
+		return anonymous, err
+	}
+	answers := map[string]interface{}{}
+	for key, data := range surveyRes.Survey.Data {
+		if data.Response == nil || data.ExcludeFromAnalytics || excluded[key] {
+			continue
+		}
+		if isFreeTextQuestion(data) {
+			if a.profile.FreeText != model.AnalyticsFreeTextHash || a.secret == "" {
+				continue
+			}
+			answers[key] = a.hash("text", strings.ToLower(strings.TrimSpace(fmt.Sprint(data.Response))))
+			continue
+		}
+		answers[key] = data.Response
+	}
+	if len(answers) > 0 {
+		anonymous.Answers = answers
+	}
+	return anonymous, nil
+}
+
+// include returns true if the survey response may be sent to analytics. Responses to surveys with fewer responses
+// than the minimum group size of their app/org are withheld.
+func (a *surveyResponseAnonymizer) include(surveyRes model.SurveyResponse) (bool, error) {
+	withheld, ok := a.withheld[surveyRes.Survey.ID]
+	if !ok {
+		var err error
+		withheld, _, err = surveyBelowMinGroupSize(a.storage, surveyRes.Survey)
+		if err != nil {
+			return false, err
+		}
+		a.withheld[surveyRes.Survey.ID] = withheld
+	}
+	return !withheld, nil
+}
+
+// excludedQuestions returns the keys of the questions the current survey definition excludes from analytics, so
+// questions excluded after responses were submitted are left out as well. Responses to deleted surveys have no current
+// definition, only the questions excluded in the definition embedded in the response are left out.
+func (a *surveyResponseAnonymizer) excludedQuestions(survey model.Survey) (map[string]bool, error) {
+	if excluded, ok := a.excluded[survey.ID]; ok {
+		return excluded, nil
+	}
+
+	current, err := a.storage.GetSurveys(survey.OrgID, survey.AppID, nil, []string{survey.ID}, nil, "", nil, nil, &model.SurveyTimeFilter{}, nil, nil, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, &logutils.FieldArgs{"id": survey.ID}, err)
+	}
+	excluded := map[string]bool{}
+	for _, definition := range current {
+		for key, data := range definition.Data {
+			if data.ExcludeFromAnalytics {
+				excluded[key] = true
+			}
+		}
+	}
+	a.excluded[survey.ID] = excluded
+	return excluded, nil
+}
+
+// hash returns the keyed hash of a value. The kind keeps hashes of different kinds of values from matching.
+func (a *surveyResponseAnonymizer) hash(kind string, value string) string {
+	mac := hmac.New(sha256.New, []byte(a.secret))
+	mac.Write([]byte(kind + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// encodeCursor encrypts the position of a survey response into an opaque cursor token. No cursor is issued if the
+// analytics secret is not configured.
+func (a *surveyResponseAnonymizer) encodeCursor(cursor model.SurveyResponseCursor) (string, error) {
+	if a.secret == "" {
+		return "", nil
+	}
+	plaintext, err := json.Marshal(cursor)
+	if err != nil {
+		return "", errors.WrapErrorAction(logutils.ActionMarshal, model.TypeSurveyResponseCursor, nil, err)
+	}
+	gcm, err := a.cursorCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", errors.WrapErrorAction(logutils.ActionCreate, model.TypeSurveyResponseCursor, nil, err)
+	}
+	return base64.RawURLEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// decodeCursor returns the position of the survey response encrypted in the cursor token
+func (a *surveyResponseAnonymizer) decodeCursor(token string) (*model.SurveyResponseCursor, error) {
+	if a.secret == "" {
+		return nil, &model.InvalidCursorError{Reason: "cursors are not enabled"}
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, &model.InvalidCursorError{Reason: "malformed cursor"}
+	}
+	gcm, err := a.cursorCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, &model.InvalidCursorError{Reason: "malformed cursor"}
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, &model.InvalidCursorError{Reason: "unknown cursor"}
+	}
+	var cursor model.SurveyResponseCursor
+	err = json.Unmarshal(plaintext, &cursor)
+	if err != nil || cursor.ID == "" || cursor.DateCreated.IsZero() {
+		return nil, &model.InvalidCursorError{Reason: "incomplete cursor"}
+	}
+	return &cursor, nil
+}
+
+// cursorCipher returns the cipher of the cursor tokens, keyed by a key derived from the analytics secret
+func (a *surveyResponseAnonymizer) cursorCipher() (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, []byte(a.secret))
+	mac.Write([]byte("cursor"))
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionCreate, "cursor cipher", nil, err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionCreate, "cursor cipher", nil, err)
+	}
+	return gcm, nil
+}
+
+func isFreeTextQuestion(data model.SurveyData) bool {
+	return data.Type == model.SurveyDataTypeText || data.Type == model.SurveyDataTypeEntry
+}
+
+// coarsenTime returns the start of the day, week or month containing t
+func coarsenTime(t time.Time, granularity string) time.Time {
+	if granularity == "" {
+		granularity = model.ResponsePeriodDay
+	}
+	start, err := responsePeriodStart(granularity, t)
+	if err != nil {
+		start, _ = responsePeriodStart(model.ResponsePeriodDay, t)
+	}
+	return start
+}
@@ -0,0 +1,236 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"application/driven/calendar"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	// eventRegistrationCacheTTL is how long the event registrations checked when listing surveys are cached
+	eventRegistrationCacheTTL = 5 * time.Minute
+	// eventRegistrationChecks is the number of event registrations checked at once when listing surveys
+	eventRegistrationChecks = 5
+)
+
+// cachedEventRegistration is the registration of a user to a calendar event cached until it expires
+type cachedEventRegistration struct {
+	registered bool
+	expires    time.Time
+}
+
+// validateSurveyAudience checks that the audience of a survey definition has criteria that can be evaluated
+func validateSurveyAudience(survey model.Survey) error {
+	audience := survey.Audience
+	if audience == nil {
+		return nil
+	}
+	if audience.Empty() {
+		return errors.ErrorData(logutils.StatusInvalid, model.TypeSurveyAudience, &logutils.FieldArgs{"empty": true})
+	}
+	for idType, pattern := range audience.ExternalIDPatterns {
+		// external ID types are stored as field names
+		if idType == "" || strings.ContainsAny(idType, ".$") {
+			return errors.ErrorData(logutils.StatusInvalid, "external id type", logutils.StringArgs(idType))
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errors.WrapErrorData(logutils.StatusInvalid, "external id pattern", &logutils.FieldArgs{"type": idType, "pattern": pattern}, err)
+		}
+	}
+	if audience.EventRegistration && survey.CalendarEventID == "" {
+		return errors.ErrorData(logutils.StatusInvalid, model.TypeSurveyAudience, &logutils.FieldArgs{"event_registration": true, "calendar_event_id": ""})
+	}
+	return nil
+}
+
+// inSurveyAudience returns true if the survey is for the user. The creator is always part of the audience.
+func (a appShared) inSurveyAudience(survey model.Survey, user model.SurveyAudienceUser) (bool, error) {
+	if survey.Audience == nil || survey.CreatorID == user.AccountID || survey.Audience.MatchesClaims(user) {
+		return true, nil
+	}
+	if survey.Audience.EventRegistration && survey.CalendarEventID != "" {
+		return a.isRegisteredForEvent(survey.OrgID, survey.AppID, survey.CalendarEventID, user.AccountID, user.ExternalIDs)
+	}
+	return false, nil
+}
+
+// checkSurveyAudience returns an error if the survey is not for the user
+func (a appShared) checkSurveyAudience(survey model.Survey, user model.SurveyAudienceUser) error {
+	inAudience, err := a.inSurveyAudience(survey, user)
+	if err != nil {
+		return errors.WrapErrorAction("checking", model.TypeSurveyAudience, &logutils.FieldArgs{"survey_id": survey.ID}, err)
+	}
+	if !inAudience {
+		return &model.SurveyAudienceError{SurveyID: survey.ID}
+	}
+	return nil
+}
+
+// resolveSurveyAudience sets the surveys whose external ID patterns or event registration match the user before the
+// surveys are listed, so that listing can be paginated in storage. Registration is checked once per calendar event.
+func (a appShared) resolveSurveyAudience(orgID string, appID string, surveyIDs []string, calendarEventID string, user *model.SurveyAudienceUser) error {
+	surveys, err := a.app.storage.GetSurveyAudiences(orgID, appID, surveyIDs, calendarEventID)
+	if err != nil {
+		return err
+	}
+
+	user.SurveyIDs = make([]string, 0)
+	eventSurveyIDs := map[string][]string{}
+	for _, survey := range surveys {
+		if survey.Audience == nil {
+			continue
+		}
+		if survey.Audience.MatchesClaims(*user) {
+			user.SurveyIDs = append(user.SurveyIDs, survey.ID)
+		} else if survey.Audience.EventRegistration && survey.CalendarEventID != "" {
+			eventSurveyIDs[survey.CalendarEventID] = append(eventSurveyIDs[survey.CalendarEventID], survey.ID)
+		}
+	}
+
+	eventIDs := make([]string, 0, len(eventSurveyIDs))
+	for eventID := range eventSurveyIDs {
+		eventIDs = append(eventIDs, eventID)
+	}
+	registrations, err := a.eventRegistrations(orgID, appID, eventIDs, *user)
+	if err != nil {
+		return err
+	}
+	for eventID, ids := range eventSurveyIDs {
+		if registrations[eventID] {
+			user.SurveyIDs = append(user.SurveyIDs, ids...)
+		}
+	}
+	return nil
+}
+
+// eventRegistrations returns whether the user is registered to each of the calendar events. Registrations are cached
+// for a short time and the others are checked a few at a time, so listing surveys does not wait on one Calendar
+// request per event.
+func (a appShared) eventRegistrations(orgID string, appID string, eventIDs []string, user model.SurveyAudienceUser) (map[string]bool, error) {
+	now := time.Now().UTC()
+	registrations := make(map[string]bool, len(eventIDs))
+	unknown := make([]string, 0)
+	for _, eventID := range eventIDs {
+		cached, ok := a.app.eventRegistrations.Load(eventRegistrationKey(orgID, appID, eventID, user.AccountID))
+		if ok && cached.(cachedEventRegistration).expires.After(now) {
+			registrations[eventID] = cached.(cachedEventRegistration).registered
+		} else {
+			unknown = append(unknown, eventID)
+		}
+	}
+
+	var (
+		lock     sync.Mutex
+		wg       sync.WaitGroup
+		checkErr error
+	)
+	checks := make(chan struct{}, eventRegistrationChecks)
+	for _, eventID := range unknown {
+		wg.Add(1)
+		checks <- struct{}{}
+		go func(eventID string) {
+			defer func() {
+				<-checks
+				wg.Done()
+			}()
+			registered, err := a.isRegisteredForEvent(orgID, appID, eventID, user.AccountID, user.ExternalIDs)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				checkErr = errors.WrapErrorAction("checking", model.TypeSurveyAudience, &logutils.FieldArgs{"calendar_event_id": eventID}, err)
+				return
+			}
+			registrations[eventID] = registered
+			a.app.eventRegistrations.Store(eventRegistrationKey(orgID, appID, eventID, user.AccountID),
+				cachedEventRegistration{registered: registered, expires: now.Add(eventRegistrationCacheTTL)})
+		}(eventID)
+	}
+	wg.Wait()
+	if checkErr != nil {
+		return nil, checkErr
+	}
+	return registrations, nil
+}
+
+// pruneEventRegistrations removes the expired event registrations from the cache
+func (a *Application) pruneEventRegistrations() {
+	now := time.Now().UTC()
+	a.eventRegistrations.Range(func(key interface{}, value interface{}) bool {
+		if !value.(cachedEventRegistration).expires.After(now) {
+			a.eventRegistrations.Delete(key)
+		}
+		return true
+	})
+}
+
+// eventRegistrationKey returns the key of a cached event registration
+func eventRegistrationKey(orgID string, appID string, eventID string, userID string) string {
+	return orgID + "_" + appID + "_" + eventID + "_" + userID
+}
+
+// surveyAudience returns the account IDs of the known users a survey is for: the listed accounts and the users
+// registered to the calendar event if the audience includes them. Surveys of calendar events without an audience are
+// for the users registered to the event, other surveys have no known audience.
+func (a *Application) surveyAudience(survey model.Survey) ([]string, error) {
+	accountIDs := make([]string, 0)
+	eventRegistration := survey.CalendarEventID != ""
+	if survey.Audience != nil {
+		accountIDs = append(accountIDs, survey.Audience.AccountIDs...)
+		eventRegistration = eventRegistration && survey.Audience.EventRegistration
+	}
+
+	if eventRegistration {
+		registered := true
+		eventUsers, err := a.calendar.GetEventUsers(survey.OrgID, survey.AppID, survey.CalendarEventID, nil, &registered, "", nil)
+		if err != nil {
+			return nil, errors.WrapErrorAction(logutils.ActionGet, calendar.TypeCalendarUser, &logutils.FieldArgs{"calendar_event_id": survey.CalendarEventID}, err)
+		}
+		for _, eventUser := range eventUsers {
+			if eventUser.Registered && eventUser.User.AccountID != "" {
+				accountIDs = append(accountIDs, eventUser.User.AccountID)
+			}
+		}
+	}
+
+	audience := make([]string, 0, len(accountIDs))
+	added := map[string]bool{}
+	for _, accountID := range accountIDs {
+		if added[accountID] {
+			continue
+		}
+		added[accountID] = true
+		audience = append(audience, accountID)
+	}
+	return audience, nil
+}
+
+// surveyAudiencePreview lists the known users a survey is for
+func (a *Application) surveyAudiencePreview(survey model.Survey) (*model.SurveyAudiencePreview, error) {
+	accountIDs, err := a.surveyAudience(survey)
+	if err != nil {
+		return nil, err
+	}
+	partial := survey.Audience == nil || !survey.Audience.Listable()
+	return &model.SurveyAudiencePreview{SurveyID: survey.ID, Audience: survey.Audience, AccountIDs: accountIDs, Partial: partial}, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/interfaces"
+	"application/core/model"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// auditIgnoredFields are the entity fields that change with every update and are not recorded in the audit log
+var auditIgnoredFields = map[string]bool{"date_updated": true}
+
+// auditSecretFields are the parts of config and alert contact param names whose values are replaced by a digest in the audit log
+var auditSecretFields = []string{"secret", "token", "password", "key"}
+
+// auditedSurveyResponse is the part of a survey response recorded in the audit log. The answers themselves are never
+// copied to the audit log, only the keys of the answered questions.
+type auditedSurveyResponse struct {
+	ID             string   `json:"id"`
+	UserID         string   `json:"user_id"`
+	SurveyID       string   `json:"survey_id"`
+	SurveyRevision int      `json:"survey_revision"`
+	Status         string   `json:"status,omitempty"`
+	Answers        []string `json:"answers"`
+}
+
+// newAuditEvent returns the audit event recording the change of an entity from before to after. before is nil for
+// creations and after is nil for deletions.
+func newAuditEvent(audit model.AuditContext, entity string, entityID string, orgID string, appID string, before interface{}, after interface{}) (*model.AuditEvent, error) {
+	action := model.AuditActionUpdate
+	if before == nil {
+		action = model.AuditActionCreate
+	} else if after == nil {
+		action = model.AuditActionDelete
+	}
+
+	from, err := auditedValue(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := auditedValue(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]model.SurveyChange, 0)
+	diffValues("", from, to, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return &model.AuditEvent{ID: uuid.NewString(), OrgID: orgID, AppID: appID, Entity: entity, EntityID: entityID, Action: action,
+		Actor: audit.Actor, RequestID: audit.RequestID, Changes: changes, DateCreated: time.Now().UTC()}, nil
+}
+
+// auditedValue returns the decoded JSON of the part of an entity recorded in the audit log
+func auditedValue(value interface{}) (map[string]interface{}, error) {
+	secrets := false
+	paramSecrets := false
+	switch entity := value.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case model.SurveyResponse:
+		answers := make([]string, 0, len(entity.Survey.Data))
+		for key := range entity.Survey.Data {
+			answers = append(answers, key)
+		}
+		sort.Strings(answers)
+		value = auditedSurveyResponse{ID: entity.ID, UserID: entity.UserID, SurveyID: entity.Survey.ID, SurveyRevision: entity.SurveyRevision,
+			Status: entity.Status, Answers: answers}
+	case model.Config:
+		secrets = true
+	case model.AlertContact:
+		paramSecrets = true
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionMarshal, model.TypeAuditEvent, nil, err)
+	}
+	var audited map[string]interface{}
+	err = json.Unmarshal(data, &audited)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionUnmarshal, model.TypeAuditEvent, nil, err)
+	}
+	for field := range auditIgnoredFields {
+		delete(audited, field)
+	}
+	if secrets {
+		redactAuditSecrets(audited)
+	}
+	// only the params of alert contacts hold secrets, such as the webhook signing secret
+	if params, ok := audited["params"].(map[string]interface{}); ok && paramSecrets {
+		redactAuditSecrets(params)
+	}
+	return audited, nil
+}
+
+// redactAuditSecrets replaces the values of secret fields by a digest, so changes to secrets are recorded without the secrets
+func redactAuditSecrets(value map[string]interface{}) {
+	for key, fieldValue := range value {
+		if nested, ok := fieldValue.(map[string]interface{}); ok {
+			redactAuditSecrets(nested)
+			continue
+		}
+		secretValue, ok := fieldValue.(string)
+		if !ok || secretValue == "" {
+			continue
+		}
+		for _, secretField := range auditSecretFields {
+			if strings.Contains(strings.ToLower(key), secretField) {
+				digest := sha256.Sum256([]byte(secretValue))
+				value[key] = "sha256:" + hex.EncodeToString(digest[:4])
+				break
+			}
+		}
+	}
+}
+
+// insertAuditEvent records the change of an entity in the audit log. It may be called within a transaction.
+func insertAuditEvent(storage interfaces.Storage, audit model.AuditContext, entity string, entityID string, orgID string, appID string,
+	before interface{}, after interface{}) error {
+	auditEvent, err := newAuditEvent(audit, entity, entityID, orgID, appID, before, after)
+	if err != nil {
+		return err
+	}
+	err = storage.InsertAuditEvent(*auditEvent)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionInsert, model.TypeAuditEvent, &logutils.FieldArgs{"entity": entity, "entity_id": entityID}, err)
+	}
+	return nil
+}
+
+// recordAuditEvent records the change of an entity which has already been stored. Failures are logged instead of
+// returned, as the change cannot be undone.
+func (a appShared) recordAuditEvent(audit model.AuditContext, entity string, entityID string, orgID string, appID string, before interface{}, after interface{}) {
+	err := insertAuditEvent(a.app.storage, audit, entity, entityID, orgID, appID, before, after)
+	if err != nil {
+		a.app.logger.Errorf("error recording audit event for %s %s - %s", entity, entityID, err)
+	}
+}
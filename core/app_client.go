@@ -15,7 +15,9 @@
 package core
 
 import (
+	"application/core/interfaces"
 	"application/core/model"
+	"application/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,30 +31,164 @@ type appClient struct {
 }
 
 // Surveys
-// GetSurvey returns the survey with the provided ID
-func (a appClient) GetSurvey(id string, orgID string, appID string) (*model.Survey, error) {
-	return a.app.shared.getSurvey(id, orgID, appID)
+// GetSurvey returns the survey with the provided ID if its audience includes the user
+func (a appClient) GetSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, permissions []string) (*model.Survey, error) {
+	survey, err := a.app.shared.getSurvey(id, orgID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	// drafts are only visible to their creator
+	if survey.CurrentStatus() == model.SurveyStatusDraft && survey.CreatorID != userID {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": id, "app_id": appID, "org_id": orgID})
+	}
+	err = a.app.shared.checkSurveyAudience(*survey, model.SurveyAudienceUser{AccountID: userID, Permissions: permissions, ExternalIDs: externalIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	// only the creator sees the audience and alert rules
+	if survey.CreatorID != userID {
+		view := survey.RespondentView()
+		return &view, nil
+	}
+	return survey, nil
 }
 
-// GetSurvey returns surveys matching the provided query
-func (a appClient) GetSurveys(orgID string, appID string, userID *string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string,
+// GetSurvey returns surveys matching the provided query. Only the surveys whose audience includes the user are returned.
+func (a appClient) GetSurveys(orgID string, appID string, userID *string, externalIDs map[string]string, permissions []string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string,
 	limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, []model.SurveyResponse, error) {
-	return a.app.shared.getSurveys(orgID, appID, userID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+	var audience *model.SurveyAudienceUser
+	if userID != nil {
+		audience = &model.SurveyAudienceUser{AccountID: *userID, Permissions: permissions, ExternalIDs: externalIDs}
+	}
+	return a.app.shared.getSurveys(orgID, appID, userID, audience, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed, false)
 }
 
 // CreateSurvey creates a new survey
-func (a appClient) CreateSurvey(survey model.Survey, externalIDs map[string]string) (*model.Survey, error) {
-	return a.app.shared.createSurvey(survey, externalIDs)
+func (a appClient) CreateSurvey(survey model.Survey, externalIDs map[string]string, strict bool, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.createSurvey(survey, externalIDs, strict, audit)
 }
 
 // UpdateSurvey updates the provided survey
-func (a appClient) UpdateSurvey(survey model.Survey, userID string, externalIDs map[string]string) error {
-	return a.app.shared.updateSurvey(survey, userID, externalIDs, false)
+func (a appClient) UpdateSurvey(survey model.Survey, userID string, externalIDs map[string]string, strict bool, audit model.AuditContext) error {
+	return a.app.shared.updateSurvey(survey, userID, externalIDs, false, strict, audit)
+}
+
+// ValidateSurvey runs a static analysis of the provided survey definition
+func (a appClient) ValidateSurvey(survey model.Survey) model.SurveyLint {
+	return lintSurvey(survey)
 }
 
 // DeleteSurvey deletes the survey with the specified ID
-func (a appClient) DeleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string) error {
-	return a.app.shared.deleteSurvey(id, orgID, appID, userID, externalIDs, false)
+func (a appClient) DeleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) error {
+	return a.app.shared.deleteSurvey(id, orgID, appID, userID, externalIDs, false, "", audit)
+}
+
+// PublishSurvey publishes the survey with the specified ID
+func (a appClient) PublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.publishSurvey(id, orgID, appID, userID, externalIDs, false, audit)
+}
+
+// UnpublishSurvey moves the survey with the specified ID back to draft
+func (a appClient) UnpublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.unpublishSurvey(id, orgID, appID, userID, externalIDs, false, audit)
+}
+
+// EvaluateSurvey evaluates the rules of the survey with the provided ID against the provided answers without saving a response
+func (a appClient) EvaluateSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, permissions []string, data map[string]model.SurveyData) (*model.SurveyEvaluation, error) {
+	survey, err := a.GetSurvey(id, orgID, appID, userID, externalIDs, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation := evaluateSurveyResponse(*survey, data)
+	return &evaluation, nil
+}
+
+// Survey Templates
+// GetSurveyTemplates returns the templates in the catalog, or the templates created by the user if mine is true
+func (a appClient) GetSurveyTemplates(orgID string, appID string, userID string, mine bool, category *string, limit *int, offset *int) ([]model.Survey, error) {
+	filter := model.SurveyTemplateFilter{Category: category, Limit: limit, Offset: offset}
+	if mine {
+		filter.CreatorID = &userID
+	} else {
+		inCatalog := true
+		filter.InCatalog = &inCatalog
+	}
+	templates, err := a.app.storage.GetSurveyTemplates(orgID, appID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the creator of a template sees its audience and alert rules
+	for i, template := range templates {
+		if template.CreatorID != userID {
+			templates[i] = template.RespondentView()
+		}
+	}
+	return templates, nil
+}
+
+// SetSurveyTemplate marks the survey with the specified ID as a template
+func (a appClient) SetSurveyTemplate(id string, orgID string, appID string, userID string, template model.SurveyTemplate, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.setSurveyTemplate(id, orgID, appID, userID, false, &template, audit)
+}
+
+// RemoveSurveyTemplate removes the template mark from the survey with the specified ID
+func (a appClient) RemoveSurveyTemplate(id string, orgID string, appID string, userID string, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.setSurveyTemplate(id, orgID, appID, userID, false, nil, audit)
+}
+
+// CloneSurvey creates a new survey from the template with the specified ID
+func (a appClient) CloneSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, overrides model.SurveyCloneOverrides, audit model.AuditContext) (*model.Survey, error) {
+	return a.app.shared.cloneSurvey(id, orgID, appID, userID, externalIDs, false, overrides, audit)
+}
+
+// Event Survey Subscriptions
+// SubscribeEventSurvey creates the follow-up survey of a calendar event from a template when the event ends
+func (a appClient) SubscribeEventSurvey(subscription model.EventSurveySubscription, externalIDs map[string]string) (*model.EventSurveySubscription, error) {
+	return a.subscribeEventSurvey(subscription, externalIDs)
+}
+
+// GetEventSurveySubscription returns the subscription of a calendar event
+func (a appClient) GetEventSurveySubscription(calendarEventID string, orgID string, appID string, userID string, externalIDs map[string]string) (*model.EventSurveySubscription, error) {
+	subscription, err := a.app.storage.GetEventSurveySubscription(calendarEventID, orgID, appID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeEventSurveySubscription, nil, err)
+	}
+	if subscription == nil {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeEventSurveySubscription, &logutils.FieldArgs{"calendar_event_id": calendarEventID})
+	}
+	err = a.checkEventSurveySubscriber(*subscription, userID, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// UnsubscribeEventSurvey deletes the subscription of a calendar event. A follow-up survey which has been created is kept.
+func (a appClient) UnsubscribeEventSurvey(calendarEventID string, orgID string, appID string, userID string, externalIDs map[string]string) error {
+	subscription, err := a.GetEventSurveySubscription(calendarEventID, orgID, appID, userID, externalIDs)
+	if err != nil {
+		return err
+	}
+	return a.app.storage.DeleteEventSurveySubscription(subscription.CalendarEventID, orgID, appID)
+}
+
+// GetSurveyReminderMutes returns the survey reminders muted by the user
+func (a appClient) GetSurveyReminderMutes(orgID string, appID string, userID string) ([]model.SurveyReminderMute, error) {
+	return a.app.storage.GetSurveyReminderMutes(orgID, appID, []string{userID}, nil)
+}
+
+// MuteSurveyReminders stops the reminders of a survey, or of all surveys if surveyID is empty, for the user
+func (a appClient) MuteSurveyReminders(orgID string, appID string, userID string, surveyID string) (*model.SurveyReminderMute, error) {
+	return a.muteSurveyReminders(orgID, appID, userID, surveyID)
+}
+
+// UnmuteSurveyReminders resumes the reminders of a survey, or of all surveys if surveyID is empty, for the user
+func (a appClient) UnmuteSurveyReminders(orgID string, appID string, userID string, surveyID string) error {
+	return a.app.storage.DeleteSurveyReminderMute(orgID, appID, userID, surveyID)
 }
 
 // Survey Response
@@ -67,7 +203,7 @@ func (a appClient) GetUserSurveyResponses(orgID string, appID string, userID str
 }
 
 // GetAllSurveyResponses returns the survey responses matching the provided filters
-func (a appClient) GetAllSurveyResponses(orgID string, appID string, userID string, surveyID string, startDate *time.Time, endDate *time.Time, limit *int, offset *int, externalIDs map[string]string) ([]model.SurveyResponse, error) {
+func (a appClient) GetAllSurveyResponses(orgID string, appID string, userID string, surveyID string, startDate *time.Time, endDate *time.Time, limit *int, offset *int, externalIDs map[string]string, validate bool) ([]model.SurveyResponse, error) {
 	var allResponses []model.SurveyResponse
 	var err error
 
@@ -95,6 +231,11 @@ func (a appClient) GetAllSurveyResponses(orgID string, appID string, userID stri
 		return nil, errors.ErrorData(logutils.StatusInvalid, "user", &logutils.FieldArgs{"calendar_event_id": survey.CalendarEventID, "admin": false})
 	}
 
+	err = a.app.shared.checkRawSurveyResponses(*survey)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get responses
 	allResponses, err = a.app.storage.GetSurveyResponses(&orgID, &appID, nil, []string{surveyID}, nil, startDate, endDate, limit, offset)
 	if err != nil {
@@ -108,25 +249,130 @@ func (a appClient) GetAllSurveyResponses(orgID string, appID string, userID stri
 		}
 	}
 
+	if validate {
+		validateSurveyResponses(*survey, allResponses)
+	}
+
 	return allResponses, nil
 }
 
+// ExportSurveyResponses writes the responses to the survey with the provided ID as flattened rows
+func (a appClient) ExportSurveyResponses(orgID string, appID string, userID string, surveyID string, startDate *time.Time, endDate *time.Time, externalIDs map[string]string, writer interfaces.ExportWriter) error {
+	survey, err := a.app.shared.getSurvey(surveyID, orgID, appID)
+	if err != nil {
+		return err
+	}
+
+	// Check if survey is sensitive
+	if survey.Sensitive {
+		return errors.Newf("Survey is sensitive and responses are not available")
+	}
+
+	// If no calendar event is associated then user should not have access to responses
+	if survey.CalendarEventID == "" {
+		return errors.Newf("Survey responses are not available. No calendar event associated")
+	}
+
+	// Check if user is admin of calendar event
+	admin, err := a.app.shared.isEventAdmin(survey.OrgID, survey.AppID, survey.CalendarEventID, userID, externalIDs)
+	if err != nil {
+		return errors.WrapErrorAction("checking", "event admin", nil, err)
+	}
+	if !admin {
+		return errors.ErrorData(logutils.StatusInvalid, "user", &logutils.FieldArgs{"calendar_event_id": survey.CalendarEventID, "admin": false})
+	}
+
+	return a.app.shared.exportSurveyResponses(*survey, startDate, endDate, writer)
+}
+
+// GetSurveyResults returns the aggregated answers to the survey with the provided ID. Results are available to the
+// survey creator and admins of the associated calendar event, unless the survey is sensitive.
+func (a appClient) GetSurveyResults(surveyID string, orgID string, appID string, userID string, externalIDs map[string]string, startDate *time.Time,
+	endDate *time.Time, interval string) (*model.SurveyResults, error) {
+	interval, err := surveyResultsInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	survey, err := a.app.shared.getSurvey(surveyID, orgID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if survey is sensitive
+	if survey.Sensitive {
+		return nil, errors.Newf("Survey is sensitive and results are not available")
+	}
+
+	// Check if user is the creator or admin of the calendar event
+	if survey.CreatorID != userID {
+		if survey.CalendarEventID == "" {
+			return nil, errors.Newf("Survey results are not available. No calendar event associated")
+		}
+		admin, err := a.app.shared.isEventAdmin(survey.OrgID, survey.AppID, survey.CalendarEventID, userID, externalIDs)
+		if err != nil {
+			return nil, errors.WrapErrorAction("checking", "event admin", nil, err)
+		}
+		if !admin {
+			return nil, errors.ErrorData(logutils.StatusInvalid, "user", &logutils.FieldArgs{"calendar_event_id": survey.CalendarEventID, "admin": false})
+		}
+	}
+
+	// The results never contain user IDs or free text answers, so anonymous surveys are aggregated like any other survey
+	aggregates, err := a.app.storage.GetSurveyResponseAggregates(orgID, appID, surveyID, surveyResultValueKeys(*survey), startDate, endDate, interval)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurveyResults, nil, err)
+	}
+
+	minSize, err := minGroupSize(a.app.storage, orgID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := buildSurveyResults(*survey, *aggregates, interval)
+	suppressSmallGroups(&results, minSize)
+	return &results, nil
+}
+
 // CreateSurveyResponse creates a new survey response
-func (a appClient) CreateSurveyResponse(surveyResponse model.SurveyResponse, externalIDs map[string]string) (*model.SurveyResponse, error) {
+func (a appClient) CreateSurveyResponse(surveyResponse model.SurveyResponse, externalIDs map[string]string, permissions []string, audit model.AuditContext) (*model.SurveyResponse, error) {
 	surveyResponse.ID = uuid.NewString()
 	surveyResponse.DateCreated = time.Now().UTC()
 	surveyResponse.DateUpdated = nil
+	surveyResponse.Status = model.SurveyResponseStatusCompleted
+
+	// Return the response created by a previous attempt of the same request
+	if surveyResponse.IdempotencyKey != "" {
+		existing, err := a.app.storage.GetSurveyResponseByIdempotencyKey(surveyResponse.OrgID, surveyResponse.AppID, surveyResponse.UserID, surveyResponse.Survey.ID, surveyResponse.IdempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
 
 	// Get survey from storage
 	survey, err := a.app.storage.GetSurvey(surveyResponse.Survey.ID, surveyResponse.OrgID, surveyResponse.AppID)
 	if err != nil {
 		return nil, err
 	}
+
+	// Check that the survey accepts responses from the user
+	err = checkSurveyResponseWindow(*survey, surveyResponse.DateCreated)
+	if err != nil {
+		return nil, err
+	}
+	err = a.app.shared.checkSurveyAudience(*survey, model.SurveyAudienceUser{AccountID: surveyResponse.UserID, Permissions: permissions, ExternalIDs: externalIDs})
+	if err != nil {
+		return nil, err
+	}
+
 	// Populate survey with data from client request
-	survey.Data = surveyResponse.Survey.Data
-	survey.SurveyStats = surveyResponse.Survey.SurveyStats
-	survey.ResultJSON = surveyResponse.Survey.ResultJSON
-	surveyResponse.Survey = *survey
+	err = a.populateSurveyResponse(&surveyResponse, *survey, surveyResponse.Survey)
+	if err != nil {
+		return nil, err
+	}
 
 	if survey.CalendarEventID != "" {
 		// check if user attended calendar event
@@ -139,49 +385,314 @@ func (a appClient) CreateSurveyResponse(surveyResponse model.SurveyResponse, ext
 		}
 	}
 
-	return a.app.storage.CreateSurveyResponse(surveyResponse)
+	// Check the survey response policy
+	limitKeys, err := surveyResponseLimitKeys(a.app.storage, *survey, surveyResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *model.SurveyResponse
+	err = storeWithLimitKey(limitKeys, func(limitKey string) error {
+		surveyResponse.LimitKey = limitKey
+		var createErr error
+		created, createErr = a.app.storage.CreateSurveyResponse(surveyResponse)
+		return createErr
+	})
+	if _, ok := err.(*model.DuplicateKeyError); ok {
+		// a concurrent request created the response first
+		if surveyResponse.IdempotencyKey != "" {
+			existing, getErr := a.app.storage.GetSurveyResponseByIdempotencyKey(surveyResponse.OrgID, surveyResponse.AppID, surveyResponse.UserID, surveyResponse.Survey.ID, surveyResponse.IdempotencyKey)
+			if getErr == nil && existing != nil {
+				return existing, nil
+			}
+		}
+		if survey.ResponsePolicy != nil {
+			return nil, &model.SurveyResponseLimitError{Code: model.ResponseLimitReached, Policy: *survey.ResponsePolicy}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.app.shared.recordAuditEvent(audit, model.AuditEntitySurveyResponse, created.ID, created.OrgID, created.AppID, nil, *created)
+	a.app.triggerSurveyAlerts(*survey, surveyResponse)
+	return created, nil
 }
 
 // UpdateSurveyResponse updates the provided survey response
-func (a appClient) UpdateSurveyResponse(surveyResponse model.SurveyResponse) error {
-	return a.app.storage.UpdateSurveyResponse(surveyResponse)
+func (a appClient) UpdateSurveyResponse(surveyResponse model.SurveyResponse, externalIDs map[string]string, permissions []string, audit model.AuditContext) error {
+	existing, err := a.app.storage.GetSurveyResponse(surveyResponse.ID, surveyResponse.OrgID, surveyResponse.AppID, surveyResponse.UserID)
+	if err != nil {
+		return err
+	}
+	if existing.InProgress() {
+		// in progress responses are saved one answer at a time and finalized
+		return errors.ErrorData(logutils.StatusInvalid, model.TypeSurveyResponse, &logutils.FieldArgs{"id": existing.ID, "status": existing.Status})
+	}
+
+	// Get survey from storage
+	survey, err := a.app.storage.GetSurvey(existing.Survey.ID, existing.OrgID, existing.AppID)
+	if err != nil {
+		return err
+	}
+
+	// Check that the survey still accepts responses from the user
+	err = checkSurveyResponseWindow(*survey, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	err = a.app.shared.checkSurveyAudience(*survey, model.SurveyAudienceUser{AccountID: existing.UserID, Permissions: permissions, ExternalIDs: externalIDs})
+	if err != nil {
+		return err
+	}
+
+	// Responses to single response surveys cannot be edited
+	if survey.ResponsePolicyMode() == model.ResponsePolicySingle {
+		return &model.SurveyResponseLimitError{Code: model.ResponseNotEditable, Policy: *survey.ResponsePolicy}
+	}
+
+	// Populate survey with data from client request
+	before := *existing
+	err = a.populateSurveyResponse(existing, *survey, surveyResponse.Survey)
+	if err != nil {
+		return err
+	}
+
+	err = a.app.storage.UpdateSurveyResponse(*existing)
+	if err != nil {
+		return err
+	}
+
+	a.app.shared.recordAuditEvent(audit, model.AuditEntitySurveyResponse, existing.ID, existing.OrgID, existing.AppID, before, *existing)
+	return nil
 }
 
-// DeleteSurveyResponse deletes the survey with the specified ID
-func (a appClient) DeleteSurveyResponse(id string, orgID string, appID string, userID string) error {
-	return a.app.storage.DeleteSurveyResponse(id, orgID, appID, userID)
+// GetSurveyResponseDraft returns the in progress response of the user to the survey with the provided ID, or nil if there is none
+func (a appClient) GetSurveyResponseDraft(surveyID string, orgID string, appID string, userID string) (*model.SurveyResponse, error) {
+	return a.app.storage.GetSurveyResponseDraft(orgID, appID, userID, surveyID)
 }
 
-// DeleteSurveyResponses deletes the survey responses matching the provided filters
-func (a appClient) DeleteSurveyResponses(orgID string, appID string, userID string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time) error {
-	return a.app.storage.DeleteSurveyResponses(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate)
+// SaveSurveyResponseDraft saves the answer to a single question in the in progress response of the user to a survey.
+// The response is created with the first saved answer.
+func (a appClient) SaveSurveyResponseDraft(surveyID string, orgID string, appID string, userID string, key string, answer model.SurveyData, externalIDs map[string]string, permissions []string, audit model.AuditContext) (*model.SurveyResponse, error) {
+	survey, err := a.app.storage.GetSurvey(surveyID, orgID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the survey accepts responses from the user
+	now := time.Now().UTC()
+	err = checkSurveyResponseWindow(*survey, now)
+	if err != nil {
+		return nil, err
+	}
+	err = a.app.shared.checkSurveyAudience(*survey, model.SurveyAudienceUser{AccountID: userID, Permissions: permissions, ExternalIDs: externalIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the answer against the question definition. Required questions are checked when the response is finalized.
+	question, ok := survey.Data[key]
+	if !ok {
+		return nil, &model.SurveyResponseValidationError{Errors: []model.SurveyDataError{surveyDataError(key, model.ValidationCodeUnknownKey, "question does not exist in survey")}}
+	}
+	question.Response = answer.Response
+	if response := utils.NormalizeValue(answer.Response); !isEmptyResponse(response) {
+		validationErrors := validateSurveyDataResponse(key, question, response)
+		if len(validationErrors) > 0 {
+			return nil, &model.SurveyResponseValidationError{Errors: validationErrors}
+		}
+	}
+
+	draft, err := a.app.storage.GetSurveyResponseDraft(orgID, appID, userID, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		draft = newSurveyResponseDraft(*survey, userID, now)
+		draft.Survey.Data[key] = question
+		created, err := a.app.storage.CreateSurveyResponse(*draft)
+		if err == nil {
+			a.app.shared.recordAuditEvent(audit, model.AuditEntitySurveyResponse, created.ID, created.OrgID, created.AppID, nil, *created)
+		}
+		if _, ok := err.(*model.DuplicateKeyError); !ok {
+			return created, err
+		}
+
+		// a concurrent request created the draft first
+		draft, err = a.app.storage.GetSurveyResponseDraft(orgID, appID, userID, surveyID)
+		if err != nil {
+			return nil, err
+		}
+		if draft == nil {
+			return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurveyResponse, &logutils.FieldArgs{"survey_id": surveyID, "status": model.SurveyResponseStatusInProgress})
+		}
+	}
+
+	before := *draft
+	before.Survey.Data = make(map[string]model.SurveyData, len(draft.Survey.Data))
+	for dataKey, data := range draft.Survey.Data {
+		before.Survey.Data[dataKey] = data
+	}
+	if draft.Survey.Data == nil {
+		draft.Survey.Data = make(map[string]model.SurveyData)
+	}
+	draft.Survey.Data[key] = question
+	err = a.app.storage.UpdateSurveyResponseDraft(*draft)
+	if err != nil {
+		return nil, err
+	}
+	draft.DateUpdated = &now
+	a.app.shared.recordAuditEvent(audit, model.AuditEntitySurveyResponse, draft.ID, draft.OrgID, draft.AppID, before, *draft)
+	return draft, nil
 }
 
-// Survey Alerts
-// CreateSurveyAlert creates a new survey alert
-func (a appClient) CreateSurveyAlert(surveyAlert model.SurveyAlert) error {
-	contacts, err := a.app.storage.GetAlertContactsByKey(surveyAlert.ContactKey, surveyAlert.OrgID, surveyAlert.AppID)
+// FinalizeSurveyResponseDraft validates the saved answers of the in progress response of the user to a survey and completes the response
+func (a appClient) FinalizeSurveyResponseDraft(surveyID string, orgID string, appID string, userID string, externalIDs map[string]string, permissions []string, audit model.AuditContext) (*model.SurveyResponse, error) {
+	draft, err := a.app.storage.GetSurveyResponseDraft(orgID, appID, userID, surveyID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if draft == nil {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurveyResponse, &logutils.FieldArgs{"survey_id": surveyID, "status": model.SurveyResponseStatusInProgress})
 	}
 
-	for i := 0; i < len(contacts); i++ {
-		if contacts[i].Type == "email" {
-			subject, ok := surveyAlert.Content["subject"].(string)
-			if !ok {
-				return errors.ErrorData(logutils.StatusMissing, "subject", nil)
-			}
-			body, ok := surveyAlert.Content["body"].(string)
-			if !ok {
-				return errors.ErrorData(logutils.StatusMissing, "body", nil)
-			}
-			a.app.notifications.SendMail(contacts[i].Address, subject, body)
+	// Get survey from storage
+	survey, err := a.app.storage.GetSurvey(surveyID, orgID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The response is created when it is finalized
+	before := *draft
+	now := time.Now().UTC()
+	draft.DateCreated = now
+	draft.DateUpdated = &now
+
+	// Check that the survey accepts responses from the user
+	err = checkSurveyResponseWindow(*survey, now)
+	if err != nil {
+		return nil, err
+	}
+	err = a.app.shared.checkSurveyAudience(*survey, model.SurveyAudienceUser{AccountID: userID, Permissions: permissions, ExternalIDs: externalIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	// Evaluate the saved answers against the current survey definition
+	err = a.populateSurveyResponse(draft, *survey, draft.Survey)
+	if err != nil {
+		return nil, err
+	}
+
+	if survey.CalendarEventID != "" {
+		// check if user attended calendar event
+		attended, err := a.app.shared.hasAttendedEvent(orgID, appID, survey.CalendarEventID, userID, externalIDs)
+		if err != nil {
+			return nil, errors.WrapErrorAction("checking", "event attendance", nil, err)
 		}
+		if !attended {
+			return nil, errors.Newf("user has not attended calendar event")
+		}
+	}
+
+	// Check the survey response policy
+	limitKeys, err := surveyResponseLimitKeys(a.app.storage, *survey, *draft)
+	if err != nil {
+		return nil, err
+	}
+
+	err = storeWithLimitKey(limitKeys, func(limitKey string) error {
+		draft.LimitKey = limitKey
+		return a.app.storage.CompleteSurveyResponseDraft(*draft)
+	})
+	if _, ok := err.(*model.DuplicateKeyError); ok && survey.ResponsePolicy != nil {
+		// a concurrent request reached the response limit first
+		return nil, &model.SurveyResponseLimitError{Code: model.ResponseLimitReached, Policy: *survey.ResponsePolicy}
+	}
+	if err != nil {
+		return nil, err
+	}
+	draft.Status = model.SurveyResponseStatusCompleted
+	a.app.shared.recordAuditEvent(audit, model.AuditEntitySurveyResponse, draft.ID, draft.OrgID, draft.AppID, before, *draft)
+	a.app.triggerSurveyAlerts(*survey, *draft)
+	return draft, nil
+}
+
+// populateSurveyResponse evaluates the answers from the client request against the survey and sets the survey,
+// answers, computed stats and result on the survey response
+func (a appClient) populateSurveyResponse(surveyResponse *model.SurveyResponse, survey model.Survey, answers model.Survey) error {
+	// Evaluate the survey rules and validate the answers against the survey definition
+	evaluation := evaluateSurveyResponse(survey, answers.Data)
+	if len(evaluation.ValidationErrors) > 0 {
+		return &model.SurveyResponseValidationError{Errors: evaluation.ValidationErrors}
+	}
+	if len(evaluation.RuleErrors) > 0 {
+		a.app.logger.Warnf("error evaluating rules for survey %s response %s: %v", survey.ID, surveyResponse.ID, evaluation.RuleErrors)
+	}
+
+	// Compute the stats instead of trusting the ones sent by the client, but keep the client stats if they do not match
+	stats := evaluation.SurveyStats
+	surveyResponse.ClientStats = nil
+	if answers.SurveyStats != nil && !surveyStatsMatch(*answers.SurveyStats, stats) {
+		a.app.logger.Warnf("survey stats mismatch for survey %s response %s: client %v, computed %v", survey.ID, surveyResponse.ID, *answers.SurveyStats, stats)
+		surveyResponse.ClientStats = answers.SurveyStats
 	}
 
+	// Use the result computed from the result rules, falling back to the client result if they could not be evaluated
+	resultJSON := answers.ResultJSON
+	if evaluation.ResultJSON != "" {
+		resultJSON = evaluation.ResultJSON
+	}
+
+	survey.Data = evaluation.Data
+	survey.SurveyStats = &stats
+	survey.ResultJSON = resultJSON
+	surveyResponse.Survey = survey.RespondentView()
+	surveyResponse.SurveyRevision = survey.Revision
 	return nil
 }
 
+// DeleteSurveyResponse deletes the survey with the specified ID
+func (a appClient) DeleteSurveyResponse(id string, orgID string, appID string, userID string, audit model.AuditContext) error {
+	surveyResponse, err := a.app.storage.GetSurveyResponse(id, orgID, appID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = a.app.storage.DeleteSurveyResponse(id, orgID, appID, userID)
+	if err != nil {
+		return err
+	}
+
+	a.app.shared.recordAuditEvent(audit, model.AuditEntitySurveyResponse, id, orgID, appID, *surveyResponse, nil)
+	return nil
+}
+
+// DeleteSurveyResponses deletes the survey responses matching the provided filters
+func (a appClient) DeleteSurveyResponses(orgID string, appID string, userID string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time, audit model.AuditContext) error {
+	surveyResponses, err := a.app.storage.GetSurveyResponses(&orgID, &appID, &userID, surveyIDs, surveyTypes, startDate, endDate, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	err = a.app.storage.DeleteSurveyResponses(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	for _, surveyResponse := range surveyResponses {
+		a.app.shared.recordAuditEvent(audit, model.AuditEntitySurveyResponse, surveyResponse.ID, orgID, appID, surveyResponse, nil)
+	}
+	return nil
+}
+
+// Survey Alerts
+// CreateSurveyAlert sends a survey alert to the alert contacts sharing its contact key and returns the result of each delivery
+func (a appClient) CreateSurveyAlert(surveyAlert model.SurveyAlert) ([]model.AlertDelivery, error) {
+	return a.app.sendSurveyAlert(surveyAlert)
+}
+
 // GetUserData returns surveys matching the provided query
 func (a appClient) GetUserData(orgID string, appID string, userID *string) (*model.UserData, error) {
 	return a.app.shared.getUserData(orgID, appID, userID)
@@ -13,3 +13,993 @@
 // limitations under the License.
 
 package core_test
+
+import (
+	"application/core/interfaces/mocks"
+	"application/core/model"
+	"application/driven/calendar"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rokwire/core-auth-library-go/v3/authutils"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAppClient_CreateSurveyResponse(t *testing.T) {
+	minimum, maximum, wholeNum, maxLength := 1.0, 5.0, true, 10
+	first, second := "rating", "comment"
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", DefaultDataKey: &first, Data: map[string]model.SurveyData{
+		first:  {Type: model.SurveyDataTypeNumeric, Minimum: &minimum, Maximum: &maximum, WholeNum: &wholeNum, DefaultFollowUpKey: &second},
+		second: {Type: model.SurveyDataTypeText, MaxLength: &maxLength, AllowSkip: true},
+		"pet":  {Type: model.SurveyDataTypeMultipleChoice, AllowSkip: true, Options: []model.OptionData{{Title: "Cat", Value: "cat"}, {Title: "Dog", Value: "dog"}}},
+	}}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", "survey", "org", "app").Return(func(id string, orgID string, appID string) *model.Survey {
+		stored := survey
+		return &stored
+	}, nil)
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(&model.SurveyResponse{}, nil).Maybe()
+	app := buildTestApplication(storage)
+
+	tests := []struct {
+		name      string
+		data      map[string]model.SurveyData
+		wantCodes []string
+	}{
+		{"valid", map[string]model.SurveyData{first: {Response: 3.0}, second: {Response: "great"}, "pet": {Response: "dog"}}, nil},
+		{"skipped optional", map[string]model.SurveyData{first: {Response: 5.0}}, nil},
+		{"missing required", map[string]model.SurveyData{second: {Response: "great"}}, []string{model.ValidationCodeMissing}},
+		{"out of range", map[string]model.SurveyData{first: {Response: 6.0}}, []string{model.ValidationCodeOutOfRange}},
+		{"not whole", map[string]model.SurveyData{first: {Response: 2.5}}, []string{model.ValidationCodeNotWholeNumber}},
+		{"wrong type", map[string]model.SurveyData{first: {Response: "3"}}, []string{model.ValidationCodeInvalidType}},
+		{"too long", map[string]model.SurveyData{first: {Response: 1.0}, second: {Response: "far too long"}}, []string{model.ValidationCodeInvalidLength}},
+		{"invalid option", map[string]model.SurveyData{first: {Response: 1.0}, "pet": {Response: "fish"}}, []string{model.ValidationCodeInvalidOption}},
+		{"multiple not allowed", map[string]model.SurveyData{first: {Response: 1.0}, "pet": {Response: []interface{}{"cat", "dog"}}}, []string{model.ValidationCodeMultipleNotAllowed}},
+		{"unknown key", map[string]model.SurveyData{first: {Response: 1.0}, "other": {Response: true}}, []string{model.ValidationCodeUnknownKey}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			surveyResponse := model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "survey", Data: tt.data}}
+			_, err := app.Client.CreateSurveyResponse(surveyResponse, nil, nil, model.AuditContext{})
+			if len(tt.wantCodes) == 0 {
+				if err != nil {
+					t.Errorf("appClient.CreateSurveyResponse() error = %v, want nil", err)
+				}
+				return
+			}
+
+			validationErr, ok := err.(*model.SurveyResponseValidationError)
+			if !ok {
+				t.Fatalf("appClient.CreateSurveyResponse() error = %v, want validation error", err)
+			}
+			if len(validationErr.Errors) != len(tt.wantCodes) {
+				t.Fatalf("appClient.CreateSurveyResponse() errors = %v, want codes %v", validationErr.Errors, tt.wantCodes)
+			}
+			for i, code := range tt.wantCodes {
+				if validationErr.Errors[i].Code != code {
+					t.Errorf("appClient.CreateSurveyResponse() errors[%d].Code = %s, want %s", i, validationErr.Errors[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_NoPath(t *testing.T) {
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", Data: map[string]model.SurveyData{
+		"name":  {Type: model.SurveyDataTypeText},
+		"notes": {Type: model.SurveyDataTypeText, AllowSkip: true},
+	}}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(&model.SurveyResponse{}, nil).Maybe()
+	app := buildTestApplication(storage)
+
+	// without a default data key there is no path, so every required question is checked
+	response := model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "survey", Data: map[string]model.SurveyData{"notes": {Response: "none"}}}}
+	_, err := app.Client.CreateSurveyResponse(response, nil, nil, model.AuditContext{})
+	validationErr, ok := err.(*model.SurveyResponseValidationError)
+	if !ok || len(validationErr.Errors) != 1 || validationErr.Errors[0].Key != "name" || validationErr.Errors[0].Code != model.ValidationCodeMissing {
+		t.Errorf("Client.CreateSurveyResponse() error = %v, want missing answer to name", err)
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_FollowUpRule(t *testing.T) {
+	start := "pet"
+	followUpRule := `{"condition": {"operator": "==", "data_key": "data.pet", "compare_to": "dog"}, "true_result": {"action": "return", "data": "breed"}}`
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", DefaultDataKey: &start, Data: map[string]model.SurveyData{
+		start:   {Type: model.SurveyDataTypeText, FollowUpRule: &followUpRule},
+		"breed": {Type: model.SurveyDataTypeText, AllowSkip: true},
+		"dog":   {Type: model.SurveyDataTypeText, AllowSkip: true},
+	}}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(&model.SurveyResponse{}, nil).Maybe()
+	app := buildTestApplication(storage)
+
+	tests := []struct {
+		name      string
+		data      map[string]model.SurveyData
+		wantCodes []string
+	}{
+		// "dog" is only compared to by the rule, so it is not linked and may be answered independently
+		{"compared key not linked", map[string]model.SurveyData{start: {Response: "cat"}, "dog": {Response: "none"}}, nil},
+		{"follow-up on path", map[string]model.SurveyData{start: {Response: "dog"}, "breed": {Response: "beagle"}}, nil},
+		{"follow-up not on path", map[string]model.SurveyData{start: {Response: "cat"}, "breed": {Response: "beagle"}}, []string{model.ValidationCodeNotOnPath}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "survey", Data: tt.data}}
+			_, err := app.Client.CreateSurveyResponse(response, nil, nil, model.AuditContext{})
+			if len(tt.wantCodes) == 0 {
+				if err != nil {
+					t.Errorf("appClient.CreateSurveyResponse() error = %v, want nil", err)
+				}
+				return
+			}
+			validationErr, ok := err.(*model.SurveyResponseValidationError)
+			if !ok || len(validationErr.Errors) != len(tt.wantCodes) || validationErr.Errors[0].Code != tt.wantCodes[0] {
+				t.Errorf("appClient.CreateSurveyResponse() error = %v, want codes %v", err, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_Stats(t *testing.T) {
+	one, two, five, section := 1.0, 2.0, 5.0, "mood"
+	selfScore := true
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", Scored: true, Data: map[string]model.SurveyData{
+		"feeling": {Type: model.SurveyDataTypeMultipleChoice, Section: &section, Options: []model.OptionData{{Value: "good", Score: &two}, {Value: "bad", Score: &one}}},
+		"rating":  {Type: model.SurveyDataTypeNumeric, Section: &section, SelfScore: &selfScore, Maximum: &five},
+		"quiz":    {Type: model.SurveyDataTypeTrueFalse, CorrectAnswer: true},
+		"notes":   {Type: model.SurveyDataTypeText, AllowSkip: true},
+	}}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", "survey", "org", "app").Return(func(id string, orgID string, appID string) *model.Survey {
+		stored := survey
+		return &stored
+	}, nil)
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(func(surveyResponse model.SurveyResponse) *model.SurveyResponse {
+		return &surveyResponse
+	}, nil)
+	app := buildTestApplication(storage)
+
+	data := map[string]model.SurveyData{"feeling": {Response: "good"}, "rating": {Response: 4.0}, "quiz": {Response: false}}
+	clientStats := model.SurveyStats{Scored: 3, Scores: map[string]float64{section: 100}}
+	created, err := app.Client.CreateSurveyResponse(model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "survey", Data: data, SurveyStats: &clientStats}}, nil, nil, model.AuditContext{})
+	if err != nil {
+		t.Fatalf("appClient.CreateSurveyResponse() error = %v", err)
+	}
+
+	want := model.SurveyStats{Total: 4, Complete: 3, Scored: 3, Scores: map[string]float64{section: 6, "": 0}, MaximumScores: map[string]float64{section: 7, "": 1}, ResponseData: map[string]interface{}{}}
+	if !reflect.DeepEqual(*created.Survey.SurveyStats, want) {
+		t.Errorf("appClient.CreateSurveyResponse() stats = %v, want %v", *created.Survey.SurveyStats, want)
+	}
+	if created.ClientStats == nil || !reflect.DeepEqual(*created.ClientStats, clientStats) {
+		t.Errorf("appClient.CreateSurveyResponse() client stats = %v, want %v", created.ClientStats, clientStats)
+	}
+}
+
+func TestAppClient_ValidateSurvey(t *testing.T) {
+	first, second, missing, one := "q1", "q2", "missing", 1.0
+	followUpRule := `{"condition": {"operator": "==", "data_key": "data.q1", "compare_to": true}, "true_result": {"action": "return", "data": "q1"}}`
+	survey := model.Survey{
+		DefaultDataKey: &first,
+		Data: map[string]model.SurveyData{
+			first:    {Type: model.SurveyDataTypeTrueFalse, FollowUpRule: &followUpRule, DefaultFollowUpKey: &second},
+			second:   {Type: model.SurveyDataTypeMultipleChoice, DefaultFollowUpKey: &missing, Options: []model.OptionData{{Value: "a", Score: &one}, {Value: "a"}}},
+			"orphan": {Type: model.SurveyDataTypeText, ScoreRule: &missing},
+		},
+		Strings:  map[string]interface{}{"en": map[string]interface{}{"unused": "Unused"}},
+		SubRules: map[string]interface{}{"rule": map[string]interface{}{"rule_key": "other"}},
+	}
+	app := buildTestApplication(mocks.NewStorage(t))
+
+	lint := app.Client.ValidateSurvey(survey)
+	if lint.Valid {
+		t.Errorf("appClient.ValidateSurvey() valid = true, want false")
+	}
+
+	codes := func(issues []model.SurveyLintIssue) map[string]string {
+		found := map[string]string{}
+		for _, issue := range issues {
+			found[issue.Path] = issue.Code
+		}
+		return found
+	}
+	wantErrors := map[string]string{
+		"data.orphan.score_rule":        model.LintCodeInvalidRule,
+		"data.q1":                       model.LintCodeCycle,
+		"data.q2.default_follow_up_key": model.LintCodeDanglingReference,
+		"data.q2.options.1":             model.LintCodeDuplicateOption,
+		"sub_rules.rule":                model.LintCodeDanglingReference,
+	}
+	if got := codes(lint.Errors); !reflect.DeepEqual(got, wantErrors) {
+		t.Errorf("appClient.ValidateSurvey() errors = %v, want %v", got, wantErrors)
+	}
+	wantWarnings := map[string]string{
+		"data.orphan":    model.LintCodeUnreachable,
+		"data.q2":        model.LintCodeInvalidScore,
+		"strings.unused": model.LintCodeUnused,
+		"sub_rules.rule": model.LintCodeUnused,
+	}
+	if got := codes(lint.Warnings); !reflect.DeepEqual(got, wantWarnings) {
+		t.Errorf("appClient.ValidateSurvey() warnings = %v, want %v", got, wantWarnings)
+	}
+}
+
+func TestAppClient_PublishSurvey(t *testing.T) {
+	start := time.Now().Add(time.Hour)
+	end := time.Now().Add(-time.Hour)
+	surveys := map[string]model.Survey{
+		"draft":     {ID: "draft", CreatorID: "user", Status: model.SurveyStatusDraft},
+		"scheduled": {ID: "scheduled", CreatorID: "user", Status: model.SurveyStatusDraft, StartDate: &start},
+		"ended":     {ID: "ended", CreatorID: "user", Status: model.SurveyStatusDraft, EndDate: &end},
+		"archived":  {ID: "archived", CreatorID: "user", Status: model.SurveyStatusArchived},
+		"other":     {ID: "other", CreatorID: "other", Status: model.SurveyStatusDraft},
+	}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", mock.AnythingOfType("string"), "org", "app").Return(func(id string, orgID string, appID string) *model.Survey {
+		survey := surveys[id]
+		return &survey
+	}, nil)
+	storage.On("UpdateSurveyStatus", mock.AnythingOfType("string"), "org", "app", model.SurveyStatusDraft, mock.AnythingOfType("string")).Return(nil).Maybe()
+	app := buildTestApplication(storage)
+
+	tests := []struct {
+		id         string
+		wantStatus string
+		wantErr    bool
+	}{
+		{"draft", model.SurveyStatusPublished, false},
+		{"scheduled", model.SurveyStatusScheduled, false},
+		{"ended", "", true},
+		{"archived", "", true},
+		{"other", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := app.Client.PublishSurvey(tt.id, "org", "app", "user", nil, model.AuditContext{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.PublishSurvey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Status != tt.wantStatus {
+				t.Errorf("Client.PublishSurvey() status = %s, want %s", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_Window(t *testing.T) {
+	now := time.Now().UTC()
+	future, past, recent := now.Add(time.Hour), now.Add(-2*time.Hour), now.Add(-10*time.Minute)
+	grace, open, closed := 30, true, false
+	surveys := map[string]model.Survey{
+		"not_open":   {StartDate: &future},
+		"closed":     {EndDate: &past},
+		"grace":      {EndDate: &recent, GracePeriod: &grace},
+		"grace_over": {EndDate: &past, GracePeriod: &grace},
+		"override":   {EndDate: &past, ResponseWindowOverride: &open},
+		"forced":     {ResponseWindowOverride: &closed},
+	}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", mock.AnythingOfType("string"), "org", "app").Return(func(id string, orgID string, appID string) *model.Survey {
+		survey := surveys[id]
+		survey.ID = id
+		return &survey
+	}, nil)
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(&model.SurveyResponse{}, nil).Maybe()
+	app := buildTestApplication(storage)
+
+	tests := []struct {
+		id       string
+		wantCode string
+	}{
+		{"not_open", model.ResponseWindowNotOpen},
+		{"closed", model.ResponseWindowClosed},
+		{"grace", ""},
+		{"grace_over", model.ResponseWindowClosed},
+		{"override", ""},
+		{"forced", model.ResponseWindowClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			response := model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: tt.id}}
+			_, err := app.Client.CreateSurveyResponse(response, nil, nil, model.AuditContext{})
+			var code string
+			if windowErr, ok := err.(*model.SurveyResponseWindowError); ok {
+				code = windowErr.Code
+			} else if err != nil {
+				t.Fatalf("Client.CreateSurveyResponse() error = %v", err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("Client.CreateSurveyResponse() code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_Archived(t *testing.T) {
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", Status: model.SurveyStatusArchived}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+	app := buildTestApplication(storage)
+
+	response := model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "survey"}}
+	if _, err := app.Client.CreateSurveyResponse(response, nil, nil, model.AuditContext{}); err == nil {
+		t.Error("Client.CreateSurveyResponse() error = nil, want error for archived survey")
+	}
+}
+
+func TestAppClient_EvaluateSurvey_Draft(t *testing.T) {
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", CreatorID: "creator", Status: model.SurveyStatusDraft}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+	app := buildTestApplication(storage)
+
+	if _, err := app.Client.EvaluateSurvey("survey", "org", "app", "user", nil, nil, nil); err == nil {
+		t.Error("Client.EvaluateSurvey() error = nil, want error for draft of another user")
+	}
+	if _, err := app.Client.EvaluateSurvey("survey", "org", "app", "creator", nil, nil, nil); err != nil {
+		t.Errorf("Client.EvaluateSurvey() error = %v, want nil for creator", err)
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_Recurrence(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	started, between, ended := now.AddDate(0, 0, -3).Add(-30*time.Minute), now.AddDate(0, 0, -3).Add(-2*time.Hour), now.AddDate(0, 0, -2).Add(-30*time.Minute)
+	grace := 90
+	daily := &model.SurveyRecurrence{Rule: "FREQ=DAILY", Duration: 60}
+	surveys := map[string]model.Survey{
+		"open":    {StartDate: &started, Recurrence: daily},
+		"between": {StartDate: &between, Recurrence: daily},
+		"grace":   {StartDate: &between, Recurrence: daily, GracePeriod: &grace},
+		"ended":   {StartDate: &ended, Recurrence: &model.SurveyRecurrence{Rule: "FREQ=DAILY;COUNT=2", Duration: 60}},
+		"weekly":  {StartDate: &between, Recurrence: &model.SurveyRecurrence{Rule: "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR,SA,SU"}},
+	}
+	for id, survey := range surveys {
+		survey.ResponsePolicy = &model.SurveyResponsePolicy{Mode: model.ResponsePolicySingle}
+		surveys[id] = survey
+	}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", mock.AnythingOfType("string"), "org", "app").Return(func(id string, orgID string, appID string) *model.Survey {
+		survey := surveys[id]
+		survey.ID = id
+		return &survey
+	}, nil)
+	since := map[string]*time.Time{}
+	storage.On("CountSurveyResponses", "org", "app", "user", mock.AnythingOfType("string"), mock.Anything).Return(func(orgID string, appID string, userID string, surveyID string, start *time.Time) int64 {
+		since[surveyID] = start
+		return 0
+	}, nil).Maybe()
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(&model.SurveyResponse{}, nil).Maybe()
+	app := buildTestApplication(storage)
+
+	tests := []struct {
+		id          string
+		wantCode    string
+		windowStart time.Time
+	}{
+		{"open", "", started.AddDate(0, 0, 3)},
+		{"between", model.ResponseWindowNotOpen, time.Time{}},
+		{"grace", "", between.AddDate(0, 0, 3)},
+		{"ended", model.ResponseWindowClosed, time.Time{}},
+		{"weekly", "", between.AddDate(0, 0, 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			response := model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: tt.id}}
+			_, err := app.Client.CreateSurveyResponse(response, nil, nil, model.AuditContext{})
+			var code string
+			if windowErr, ok := err.(*model.SurveyResponseWindowError); ok {
+				code = windowErr.Code
+				if code == model.ResponseWindowNotOpen && (windowErr.StartDate == nil || !windowErr.StartDate.After(now)) {
+					t.Errorf("Client.CreateSurveyResponse() next window = %v, want a future start", windowErr.StartDate)
+				}
+			} else if err != nil {
+				t.Fatalf("Client.CreateSurveyResponse() error = %v", err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("Client.CreateSurveyResponse() code = %q, want %q", code, tt.wantCode)
+			}
+			if code == "" && (since[tt.id] == nil || !since[tt.id].Equal(tt.windowStart)) {
+				t.Errorf("Client.CreateSurveyResponse() counted responses since %v, want %v", since[tt.id], tt.windowStart)
+			}
+		})
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_Policy(t *testing.T) {
+	surveys := map[string]model.Survey{
+		"single": {ResponsePolicy: &model.SurveyResponsePolicy{Mode: model.ResponsePolicySingle}},
+		"daily":  {ResponsePolicy: &model.SurveyResponsePolicy{Mode: model.ResponsePolicyPerPeriod, Limit: 2, Period: model.ResponsePeriodDay}},
+	}
+	counts := map[string]int64{"single": 1, "daily": 1}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", mock.AnythingOfType("string"), "org", "app").Return(func(id string, orgID string, appID string) *model.Survey {
+		survey := surveys[id]
+		survey.ID = id
+		return &survey
+	}, nil)
+	storage.On("CountSurveyResponses", "org", "app", "user", mock.AnythingOfType("string"), mock.Anything).Return(func(orgID string, appID string, userID string, surveyID string, since *time.Time) int64 {
+		return counts[surveyID]
+	}, nil)
+	storage.On("GetSurveyResponseByIdempotencyKey", "org", "app", "user", "single", "retry").Return(&model.SurveyResponse{ID: "existing"}, nil)
+	storage.On("GetSurveyResponseByIdempotencyKey", "org", "app", "user", "daily", "retry").Return(nil, nil)
+	var limitKey string
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(func(surveyResponse model.SurveyResponse) *model.SurveyResponse {
+		limitKey = surveyResponse.LimitKey
+		return &surveyResponse
+	}, nil).Maybe()
+	app := buildTestApplication(storage)
+
+	_, err := app.Client.CreateSurveyResponse(model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "single"}}, nil, nil, model.AuditContext{})
+	if limitErr, ok := err.(*model.SurveyResponseLimitError); !ok || limitErr.Code != model.ResponseLimitReached {
+		t.Errorf("Client.CreateSurveyResponse() single error = %v, want %s", err, model.ResponseLimitReached)
+	}
+
+	_, err = app.Client.CreateSurveyResponse(model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "daily"}}, nil, nil, model.AuditContext{})
+	if err != nil {
+		t.Fatalf("Client.CreateSurveyResponse() daily error = %v", err)
+	}
+	if want := "daily/user/" + time.Now().UTC().Format(time.DateOnly) + "/1"; limitKey != want {
+		t.Errorf("Client.CreateSurveyResponse() limit key = %s, want %s", limitKey, want)
+	}
+
+	got, err := app.Client.CreateSurveyResponse(model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "single"}, IdempotencyKey: "retry"}, nil, nil, model.AuditContext{})
+	if err != nil || got.ID != "existing" {
+		t.Errorf("Client.CreateSurveyResponse() retry = %v, %v, want existing response", got, err)
+	}
+
+	// idempotency keys are scoped to the survey
+	got, err = app.Client.CreateSurveyResponse(model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "daily"}, IdempotencyKey: "retry"}, nil, nil, model.AuditContext{})
+	if err != nil || got.ID == "existing" {
+		t.Errorf("Client.CreateSurveyResponse() retry on another survey = %v, %v, want new response", got, err)
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_PolicySlots(t *testing.T) {
+	survey := model.Survey{ID: "daily", ResponsePolicy: &model.SurveyResponsePolicy{Mode: model.ResponsePolicyPerPeriod, Limit: 3, Period: model.ResponsePeriodDay}}
+	prefix := "daily/user/" + time.Now().UTC().Format(time.DateOnly) + "/"
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", "daily", "org", "app").Return(&survey, nil)
+	// the response in slot 0 was deleted, slots 1 and 2 are taken
+	storage.On("CountSurveyResponses", "org", "app", "user", "daily", mock.Anything).Return(int64(2), nil)
+	var tried []string
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(func(surveyResponse model.SurveyResponse) *model.SurveyResponse {
+		tried = append(tried, surveyResponse.LimitKey)
+		if surveyResponse.LimitKey != prefix+"0" {
+			return nil
+		}
+		return &surveyResponse
+	}, func(surveyResponse model.SurveyResponse) error {
+		if surveyResponse.LimitKey != prefix+"0" {
+			return &model.DuplicateKeyError{Type: string(model.TypeSurveyResponse)}
+		}
+		return nil
+	})
+	app := buildTestApplication(storage)
+
+	created, err := app.Client.CreateSurveyResponse(model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "daily"}}, nil, nil, model.AuditContext{})
+	if err != nil || created.LimitKey != prefix+"0" {
+		t.Fatalf("Client.CreateSurveyResponse() = %v, %v, want response in slot 0", created, err)
+	}
+	if want := []string{prefix + "2", prefix + "0"}; !reflect.DeepEqual(tried, want) {
+		t.Errorf("Client.CreateSurveyResponse() tried limit keys %v, want %v", tried, want)
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_Audience(t *testing.T) {
+	audience := model.SurveyAudience{AccountIDs: []string{"listed"}, Permissions: []string{"survey_staff"},
+		ExternalIDPatterns: map[string]string{"uin": "^65[0-9]{7}$"}}
+	survey := model.Survey{ID: "survey", CreatorID: "creator", Audience: &audience}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(&model.SurveyResponse{}, nil).Maybe()
+	app := buildTestApplication(storage)
+
+	tests := []struct {
+		name        string
+		userID      string
+		permissions []string
+		externalIDs map[string]string
+		wantErr     bool
+	}{
+		{"creator", "creator", nil, nil, false},
+		{"account", "listed", nil, nil, false},
+		{"permission", "user", []string{"other", "survey_staff"}, nil, false},
+		{"external id", "user", nil, map[string]string{"uin": "650000001"}, false},
+		{"external id mismatch", "user", nil, map[string]string{"uin": "123456789"}, true},
+		{"outside", "user", []string{"other"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := model.SurveyResponse{OrgID: "org", AppID: "app", UserID: tt.userID, Survey: model.Survey{ID: "survey"}}
+			_, err := app.Client.CreateSurveyResponse(response, tt.externalIDs, tt.permissions, model.AuditContext{})
+			if _, ok := err.(*model.SurveyAudienceError); ok != tt.wantErr {
+				t.Errorf("Client.CreateSurveyResponse() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && !tt.wantErr {
+				t.Fatalf("Client.CreateSurveyResponse() error = %v", err)
+			}
+
+			got, err := app.Client.GetSurvey("survey", "org", "app", tt.userID, tt.externalIDs, tt.permissions)
+			if _, ok := err.(*model.SurveyAudienceError); ok != tt.wantErr {
+				t.Errorf("Client.GetSurvey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// the listed account IDs are only shown to the creator
+			if got != nil && (got.Audience != nil) != (tt.userID == "creator") {
+				t.Errorf("Client.GetSurvey() audience = %v for user %s", got.Audience, tt.userID)
+			}
+		})
+	}
+}
+
+func TestAppClient_GetSurveys_Audience(t *testing.T) {
+	audiences := []model.Survey{
+		{ID: "uin", Audience: &model.SurveyAudience{ExternalIDPatterns: map[string]string{"uin": "^65[0-9]{7}$"}}},
+		{ID: "other", Audience: &model.SurveyAudience{ExternalIDPatterns: map[string]string{"uin": "^12"}}},
+		{ID: "invalid", Audience: &model.SurveyAudience{ExternalIDPatterns: map[string]string{"uin": "(?<=6)5"}}},
+	}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurveyAudiences", "org", "app", []string(nil), "").Return(audiences, nil)
+	var resolved []string
+	storage.On("GetSurveysAndSurveyResponses", "org", "app", (*string)(nil), []string(nil), []string(nil), "", (*bool)(nil), (*bool)(nil), (*int)(nil), (*int)(nil),
+		mock.Anything, mock.AnythingOfType("*model.SurveyAudienceUser"), (*model.SurveyTimeFilter)(nil), false).Return(
+		func(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, public *bool, archived *bool, limit *int, offset *int,
+			userID *string, audience *model.SurveyAudienceUser, filter *model.SurveyTimeFilter, includeDrafts bool) []model.Survey {
+			resolved = audience.SurveyIDs
+			return nil
+		}, nil, nil)
+	app := buildTestApplication(storage)
+
+	userID := "user"
+	_, _, err := app.Client.GetSurveys("org", "app", &userID, map[string]string{"uin": "650000001"}, nil, nil, nil, nil, "", nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Client.GetSurveys() error = %v", err)
+	}
+	if !reflect.DeepEqual(resolved, []string{"uin"}) {
+		t.Errorf("Client.GetSurveys() resolved audience surveys = %v, want [uin]", resolved)
+	}
+}
+
+func TestAppClient_SaveSurveyResponseDraft(t *testing.T) {
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", Revision: 2, Data: map[string]model.SurveyData{
+		"first":  {Type: model.SurveyDataTypeTrueFalse},
+		"second": {Type: model.SurveyDataTypeText},
+	}, AlertRules: []model.SurveyAlertRule{{Type: model.AlertRuleAnswer, ContactKey: "staff"}}}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+	storage.On("GetSurveyResponseDraft", "org", "app", "new", "survey").Return(nil, nil)
+	storage.On("GetSurveyResponseDraft", "org", "app", "existing", "survey").Return(func(orgID string, appID string, userID string, surveyID string) *model.SurveyResponse {
+		draft := model.SurveyResponse{ID: "draft", OrgID: orgID, AppID: appID, UserID: userID, Status: model.SurveyResponseStatusInProgress,
+			Survey: model.Survey{ID: surveyID, Data: map[string]model.SurveyData{"first": {Type: model.SurveyDataTypeTrueFalse, Response: true}}}}
+		return &draft
+	}, nil)
+	var created, updated model.SurveyResponse
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(func(surveyResponse model.SurveyResponse) *model.SurveyResponse {
+		created = surveyResponse
+		return &surveyResponse
+	}, nil)
+	storage.On("UpdateSurveyResponseDraft", mock.AnythingOfType("model.SurveyResponse")).Return(func(surveyResponse model.SurveyResponse) error {
+		updated = surveyResponse
+		return nil
+	})
+	app := buildTestApplication(storage)
+
+	_, err := app.Client.SaveSurveyResponseDraft("survey", "org", "app", "new", "first", model.SurveyData{Response: true}, nil, nil, model.AuditContext{})
+	if err != nil {
+		t.Fatalf("Client.SaveSurveyResponseDraft() new error = %v", err)
+	}
+	if !created.InProgress() || created.SurveyRevision != 2 || created.Survey.Data["first"].Response != true || created.Survey.AlertRules != nil {
+		t.Errorf("Client.SaveSurveyResponseDraft() created = %+v, want in progress draft with the first answer", created)
+	}
+
+	_, err = app.Client.SaveSurveyResponseDraft("survey", "org", "app", "existing", "second", model.SurveyData{Response: "later"}, nil, nil, model.AuditContext{})
+	if err != nil {
+		t.Fatalf("Client.SaveSurveyResponseDraft() existing error = %v", err)
+	}
+	if updated.ID != "draft" || updated.Survey.Data["first"].Response != true || updated.Survey.Data["second"].Response != "later" {
+		t.Errorf("Client.SaveSurveyResponseDraft() updated = %+v, want both answers", updated)
+	}
+
+	_, err = app.Client.SaveSurveyResponseDraft("survey", "org", "app", "new", "third", model.SurveyData{Response: true}, nil, nil, model.AuditContext{})
+	if _, ok := err.(*model.SurveyResponseValidationError); !ok {
+		t.Errorf("Client.SaveSurveyResponseDraft() unknown key error = %v, want validation error", err)
+	}
+}
+
+func TestAppClient_GetSurveyResults(t *testing.T) {
+	likert := model.SurveyDataStyleLikert
+	surveys := map[string]model.Survey{
+		"survey": {CreatorID: "creator", Data: map[string]model.SurveyData{
+			"choice":  {Type: model.SurveyDataTypeMultipleChoice},
+			"rating":  {Type: model.SurveyDataTypeNumeric},
+			"mood":    {Type: model.SurveyDataTypeMultipleChoice, Style: &likert},
+			"comment": {Type: model.SurveyDataTypeText},
+		}},
+		"sensitive": {CreatorID: "creator", Sensitive: true},
+	}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurvey", mock.AnythingOfType("string"), "org", "app").Return(func(id string, orgID string, appID string) *model.Survey {
+		survey := surveys[id]
+		survey.ID = id
+		return &survey
+	}, nil)
+	storage.On("GetSurveyResponseAggregates", "org", "app", "survey", []string{"choice", "mood", "rating"}, (*time.Time)(nil), (*time.Time)(nil), model.ResponsePeriodDay).
+		Return(&model.SurveyResponseAggregates{Responses: 4, Answers: map[string]int64{"choice": 3, "rating": 4, "mood": 2, "comment": 1},
+			Values: []model.SurveyResponseValueCount{
+				{Key: "choice", Value: "a", Count: 2}, {Key: "choice", Value: "b", Count: 2},
+				{Key: "rating", Value: int32(1), Count: 1}, {Key: "rating", Value: 2.5, Count: 1}, {Key: "rating", Value: int64(4), Count: 2},
+				{Key: "mood", Value: int32(3), Count: 2},
+			}}, nil)
+	storage.On("FindConfig", model.ConfigTypePrivacy, "app", "org").Return(&model.Config{Type: model.ConfigTypePrivacy, Data: model.PrivacyConfigData{MinGroupSize: 1}}, nil)
+	app := buildTestApplication(storage)
+
+	results, err := app.Client.GetSurveyResults("survey", "org", "app", "creator", nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("Client.GetSurveyResults() error = %v", err)
+	}
+	if choice := results.Questions["choice"]; choice.Responses != 3 || choice.Counts["a"] != 2 || choice.Counts["b"] != 2 || choice.Histogram != nil {
+		t.Errorf("Client.GetSurveyResults() choice = %+v", choice)
+	}
+	if rating := results.Questions["rating"]; len(rating.Histogram) != 3 || rating.Mean == nil || *rating.Mean != 2.875 || rating.Median == nil || *rating.Median != 3.25 {
+		t.Errorf("Client.GetSurveyResults() rating = %+v", rating)
+	}
+	if mood := results.Questions["mood"]; mood.Counts["3"] != 2 || mood.Median == nil || *mood.Median != 3 {
+		t.Errorf("Client.GetSurveyResults() mood = %+v", mood)
+	}
+	if comment := results.Questions["comment"]; comment.Responses != 1 || comment.Counts != nil || comment.Histogram != nil {
+		t.Errorf("Client.GetSurveyResults() comment = %+v", comment)
+	}
+
+	if _, err = app.Client.GetSurveyResults("sensitive", "org", "app", "creator", nil, nil, nil, ""); err == nil {
+		t.Error("Client.GetSurveyResults() sensitive error = nil, want error")
+	}
+	if _, err = app.Client.GetSurveyResults("survey", "org", "app", "other", nil, nil, nil, ""); err == nil {
+		t.Error("Client.GetSurveyResults() other user error = nil, want error")
+	}
+	if _, err = app.Client.GetSurveyResults("survey", "org", "app", "creator", nil, nil, nil, "year"); err == nil {
+		t.Error("Client.GetSurveyResults() interval error = nil, want error")
+	}
+}
+
+func TestAppClient_GetSurveyResults_Suppression(t *testing.T) {
+	survey := model.Survey{ID: "survey", CreatorID: "creator", Data: map[string]model.SurveyData{
+		"choice": {Type: model.SurveyDataTypeMultipleChoice},
+		"rating": {Type: model.SurveyDataTypeNumeric},
+		"other":  {Type: model.SurveyDataTypeMultipleChoice},
+	}}
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+	storage.On("GetSurveyResponseAggregates", "org", "app", "survey", []string{"choice", "other", "rating"}, (*time.Time)(nil), (*time.Time)(nil), model.ResponsePeriodDay).
+		Return(&model.SurveyResponseAggregates{Responses: 9, Answers: map[string]int64{"choice": 9, "rating": 9, "other": 2},
+			Values: []model.SurveyResponseValueCount{
+				{Key: "choice", Value: "a", Count: 4}, {Key: "choice", Value: "b", Count: 1}, {Key: "choice", Value: "c", Count: 1}, {Key: "choice", Value: "d", Count: 3},
+				{Key: "rating", Value: int32(1), Count: 5}, {Key: "rating", Value: int32(3), Count: 3}, {Key: "rating", Value: int32(5), Count: 1},
+				{Key: "other", Value: "x", Count: 2},
+			},
+			Timeline: []model.SurveyResponseCount{{Date: day, Count: 4}, {Date: day.AddDate(0, 0, 1), Count: 2}, {Date: day.AddDate(0, 0, 2), Count: 3}}}, nil)
+	storage.On("FindConfig", model.ConfigTypePrivacy, "app", "org").Return(nil, nil)
+	storage.On("FindConfig", model.ConfigTypePrivacy, "app", authutils.AllOrgs).Return(&model.Config{Type: model.ConfigTypePrivacy,
+		Data: model.PrivacyConfigData{MinGroupSize: 3}}, nil)
+	app := buildTestApplication(storage)
+
+	results, err := app.Client.GetSurveyResults("survey", "org", "app", "creator", nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("Client.GetSurveyResults() error = %v", err)
+	}
+	// the interval of 3 responses is left out too, otherwise the interval of 2 could be recomputed from the total
+	if results.MinGroupSize != 3 || results.Suppressed || len(results.Timeline) != 1 || results.Timeline[0].Count != 4 {
+		t.Errorf("Client.GetSurveyResults() results = %+v", results)
+	}
+	// b and c alone would single out fewer than 3 users, so the next smallest value is suppressed with them
+	want := map[string]int64{"a": 4, model.SurveyResultsSuppressedKey: 5}
+	if choice := results.Questions["choice"]; !choice.Suppressed || !reflect.DeepEqual(choice.Counts, want) {
+		t.Errorf("Client.GetSurveyResults() choice counts = %v, want %v", choice.Counts, want)
+	}
+	if rating := results.Questions["rating"]; !rating.Suppressed || len(rating.Histogram) != 1 || rating.Histogram[0].Value != 1 {
+		t.Errorf("Client.GetSurveyResults() rating histogram = %v", rating.Histogram)
+	}
+	// the mean and median would reveal the suppressed rating
+	if rating := results.Questions["rating"]; rating.Mean != nil || rating.Median != nil {
+		t.Errorf("Client.GetSurveyResults() rating mean = %v, median = %v, want nil", rating.Mean, rating.Median)
+	}
+	if other := results.Questions["other"]; !other.Suppressed || other.Counts != nil || other.Responses != 0 {
+		t.Errorf("Client.GetSurveyResults() other = %+v", other)
+	}
+}
+
+func TestAppClient_CloneSurvey(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	stats := model.SurveyStats{Total: 3}
+	surveys := map[string]model.Survey{
+		"catalog": {ID: "catalog", CreatorID: "staff", OrgID: "org", AppID: "app", Title: "Follow-up", Type: "event_follow_up", SurveyStats: &stats,
+			Data:      map[string]model.SurveyData{"q1": {Text: "How was it?", Type: "text"}},
+			Constants: map[string]interface{}{"max": 5.0}, SubRules: map[string]interface{}{"rule": "SUM(data.q1)"}, CalendarEventID: "old-event",
+			Revision: 4, Status: model.SurveyStatusClosed, Template: &model.SurveyTemplate{Name: "Follow-up", InCatalog: true}},
+		"private":  {ID: "private", CreatorID: "staff", OrgID: "org", AppID: "app", Template: &model.SurveyTemplate{Name: "Private"}},
+		"survey":   {ID: "survey", CreatorID: "user", OrgID: "org", AppID: "app"},
+		"template": {ID: "template", CreatorID: "user", OrgID: "org", AppID: "app", Template: &model.SurveyTemplate{Name: "Mine"}},
+		"weekly": {ID: "weekly", CreatorID: "user", OrgID: "org", AppID: "app", Template: &model.SurveyTemplate{Name: "Weekly check-in"},
+			Recurrence: &model.SurveyRecurrence{Rule: "FREQ=WEEKLY;BYDAY=MO", NotifyOnOpen: true}},
+	}
+
+	storage := mocks.NewStorage(t)
+	mockPerformTransaction(storage)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", mock.AnythingOfType("string"), "org", "app").Return(func(id string, orgID string, appID string) *model.Survey {
+		survey := surveys[id]
+		return &survey
+	}, nil)
+	storage.On("CreateSurvey", mock.AnythingOfType("model.Survey")).Return(func(survey model.Survey) *model.Survey {
+		return &survey
+	}, nil).Maybe()
+	storage.On("InsertSurveyRevision", mock.AnythingOfType("model.SurveyRevision")).Return(nil).Maybe()
+	app := buildTestApplication(storage)
+
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"catalog", false},
+		{"template", false},
+		{"private", true},
+		{"survey", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			title := "Follow-up for this week"
+			got, err := app.Client.CloneSurvey(tt.id, "org", "app", "user", nil, model.SurveyCloneOverrides{Title: &title, StartDate: &start}, model.AuditContext{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.CloneSurvey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.ID == "" || got.ID == tt.id || got.CreatorID != "user" || got.Title != title || got.Type != "user" || got.Revision != 1 ||
+				got.Template != nil || got.SurveyStats != nil || got.CalendarEventID != "" || got.StartDate == nil || !got.StartDate.Equal(start) ||
+				got.Status != model.SurveyStatusScheduled {
+				t.Errorf("Client.CloneSurvey() = %+v", got)
+			}
+			template := surveys[tt.id]
+			if !reflect.DeepEqual(got.Data, template.Data) || !reflect.DeepEqual(got.Constants, template.Constants) || !reflect.DeepEqual(got.SubRules, template.SubRules) {
+				t.Errorf("Client.CloneSurvey() definition = %+v, want a copy of %+v", got, template)
+			}
+			if len(got.Constants) > 0 {
+				// the copy does not share maps with the template
+				got.Constants["max"] = 10.0
+				if surveys[tt.id].Constants["max"] != 5.0 {
+					t.Errorf("Client.CloneSurvey() shares constants with the template")
+				}
+			}
+		})
+	}
+
+	// the recurrence is kept and follows the start date of the clone, which is required
+	_, err := app.Client.CloneSurvey("weekly", "org", "app", "user", nil, model.SurveyCloneOverrides{}, model.AuditContext{})
+	if err == nil {
+		t.Errorf("Client.CloneSurvey() recurring template without start date error = nil, want error")
+	}
+	got, err := app.Client.CloneSurvey("weekly", "org", "app", "user", nil, model.SurveyCloneOverrides{StartDate: &start}, model.AuditContext{})
+	if err != nil {
+		t.Fatalf("Client.CloneSurvey() recurring template error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Recurrence, surveys["weekly"].Recurrence) || got.Recurrence == surveys["weekly"].Recurrence || !got.StartDate.Equal(start) {
+		t.Errorf("Client.CloneSurvey() recurrence = %+v, start date = %v, want a copy of %+v from %v", got.Recurrence, got.StartDate, surveys["weekly"].Recurrence, start)
+	}
+}
+
+func TestAppClient_SubscribeEventSurvey(t *testing.T) {
+	surveys := map[string]model.Survey{
+		"private": {ID: "private", CreatorID: "staff", OrgID: "org", AppID: "app", Template: &model.SurveyTemplate{Name: "Private"}},
+		"survey":  {ID: "survey", CreatorID: "user", OrgID: "org", AppID: "app"},
+	}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurvey", mock.AnythingOfType("string"), "org", "app").Return(func(id string, orgID string, appID string) *model.Survey {
+		survey := surveys[id]
+		return &survey
+	}, nil).Maybe()
+	app := buildTestApplication(storage)
+
+	duration := 0
+	tests := []struct {
+		name         string
+		subscription model.EventSurveySubscription
+	}{
+		{"invalid duration", model.EventSurveySubscription{TemplateID: "survey", Duration: &duration}},
+		{"not a template", model.EventSurveySubscription{TemplateID: "survey"}},
+		{"template of another user", model.EventSurveySubscription{TemplateID: "private"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := tt.subscription
+			subscription.OrgID = "org"
+			subscription.AppID = "app"
+			subscription.CalendarEventID = "event"
+			subscription.CreatorID = "user"
+			_, err := app.Client.SubscribeEventSurvey(subscription, nil)
+			if err == nil {
+				t.Errorf("Client.SubscribeEventSurvey() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestAppClient_SubscribeEventSurvey_EventEndDate(t *testing.T) {
+	end := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	template := model.Survey{ID: "template", CreatorID: "user", OrgID: "org", AppID: "app", Template: &model.SurveyTemplate{Name: "Follow-up"}}
+	config := model.Config{Type: model.ConfigTypeEnv, AppID: authutils.AllApps, OrgID: authutils.AllOrgs, Data: model.EnvConfigData{}}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurvey", "template", "org", "app").Return(&template, nil)
+	storage.On("FindConfig", model.ConfigTypeEnv, authutils.AllApps, authutils.AllOrgs).Return(&config, nil)
+	storage.On("GetEventSurveySubscription", "event", "org", "app").Return(nil, nil)
+	storage.On("InsertEventSurveySubscription", mock.AnythingOfType("model.EventSurveySubscription")).Return(nil)
+	calendarBB := &calendarStub{events: map[string]calendar.Event{"event": {ID: "event", EndDate: &end}, "unscheduled": {ID: "unscheduled"}},
+		users: []calendar.EventPerson{{User: calendar.User{AccountID: "user"}, Role: calendar.EventRoleAdmin}}}
+	app := buildTestApplicationWithCalendar(storage, calendarBB)
+
+	// the end date sent by the client is ignored
+	subscription := model.EventSurveySubscription{OrgID: "org", AppID: "app", CalendarEventID: "event", TemplateID: "template", CreatorID: "user", EventEndDate: time.Now()}
+	got, err := app.Client.SubscribeEventSurvey(subscription, nil)
+	if err != nil {
+		t.Fatalf("Client.SubscribeEventSurvey() error = %v", err)
+	}
+	if !got.EventEndDate.Equal(end) {
+		t.Errorf("Client.SubscribeEventSurvey() event end date = %v, want %v", got.EventEndDate, end)
+	}
+
+	for _, eventID := range []string{"unscheduled", "missing"} {
+		subscription.CalendarEventID = eventID
+		_, err = app.Client.SubscribeEventSurvey(subscription, nil)
+		if err == nil {
+			t.Errorf("Client.SubscribeEventSurvey() %s event error = nil, want error", eventID)
+		}
+	}
+}
+
+func TestAppClient_MuteSurveyReminders(t *testing.T) {
+	existing := model.SurveyReminderMute{ID: "existing", OrgID: "org", AppID: "app", UserID: "user", SurveyID: "survey"}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&model.Survey{ID: "survey"}, nil)
+	storage.On("GetSurvey", "missing", "org", "app").Return(nil, nil)
+	storage.On("InsertSurveyReminderMute", mock.MatchedBy(func(mute model.SurveyReminderMute) bool { return mute.SurveyID == "" })).Return(nil)
+	storage.On("InsertSurveyReminderMute", mock.MatchedBy(func(mute model.SurveyReminderMute) bool { return mute.SurveyID == "survey" })).
+		Return(&model.DuplicateKeyError{Type: string(model.TypeSurveyReminderMute)})
+	surveyID := "survey"
+	storage.On("GetSurveyReminderMutes", "org", "app", []string{"user"}, &surveyID).Return([]model.SurveyReminderMute{{ID: "all", UserID: "user"}, existing}, nil)
+	app := buildTestApplication(storage)
+
+	tests := []struct {
+		name     string
+		surveyID string
+		wantID   string
+		wantErr  bool
+	}{
+		{"all surveys", "", "", false},
+		{"already muted", "survey", "existing", false},
+		{"missing survey", "missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := app.Client.MuteSurveyReminders("org", "app", "user", tt.surveyID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.MuteSurveyReminders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.SurveyID != tt.surveyID || (tt.wantID != "" && got.ID != tt.wantID) {
+				t.Errorf("Client.MuteSurveyReminders() = %+v, want survey %q and ID %q", got, tt.surveyID, tt.wantID)
+			}
+		})
+	}
+
+	end := time.Now().UTC().Add(time.Hour)
+	_, err := app.Client.CreateSurvey(model.Survey{OrgID: "org", AppID: "app", ReminderOffsets: []int{24}}, nil, false, model.AuditContext{})
+	if err == nil {
+		t.Error("Client.CreateSurvey() reminder offsets without end date error = nil")
+	}
+	_, err = app.Client.CreateSurvey(model.Survey{OrgID: "org", AppID: "app", EndDate: &end, ReminderOffsets: []int{0}}, nil, false, model.AuditContext{})
+	if err == nil {
+		t.Error("Client.CreateSurvey() zero reminder offset error = nil")
+	}
+}
+
+func TestAppClient_CreateSurveyAlert(t *testing.T) {
+	contacts := []model.AlertContact{
+		{ID: "email", Type: model.AlertChannelEmail, Address: "staff@example.com"},
+		{ID: "push", Type: model.AlertChannelPush, Params: map[string]interface{}{"topic": 5}},
+		{ID: "webhook", Type: model.AlertChannelWebhook, Address: "https://example.com/alerts"},
+		{ID: "sms", Type: model.AlertChannelSMS, Address: "555-0100"},
+		{ID: "fax", Type: "fax", Address: "555-0100"},
+	}
+
+	storage := mocks.NewStorage(t)
+	storage.On("GetAlertContactsByKey", "key", "org", "app").Return(contacts, nil)
+	app := buildTestApplication(storage)
+
+	// the email contact is valid but the alert has no body, the other contacts are not valid for their channels
+	deliveries, err := app.Client.CreateSurveyAlert(model.SurveyAlert{OrgID: "org", AppID: "app", ContactKey: "key",
+		Content: map[string]interface{}{"subject": "Alert"}})
+	if err != nil {
+		t.Fatalf("Client.CreateSurveyAlert() error = %v", err)
+	}
+	if len(deliveries) != len(contacts) {
+		t.Fatalf("Client.CreateSurveyAlert() deliveries = %d, want %d", len(deliveries), len(contacts))
+	}
+	for i, delivery := range deliveries {
+		if delivery.ContactID != contacts[i].ID || delivery.Status != model.AlertDeliveryInvalid || delivery.Error == "" {
+			t.Errorf("Client.CreateSurveyAlert() delivery = %+v, want invalid delivery to %s", delivery, contacts[i].ID)
+		}
+	}
+}
+
+func TestAppClient_CreateSurveyResponse_AlertRules(t *testing.T) {
+	five, threshold, section := 5.0, 4.0, "wellness"
+	selfScore := true
+	survey := model.Survey{ID: "survey", OrgID: "org", AppID: "app", Title: "Check-in", Scored: true, Data: map[string]model.SurveyData{
+		"rating": {Type: model.SurveyDataTypeNumeric, Section: &section, SelfScore: &selfScore, Maximum: &five},
+		"safe":   {Type: model.SurveyDataTypeTrueFalse},
+	}, AlertRules: []model.SurveyAlertRule{
+		{Type: model.AlertRuleScore, ContactKey: "crisis", Section: section, Threshold: &threshold, Subject: "{{.SurveyTitle}} escalation",
+			Body: "{{.UserID}} scored {{.Score}} for {{.Section}}"},
+		{Type: model.AlertRuleAnswer, ContactKey: "unsafe", Key: "safe", Value: false, Body: "{{.UserID}} answered {{.Answer}}"},
+	}}
+
+	storage := mocks.NewStorage(t)
+	mockInsertAuditEvent(storage)
+	storage.On("GetSurvey", "survey", "org", "app").Return(&survey, nil)
+	storage.On("CreateSurveyResponse", mock.AnythingOfType("model.SurveyResponse")).Return(func(surveyResponse model.SurveyResponse) *model.SurveyResponse {
+		return &surveyResponse
+	}, nil)
+	storage.On("GetAlertContactsByKey", "crisis", "org", "app").Return([]model.AlertContact{{ID: "counselor", Type: model.AlertChannelEmail, Address: "counselor@example.com"}}, nil).Once()
+	notifications := &notificationsRecorder{}
+	app := buildTestApplicationWithNotifications(storage, notifications)
+
+	// the score rule matches, the answer rule does not
+	data := map[string]model.SurveyData{"rating": {Response: 4.0}, "safe": {Response: true}}
+	_, err := app.Client.CreateSurveyResponse(model.SurveyResponse{OrgID: "org", AppID: "app", UserID: "user", Survey: model.Survey{ID: "survey", Data: data}}, nil, nil, model.AuditContext{})
+	if err != nil {
+		t.Fatalf("Client.CreateSurveyResponse() error = %v", err)
+	}
+	want := []string{"counselor@example.com|Check-in escalation|user scored 4 for wellness"}
+	if !reflect.DeepEqual(notifications.mails, want) {
+		t.Errorf("Client.CreateSurveyResponse() alerts = %v, want %v", notifications.mails, want)
+	}
+
+	_, err = app.Client.CreateSurvey(model.Survey{OrgID: "org", AppID: "app", AlertRules: []model.SurveyAlertRule{{Type: model.AlertRuleAnswer, ContactKey: "unsafe", Key: "missing", Value: true, Body: "body"}}}, nil, false, model.AuditContext{})
+	if err == nil {
+		t.Error("Client.CreateSurvey() alert rule for a missing key error = nil")
+	}
+}
@@ -163,6 +163,20 @@ func (d deleteDataLogic) deleteAppOrgUsersData(appID string, orgID string, accou
 		return
 	}
 
+	// delete survey revisions
+	err = d.storage.DeleteSurveyRevisionsWithIDs(orgID, appID, accountsIDs)
+	if err != nil {
+		d.logger.Errorf("error deleting the survey revisions - %s", err)
+		return
+	}
+
+	// delete survey reminders
+	err = d.storage.DeleteSurveyRemindersWithIDs(orgID, appID, accountsIDs)
+	if err != nil {
+		d.logger.Errorf("error deleting the survey reminders - %s", err)
+		return
+	}
+
 }
 
 func (d deleteDataLogic) getAccountsIDs(memberships []model.DeletedMembership) []string {
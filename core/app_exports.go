@@ -0,0 +1,143 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/interfaces"
+	"application/core/model"
+	"application/utils"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// surveyExportKeys returns the keys of the survey questions in the order they are presented: the questions reached
+// from the default data key through pages and default follow-ups first, then the remaining questions sorted by key
+func surveyExportKeys(survey model.Survey) []string {
+	keys := make([]string, 0, len(survey.Data))
+	visited := make(map[string]bool, len(survey.Data))
+	var visit func(key string)
+	visit = func(key string) {
+		question, ok := survey.Data[key]
+		if !ok || visited[key] {
+			return
+		}
+		visited[key] = true
+		if question.Type == model.SurveyDataTypePage {
+			for _, dataKey := range question.DataKeys {
+				visit(dataKey)
+			}
+		} else if isSurveyQuestion(question) {
+			keys = append(keys, key)
+		}
+		if question.DefaultFollowUpKey != nil {
+			visit(*question.DefaultFollowUpKey)
+		}
+	}
+	if survey.DefaultDataKey != nil {
+		visit(*survey.DefaultDataKey)
+	}
+
+	remaining := make([]string, 0)
+	for key := range survey.Data {
+		if !visited[key] {
+			remaining = append(remaining, key)
+		}
+	}
+	sort.Strings(remaining)
+	for _, key := range remaining {
+		visit(key)
+	}
+	return keys
+}
+
+// exportSurveyResponses writes one row per completed response to the survey with one column per survey question.
+// The user ID column is left out for anonymous surveys.
+func (a appShared) exportSurveyResponses(survey model.Survey, startDate *time.Time, endDate *time.Time, writer interfaces.ExportWriter) error {
+	err := a.checkRawSurveyResponses(survey)
+	if err != nil {
+		return err
+	}
+
+	keys := surveyExportKeys(survey)
+	header := []string{"id"}
+	if !survey.Anonymous {
+		header = append(header, "user_id")
+	}
+	header = append(header, "date_created", "survey_revision")
+	for _, key := range keys {
+		title := survey.Data[key].Text
+		if title == "" {
+			title = key
+		}
+		header = append(header, escapeExportCell(title))
+	}
+	err = writer.WriteHeader(header)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionSend, model.TypeSurveyResponse, nil, err)
+	}
+
+	return a.app.storage.StreamSurveyResponses(survey.OrgID, survey.AppID, survey.ID, startDate, endDate, func(surveyResponse model.SurveyResponse) error {
+		row := []string{surveyResponse.ID}
+		if !survey.Anonymous {
+			row = append(row, surveyResponse.UserID)
+		}
+		row = append(row, surveyResponse.DateCreated.UTC().Format(time.RFC3339), fmt.Sprint(surveyResponse.SurveyRevision))
+		for _, key := range keys {
+			row = append(row, formatExportValue(surveyResponse.Survey.Data[key].Response))
+		}
+		return writer.WriteRow(row)
+	})
+}
+
+// formatExportValue formats an answer for a single export cell. The values of multiple answers are separated by semicolons.
+// Text answers are escaped so spreadsheet applications do not evaluate them as formulas.
+func formatExportValue(value interface{}) string {
+	switch value := utils.NormalizeValue(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeExportCell(value)
+	case time.Time:
+		return value.UTC().Format(time.RFC3339)
+	case []interface{}:
+		values := make([]string, len(value))
+		for i, item := range value {
+			values[i] = formatExportValue(item)
+		}
+		return strings.Join(values, "; ")
+	case map[string]interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// escapeExportCell prefixes text starting with a formula character with a single quote
+func escapeExportCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
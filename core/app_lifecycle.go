@@ -0,0 +1,176 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// surveyStatusTransitions lists the statuses each survey status may move to. Scheduled, published and closed surveys
+// move between each other as their start and end dates are reached or changed.
+var surveyStatusTransitions = map[string][]string{
+	model.SurveyStatusDraft:     {model.SurveyStatusScheduled, model.SurveyStatusPublished, model.SurveyStatusArchived},
+	model.SurveyStatusScheduled: {model.SurveyStatusDraft, model.SurveyStatusPublished, model.SurveyStatusClosed, model.SurveyStatusArchived},
+	model.SurveyStatusPublished: {model.SurveyStatusDraft, model.SurveyStatusScheduled, model.SurveyStatusClosed, model.SurveyStatusArchived},
+	model.SurveyStatusClosed:    {model.SurveyStatusDraft, model.SurveyStatusScheduled, model.SurveyStatusPublished, model.SurveyStatusArchived},
+	model.SurveyStatusArchived:  {model.SurveyStatusDraft},
+}
+
+// surveyStatusInterval is how often scheduled and published surveys are moved to the status matching their dates
+const surveyStatusInterval = time.Minute
+
+func checkSurveyStatusTransition(status string, target string) error {
+	for _, allowed := range surveyStatusTransitions[status] {
+		if allowed == target {
+			return nil
+		}
+	}
+	return &model.SurveyStatusError{Status: status, Target: target}
+}
+
+// isPublishedStatus returns true if surveys with the provided status have been published and follow their start and end dates
+func isPublishedStatus(status string) bool {
+	return status == model.SurveyStatusScheduled || status == model.SurveyStatusPublished || status == model.SurveyStatusClosed
+}
+
+// createdSurveyStatus returns the status of a new survey. Surveys are published on creation unless they are created as drafts.
+func createdSurveyStatus(survey model.Survey, now time.Time) (string, error) {
+	switch survey.Status {
+	case model.SurveyStatusDraft:
+		return model.SurveyStatusDraft, nil
+	case "", model.SurveyStatusPublished:
+		if survey.Archived != nil && *survey.Archived {
+			return model.SurveyStatusArchived, nil
+		}
+		return survey.DateStatus(now), nil
+	default:
+		return "", errors.ErrorData(logutils.StatusInvalid, "survey status", logutils.StringArgs(survey.Status))
+	}
+}
+
+// updatedSurveyStatus returns the status of a survey after an update. The status follows the archived flag and the
+// updated start and end dates of published surveys.
+func updatedSurveyStatus(current model.Survey, survey model.Survey, now time.Time) string {
+	status := current.CurrentStatus()
+	archived := survey.Archived != nil && *survey.Archived
+	if archived && status != model.SurveyStatusArchived {
+		return model.SurveyStatusArchived
+	}
+	if !archived && status == model.SurveyStatusArchived {
+		return model.SurveyStatusDraft
+	}
+	if isPublishedStatus(status) {
+		return survey.DateStatus(now)
+	}
+	return status
+}
+
+func (a appShared) publishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool, audit model.AuditContext) (*model.Survey, error) {
+	return a.setSurveyStatus(id, orgID, appID, userID, externalIDs, admin, audit, func(survey model.Survey) string {
+		return survey.DateStatus(time.Now().UTC())
+	})
+}
+
+func (a appShared) unpublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool, audit model.AuditContext) (*model.Survey, error) {
+	return a.setSurveyStatus(id, orgID, appID, userID, externalIDs, admin, audit, func(survey model.Survey) string {
+		return model.SurveyStatusDraft
+	})
+}
+
+// setSurveyStatus moves the survey to the status returned by target if the transition is allowed. Only admins, the
+// creator and admins of the associated calendar event may change the status of a survey.
+func (a appShared) setSurveyStatus(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool, audit model.AuditContext,
+	target func(survey model.Survey) string) (*model.Survey, error) {
+	//1. find survey
+	survey, err := a.app.storage.GetSurvey(id, orgID, appID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+	}
+	if survey == nil {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": id, "app_id": appID, "org_id": orgID})
+	}
+
+	//2. check that the user may change the survey status
+	if !admin && survey.CreatorID != userID {
+		if survey.CalendarEventID == "" {
+			return nil, errors.ErrorData(logutils.StatusInvalid, "user", &logutils.FieldArgs{"id": id, "creator": false})
+		}
+		eventAdmin, err := a.isEventAdmin(survey.OrgID, survey.AppID, survey.CalendarEventID, userID, externalIDs)
+		if err != nil {
+			return nil, errors.WrapErrorAction("checking", "event admin", nil, err)
+		}
+		if !eventAdmin {
+			return nil, errors.ErrorData(logutils.StatusInvalid, "user", &logutils.FieldArgs{"calendar_event_id": survey.CalendarEventID, "admin": false})
+		}
+	}
+
+	//3. check the transition
+	status := survey.CurrentStatus()
+	targetStatus := target(*survey)
+	err = checkSurveyStatusTransition(status, targetStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	//4. update the status if it has not changed in the meantime
+	err = a.app.storage.UpdateSurveyStatus(id, orgID, appID, status, targetStatus)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, &logutils.FieldArgs{"id": id, "status": targetStatus}, err)
+	}
+
+	before := *survey
+	survey.Status = targetStatus
+	survey.Archived = nil
+	if targetStatus == model.SurveyStatusArchived {
+		archived := true
+		survey.Archived = &archived
+	}
+	a.recordAuditEvent(audit, model.AuditEntitySurvey, survey.ID, survey.OrgID, survey.AppID, before, *survey)
+	return survey, nil
+}
+
+// checkSurveyResponseWindow returns an error if the survey does not accept responses at the provided time
+func checkSurveyResponseWindow(survey model.Survey, now time.Time) error {
+	if status := survey.CurrentStatus(); status == model.SurveyStatusDraft || status == model.SurveyStatusArchived {
+		return errors.ErrorData(logutils.StatusInvalid, model.TypeSurvey, &logutils.FieldArgs{"id": survey.ID, "status": status})
+	}
+	window := survey.ResponseWindow(now)
+	if window != model.ResponseWindowOpen {
+		windowErr := model.SurveyResponseWindowError{Code: window, StartDate: survey.StartDate, EndDate: survey.EndDate}
+		// report the next response window of recurring surveys
+		if _, next := survey.RecurrenceWindows(now); next != nil && window == model.ResponseWindowNotOpen {
+			windowErr.StartDate = &next.Start
+			windowErr.EndDate = next.End
+		}
+		return &windowErr
+	}
+	return nil
+}
+
+// updateSurveyStatuses moves scheduled and published surveys to the status matching their start and end dates
+func (a *Application) updateSurveyStatuses() {
+	count, err := a.storage.UpdateSurveyStatuses(time.Now().UTC())
+	if err != nil {
+		a.logger.Errorf("error updating survey statuses - %s", err)
+		return
+	}
+	if count > 0 {
+		a.logger.Infof("updated the status of %d surveys", count)
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"application/core/rules"
+	"application/utils"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// surveyLinter collects the issues found by the static analysis of a survey definition
+type surveyLinter struct {
+	survey model.Survey
+	issues []model.SurveyLintIssue
+
+	// followUps are the possible next survey data keys for each survey data key
+	followUps map[string][]string
+	// starts are the possible first survey data keys
+	starts []string
+
+	usedSubRules map[string]bool
+}
+
+// lintSurvey runs a static analysis of the survey definition and returns the errors and warnings found
+func lintSurvey(survey model.Survey) model.SurveyLint {
+	linter := surveyLinter{survey: survey, followUps: map[string][]string{}, usedSubRules: map[string]bool{}}
+	linter.lintDataKeys()
+	linter.lintQuestions()
+	linter.lintRules()
+	linter.lintCycles()
+	linter.lintReachability()
+	linter.lintUnused()
+
+	lint := model.SurveyLint{Errors: make([]model.SurveyLintIssue, 0), Warnings: make([]model.SurveyLintIssue, 0)}
+	sort.SliceStable(linter.issues, func(i, j int) bool {
+		if linter.issues[i].Path == linter.issues[j].Path {
+			return linter.issues[i].Code < linter.issues[j].Code
+		}
+		return linter.issues[i].Path < linter.issues[j].Path
+	})
+	for _, issue := range linter.issues {
+		if issue.Severity == model.LintSeverityError {
+			lint.Errors = append(lint.Errors, issue)
+		} else {
+			lint.Warnings = append(lint.Warnings, issue)
+		}
+	}
+	lint.Valid = len(lint.Errors) == 0
+	return lint
+}
+
+// lintDataKeys checks the keys linking the survey data together and builds the follow-up graph
+func (l *surveyLinter) lintDataKeys() {
+	if l.survey.DefaultDataKey != nil && *l.survey.DefaultDataKey != "" {
+		if l.checkDataKey("default_data_key", *l.survey.DefaultDataKey) {
+			l.starts = append(l.starts, *l.survey.DefaultDataKey)
+		}
+	}
+	if l.survey.DefaultDataKeyRule != nil && *l.survey.DefaultDataKeyRule != "" {
+		dependencies, ok := l.lintRule("default_data_key_rule", *l.survey.DefaultDataKeyRule)
+		if ok {
+			for _, key := range ruleResultValues(l.survey, dependencies, map[string]bool{}) {
+				if l.checkDataKey("default_data_key_rule", key) {
+					l.starts = append(l.starts, key)
+				}
+			}
+		}
+	}
+
+	for _, key := range l.dataKeys() {
+		question := l.survey.Data[key]
+		path := "data." + key
+		if question.DefaultFollowUpKey != nil && *question.DefaultFollowUpKey != "" {
+			if l.checkDataKey(path+".default_follow_up_key", *question.DefaultFollowUpKey) {
+				l.followUps[key] = append(l.followUps[key], *question.DefaultFollowUpKey)
+			}
+		}
+		if question.FollowUpRule != nil && *question.FollowUpRule != "" {
+			dependencies, ok := l.lintRule(path+".follow_up_rule", *question.FollowUpRule)
+			if ok {
+				for _, next := range ruleResultValues(l.survey, dependencies, map[string]bool{}) {
+					if l.checkDataKey(path+".follow_up_rule", next) {
+						l.followUps[key] = append(l.followUps[key], next)
+					}
+				}
+			}
+		}
+		for _, dataKey := range question.DataKeys {
+			l.checkDataKey(path+".data_keys", dataKey)
+		}
+	}
+}
+
+// lintQuestions checks the options, scoring and ranges of the survey questions
+func (l *surveyLinter) lintQuestions() {
+	scorable := false
+	for _, key := range l.dataKeys() {
+		question := l.survey.Data[key]
+		path := "data." + key
+
+		if question.Type == model.SurveyDataTypeMultipleChoice && len(question.Options) == 0 {
+			l.addIssue(model.LintSeverityError, model.LintCodeMissingOptions, path+".options", "multiple choice question has no options")
+		}
+		for i, option := range question.Options {
+			for _, previous := range question.Options[:i] {
+				if utils.ValuesEqual(utils.NormalizeValue(previous.Value), utils.NormalizeValue(option.Value)) {
+					l.addIssue(model.LintSeverityError, model.LintCodeDuplicateOption, fmt.Sprintf("%s.options.%d", path, i), fmt.Sprintf("option value %v is used by another option", option.Value))
+					break
+				}
+			}
+		}
+		if len(question.Options) > 0 {
+			correctAnswers := question.CorrectAnswers
+			if question.CorrectAnswer != nil {
+				correctAnswers = append([]interface{}{question.CorrectAnswer}, correctAnswers...)
+			}
+			for _, correct := range correctAnswers {
+				if !hasOptionValue(question.Options, utils.NormalizeValue(correct)) {
+					l.addIssue(model.LintSeverityError, model.LintCodeInvalidCorrectAnswer, path, fmt.Sprintf("correct answer %v is not one of the question options", correct))
+				}
+			}
+		}
+
+		if question.SelfScore != nil && *question.SelfScore && question.Type != model.SurveyDataTypeNumeric {
+			l.addIssue(model.LintSeverityError, model.LintCodeInvalidScore, path+".self_score", "only numeric questions can be self scored")
+		}
+		_, _, isScorable := scoreSurveyData(question, nil)
+		isScorable = isScorable || (question.ScoreRule != nil && *question.ScoreRule != "")
+		if isScorable {
+			scorable = true
+			if !l.survey.Scored {
+				l.addIssue(model.LintSeverityWarning, model.LintCodeInvalidScore, path, "question defines scores but the survey is not scored")
+			}
+		}
+		if hasOptionScores(question.Options) && question.MaximumScore != nil {
+			// compare the maximum score to the best score the options allow
+			optionQuestion := question
+			optionQuestion.MaximumScore = nil
+			_, maximum, _ := scoreSurveyData(optionQuestion, nil)
+			if *question.MaximumScore < maximum-scoreTolerance {
+				l.addIssue(model.LintSeverityWarning, model.LintCodeInvalidScore, path+".maximum_score", fmt.Sprintf("maximum score is lower than the achievable score %v", maximum))
+			}
+		}
+
+		if question.Minimum != nil && question.Maximum != nil && *question.Minimum > *question.Maximum {
+			l.addIssue(model.LintSeverityError, model.LintCodeInvalidRange, path, "minimum is greater than maximum")
+		}
+		if question.MinLength != nil && question.MaxLength != nil && *question.MinLength > *question.MaxLength {
+			l.addIssue(model.LintSeverityError, model.LintCodeInvalidRange, path, "minimum length is greater than maximum length")
+		}
+		if question.StartTime != nil && question.EndTime != nil && question.StartTime.After(*question.EndTime) {
+			l.addIssue(model.LintSeverityError, model.LintCodeInvalidRange, path, "start time is after end time")
+		}
+	}
+
+	if l.survey.Scored && !scorable && len(l.survey.Data) > 0 {
+		l.addIssue(model.LintSeverityWarning, model.LintCodeInvalidScore, "scored", "survey is scored but no question defines scores")
+	}
+}
+
+// lintRules checks the rules that are not part of the follow-up graph
+func (l *surveyLinter) lintRules() {
+	for _, key := range l.dataKeys() {
+		question := l.survey.Data[key]
+		if question.ScoreRule != nil && *question.ScoreRule != "" {
+			l.lintRule("data."+key+".score_rule", *question.ScoreRule)
+		}
+		if question.DefaultResponseRule != nil && *question.DefaultResponseRule != "" {
+			l.lintRule("data."+key+".default_response_rule", *question.DefaultResponseRule)
+		}
+	}
+	if l.survey.ResultRules != "" {
+		l.lintRule("result_rules", l.survey.ResultRules)
+	}
+
+	subRuleKeys := make([]string, 0, len(l.survey.SubRules))
+	for key := range l.survey.SubRules {
+		subRuleKeys = append(subRuleKeys, key)
+	}
+	sort.Strings(subRuleKeys)
+	for _, key := range subRuleKeys {
+		result, err := rules.ParseData(l.survey.SubRules[key])
+		if err != nil {
+			l.addIssue(model.LintSeverityError, model.LintCodeInvalidRule, "sub_rules."+key, fmt.Sprintf("rule cannot be parsed: %s", err.Error()))
+			continue
+		}
+		l.lintDependencies("sub_rules."+key, rules.GetDependencies(result))
+	}
+}
+
+// lintRule parses the provided rule and checks its references. ok is false if the rule cannot be parsed.
+func (l *surveyLinter) lintRule(path string, rule string) (rules.Dependencies, bool) {
+	result, err := rules.Parse(rule)
+	if err != nil {
+		l.addIssue(model.LintSeverityError, model.LintCodeInvalidRule, path, fmt.Sprintf("rule cannot be parsed: %s", err.Error()))
+		return rules.Dependencies{}, false
+	}
+	dependencies := rules.GetDependencies(result)
+	l.lintDependencies(path, dependencies)
+	return dependencies, true
+}
+
+func (l *surveyLinter) lintDependencies(path string, dependencies rules.Dependencies) {
+	for _, ruleKey := range dependencies.RuleKeys {
+		l.usedSubRules[ruleKey] = true
+		if _, ok := l.survey.SubRules[ruleKey]; !ok {
+			l.addIssue(model.LintSeverityError, model.LintCodeDanglingReference, path, fmt.Sprintf("sub rule %s does not exist", ruleKey))
+		}
+	}
+	for _, dataKey := range dependencies.DataKeys() {
+		l.checkDataKey(path, dataKey)
+	}
+	for _, constantKey := range dependencies.ConstantKeys() {
+		if _, ok := l.survey.Constants[constantKey]; !ok {
+			l.addIssue(model.LintSeverityError, model.LintCodeDanglingReference, path, fmt.Sprintf("constant %s does not exist", constantKey))
+		}
+	}
+	stringKeys := surveyStringKeys(l.survey.Strings)
+	for _, stringKey := range dependencies.StringKeys() {
+		if !stringKeys[stringKey] {
+			l.addIssue(model.LintSeverityError, model.LintCodeDanglingReference, path, fmt.Sprintf("string %s does not exist", stringKey))
+		}
+	}
+}
+
+// lintCycles reports the cycles in the follow-up graph
+func (l *surveyLinter) lintCycles() {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var visit func(key string, path []string)
+	visit = func(key string, path []string) {
+		state[key] = visiting
+		path = append(path, key)
+		for _, next := range l.followUps[key] {
+			switch state[next] {
+			case visiting:
+				cycle := path
+				for i, pathKey := range path {
+					if pathKey == next {
+						cycle = path[i:]
+						break
+					}
+				}
+				l.addIssue(model.LintSeverityError, model.LintCodeCycle, "data."+key, fmt.Sprintf("follow-up cycle %s -> %s", strings.Join(cycle, " -> "), next))
+			case unvisited:
+				visit(next, path)
+			}
+		}
+		state[key] = visited
+	}
+	for _, key := range l.dataKeys() {
+		if state[key] == unvisited {
+			visit(key, nil)
+		}
+	}
+}
+
+// lintReachability reports the survey data that cannot be reached from the first survey data
+func (l *surveyLinter) lintReachability() {
+	if len(l.starts) == 0 {
+		return
+	}
+
+	reached := map[string]bool{}
+	queue := append([]string{}, l.starts...)
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if reached[key] {
+			continue
+		}
+		reached[key] = true
+		queue = append(queue, l.followUps[key]...)
+		queue = append(queue, l.survey.Data[key].DataKeys...)
+	}
+
+	for _, key := range l.dataKeys() {
+		if !reached[key] {
+			l.addIssue(model.LintSeverityWarning, model.LintCodeUnreachable, "data."+key, "survey data cannot be reached from the default data key")
+		}
+	}
+}
+
+// lintUnused reports the strings, constants and sub rules that are never used
+func (l *surveyLinter) lintUnused() {
+	// strings and constants may be referenced from any text in the survey, so search the whole definition for them
+	survey := l.survey
+	survey.Strings = nil
+	survey.Constants = nil
+	definition, err := json.Marshal(survey)
+	if err != nil {
+		return
+	}
+	text := string(definition)
+
+	for key := range surveyStringKeys(l.survey.Strings) {
+		if !strings.Contains(text, "strings."+key) {
+			l.addIssue(model.LintSeverityWarning, model.LintCodeUnused, "strings."+key, "string is never used")
+		}
+	}
+	for key := range l.survey.Constants {
+		if !strings.Contains(text, "constants."+key) {
+			l.addIssue(model.LintSeverityWarning, model.LintCodeUnused, "constants."+key, "constant is never used")
+		}
+	}
+	for key := range l.survey.SubRules {
+		if !l.usedSubRules[key] {
+			l.addIssue(model.LintSeverityWarning, model.LintCodeUnused, "sub_rules."+key, "sub rule is never used")
+		}
+	}
+}
+
+// checkDataKey reports a dangling reference if the survey data with the provided key does not exist
+func (l *surveyLinter) checkDataKey(path string, key string) bool {
+	if _, ok := l.survey.Data[key]; ok {
+		return true
+	}
+	l.addIssue(model.LintSeverityError, model.LintCodeDanglingReference, path, fmt.Sprintf("survey data %s does not exist", key))
+	return false
+}
+
+func (l *surveyLinter) addIssue(severity string, code string, path string, message string) {
+	l.issues = append(l.issues, model.SurveyLintIssue{Severity: severity, Code: code, Path: path, Message: message})
+}
+
+func (l *surveyLinter) dataKeys() []string {
+	keys := make([]string, 0, len(l.survey.Data))
+	for key := range l.survey.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// surveyStringKeys returns the keys of the survey strings. Strings may be grouped by language, in which case the keys
+// of every language are returned.
+func surveyStringKeys(surveyStrings map[string]interface{}) map[string]bool {
+	keys := map[string]bool{}
+	for key, value := range surveyStrings {
+		if language, ok := utils.NormalizeValue(value).(map[string]interface{}); ok {
+			for languageKey := range language {
+				keys[languageKey] = true
+			}
+		} else {
+			keys[key] = true
+		}
+	}
+	return keys
+}
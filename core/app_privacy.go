@@ -0,0 +1,188 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/interfaces"
+	"application/core/model"
+	"sort"
+
+	"github.com/rokwire/core-auth-library-go/v3/authutils"
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// defaultMinGroupSize is the minimum group size of apps/orgs without a privacy config
+const defaultMinGroupSize = 5
+
+// minGroupSize returns the minimum group size of the app/org. The privacy config of the app/org is used if there is one,
+// then the config of the app for all orgs and then the config for all apps and orgs.
+func minGroupSize(storage interfaces.Storage, orgID string, appID string) (int, error) {
+	scopes := [][2]string{{appID, orgID}, {appID, authutils.AllOrgs}, {authutils.AllApps, authutils.AllOrgs}}
+	for _, scope := range scopes {
+		config, err := storage.FindConfig(model.ConfigTypePrivacy, scope[0], scope[1])
+		if err != nil {
+			return 0, errors.WrapErrorAction(logutils.ActionFind, model.TypeConfig, &logutils.FieldArgs{"type": model.ConfigTypePrivacy, "app_id": scope[0], "org_id": scope[1]}, err)
+		}
+		if config == nil {
+			continue
+		}
+		data, err := model.GetConfigData[model.PrivacyConfigData](*config)
+		if err != nil {
+			return 0, err
+		}
+		return data.MinGroupSize, nil
+	}
+	return defaultMinGroupSize, nil
+}
+
+// checkRawSurveyResponses returns an error if the raw responses to the survey may not be returned. Responses to
+// anonymous surveys are only available once enough users responded that they cannot be attributed to individuals.
+func (a appShared) checkRawSurveyResponses(survey model.Survey) error {
+	if !survey.Anonymous {
+		return nil
+	}
+	withheld, minSize, err := surveyBelowMinGroupSize(a.app.storage, survey)
+	if err != nil {
+		return err
+	}
+	if withheld {
+		return &model.SurveyPrivacyError{MinGroupSize: minSize}
+	}
+	return nil
+}
+
+// surveyBelowMinGroupSize returns true if the survey has fewer responses than the minimum group size of its app/org
+func surveyBelowMinGroupSize(storage interfaces.Storage, survey model.Survey) (bool, int, error) {
+	minSize, err := minGroupSize(storage, survey.OrgID, survey.AppID)
+	if err != nil {
+		return false, 0, err
+	}
+	if minSize <= 1 {
+		return false, minSize, nil
+	}
+	count, err := storage.CountSurveyResponsesBySurvey(survey.OrgID, survey.AppID, survey.ID)
+	if err != nil {
+		return false, 0, errors.WrapErrorAction(logutils.ActionCount, model.TypeSurveyResponse, &logutils.FieldArgs{"survey_id": survey.ID}, err)
+	}
+	return count < int64(minSize), minSize, nil
+}
+
+// suppressedCounts returns which of the counts are suppressed: the counts below the minimum group size and, if these
+// add up to fewer than the minimum group size, the next smallest counts until they do. Otherwise a single small count
+// could be recomputed by subtracting the other counts from the total.
+func suppressedCounts(counts []int64, minSize int64) []bool {
+	order := make([]int, len(counts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return counts[order[i]] < counts[order[j]]
+	})
+
+	suppressed := make([]bool, len(counts))
+	var total int64
+	for _, i := range order {
+		if counts[i] >= minSize && (total == 0 || total >= minSize) {
+			break
+		}
+		suppressed[i] = true
+		total += counts[i]
+	}
+	return suppressed
+}
+
+// suppressSmallGroups removes the groups of fewer responses than the minimum group size from the survey results.
+// Answer values given by too few users are counted together under model.SurveyResultsSuppressedKey and histogram
+// buckets and timeline intervals with too few responses are left out. If the suppressed groups of a breakdown add up to
+// fewer responses than the minimum group size, the next smallest groups are suppressed as well, so the suppressed
+// groups cannot be recomputed from the totals. The mean and median of a question are left out if any of its values is
+// suppressed, as they could reveal the suppressed values. All breakdowns are suppressed if the survey has too few
+// responses, and questions answered by too few users only keep their type.
+func suppressSmallGroups(results *model.SurveyResults, minSize int) {
+	results.MinGroupSize = minSize
+	if minSize <= 1 {
+		return
+	}
+	if results.Responses < int64(minSize) {
+		results.Questions = map[string]model.SurveyQuestionResults{}
+		results.Timeline = []model.SurveyResponseCount{}
+		results.Suppressed = true
+		return
+	}
+
+	timelineCounts := make([]int64, len(results.Timeline))
+	for i, count := range results.Timeline {
+		timelineCounts[i] = count.Count
+	}
+	timeline := make([]model.SurveyResponseCount, 0, len(results.Timeline))
+	for i, suppressed := range suppressedCounts(timelineCounts, int64(minSize)) {
+		if !suppressed {
+			timeline = append(timeline, results.Timeline[i])
+		}
+	}
+	results.Timeline = timeline
+
+	for key, question := range results.Questions {
+		if question.Responses < int64(minSize) {
+			question = model.SurveyQuestionResults{Type: question.Type, Style: question.Style, Suppressed: true}
+			results.Questions[key] = question
+			continue
+		}
+
+		values := make([]string, 0, len(question.Counts))
+		for value := range question.Counts {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		counts := make([]int64, len(values))
+		for i, value := range values {
+			counts[i] = question.Counts[value]
+		}
+		var suppressedTotal int64
+		for i, suppressed := range suppressedCounts(counts, int64(minSize)) {
+			if suppressed {
+				suppressedTotal += counts[i]
+				delete(question.Counts, values[i])
+				question.Suppressed = true
+			}
+		}
+		// the suppressed values are only counted together if that count does not single out a small group
+		if suppressedTotal >= int64(minSize) {
+			question.Counts[model.SurveyResultsSuppressedKey] = suppressedTotal
+		}
+
+		bucketCounts := make([]int64, len(question.Histogram))
+		for i, bucket := range question.Histogram {
+			bucketCounts[i] = bucket.Count
+		}
+		histogram := make([]model.SurveyHistogramBucket, 0, len(question.Histogram))
+		for i, suppressed := range suppressedCounts(bucketCounts, int64(minSize)) {
+			if suppressed {
+				question.Suppressed = true
+			} else {
+				histogram = append(histogram, question.Histogram[i])
+			}
+		}
+		if question.Histogram != nil {
+			question.Histogram = histogram
+		}
+		if question.Suppressed {
+			question.Mean = nil
+			question.Median = nil
+		}
+		results.Questions[key] = question
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	// surveyRecurrenceInterval is how often the response windows of recurring surveys are opened, closed and reminded
+	surveyRecurrenceInterval = time.Minute
+	// surveyWindowOpenBody is the body of the push notification sent to participants when a response window opens
+	surveyWindowOpenBody = "A new response window is open"
+	// surveyWindowReminderBody is the body of the push notification sent to participants who have not responded in the current window
+	surveyWindowReminderBody = "Don't forget to respond before the response window closes"
+)
+
+// validateSurveyRecurrence checks that the recurrence of a survey definition is complete
+func validateSurveyRecurrence(survey model.Survey) error {
+	if survey.Recurrence == nil {
+		return nil
+	}
+	return survey.Recurrence.Validate(survey.StartDate)
+}
+
+// processRecurringSurveys opens and closes the response windows of recurring surveys and sends their notifications
+func (a *Application) processRecurringSurveys() {
+	surveys, err := a.storage.GetRecurringSurveys()
+	if err != nil {
+		a.logger.Errorf("error getting recurring surveys - %s", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, survey := range surveys {
+		err = a.processRecurringSurvey(survey, now)
+		if err != nil {
+			a.logger.Errorf("error processing recurring survey %s - %s", survey.ID, err)
+		}
+	}
+}
+
+// processRecurringSurvey records the response window open at now and sends the open notification and the reminder of the
+// window. Each notification is sent by the instance that records the change, so concurrent instances do not repeat it.
+func (a *Application) processRecurringSurvey(survey model.Survey, now time.Time) error {
+	current, _ := survey.RecurrenceWindows(now)
+	state := model.SurveyRecurrenceState{}
+	if survey.RecurrenceState != nil {
+		state = *survey.RecurrenceState
+	}
+
+	//1. open or close the window
+	var windowStart *time.Time
+	if current != nil {
+		windowStart = &current.Start
+	}
+	if !sameTime(state.WindowStart, windowStart) {
+		moved, err := a.storage.UpdateSurveyRecurrenceWindow(survey.ID, state.WindowStart, windowStart)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, &logutils.FieldArgs{"id": survey.ID, "window_start": windowStart}, err)
+		}
+		if !moved {
+			return nil
+		}
+
+		if current == nil {
+			a.logger.Infof("closed the response window of survey %s", survey.ID)
+		} else {
+			a.logger.Infof("opened the response window %s of survey %s", current, survey.ID)
+			if survey.Recurrence.NotifyOnOpen {
+				err = a.notifySurveyParticipants(survey, nil, surveyWindowOpenBody)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	//2. remind the participants who have not responded in the window
+	if current == nil || current.End == nil || survey.Recurrence.ReminderBefore == nil || sameTime(state.RemindedStart, &current.Start) {
+		return nil
+	}
+	if now.Before(current.End.Add(-time.Duration(*survey.Recurrence.ReminderBefore) * time.Minute)) {
+		return nil
+	}
+	reminded, err := a.storage.UpdateSurveyRecurrenceReminder(survey.ID, current.Start)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, &logutils.FieldArgs{"id": survey.ID, "reminded_start": current.Start}, err)
+	}
+	if !reminded {
+		return nil
+	}
+	return a.notifySurveyParticipants(survey, &current.Start, surveyWindowReminderBody)
+}
+
+// notifySurveyParticipants sends a push notification to the known users of the survey audience, except the users who
+// have responded since the provided time and the users who have muted the reminders of the survey. The users who have
+// responded before are notified as well if not all the users of the audience can be listed.
+func (a *Application) notifySurveyParticipants(survey model.Survey, exceptSince *time.Time, body string) error {
+	//1. find the audience
+	participants, err := a.surveyAudience(survey)
+	if err != nil {
+		return err
+	}
+	if survey.Audience == nil || !survey.Audience.Listable() {
+		respondents, err := a.storage.GetSurveyRespondents(survey.OrgID, survey.AppID, survey.ID, nil)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionGet, "survey participants", &logutils.FieldArgs{"survey_id": survey.ID}, err)
+		}
+		participants = append(participants, respondents...)
+	}
+	if len(participants) == 0 {
+		return nil
+	}
+
+	//2. skip the users who have responded in the window or muted the reminders
+	skipped := map[string]bool{}
+	if exceptSince != nil {
+		respondents, err := a.storage.GetSurveyRespondents(survey.OrgID, survey.AppID, survey.ID, exceptSince)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionGet, "survey participants", &logutils.FieldArgs{"survey_id": survey.ID, "since": exceptSince}, err)
+		}
+		for _, userID := range respondents {
+			skipped[userID] = true
+		}
+	}
+	mutes, err := a.storage.GetSurveyReminderMutes(survey.OrgID, survey.AppID, participants, &survey.ID)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurveyReminderMute, &logutils.FieldArgs{"survey_id": survey.ID}, err)
+	}
+	for _, mute := range mutes {
+		skipped[mute.UserID] = true
+	}
+
+	recipients := make([]model.NotificationMessageRecipient, 0, len(participants))
+	for _, userID := range participants {
+		if skipped[userID] {
+			continue
+		}
+		skipped[userID] = true
+		recipients = append(recipients, model.NotificationMessageRecipient{UserID: userID})
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	a.notifications.SendNotification(model.NotificationMessage{OrgID: survey.OrgID, AppID: survey.AppID, Subject: survey.Title, Body: body,
+		Data: map[string]string{"type": "survey", "entity_type": "survey", "entity_id": survey.ID, "entity_name": survey.Title}, Recipients: recipients})
+	a.logger.Infof("notified %d participants of survey %s", len(recipients), survey.ID)
+	return nil
+}
+
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
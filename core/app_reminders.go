@@ -0,0 +1,179 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	// surveyRemindersInterval is how often the reminders of surveys approaching their end date are sent
+	surveyRemindersInterval = 5 * time.Minute
+	// surveyReminderBody is the body of the push notification sent to users who have not responded to a survey
+	surveyReminderBody = "Don't forget to complete the survey before it closes"
+)
+
+// validateSurveyReminderOffsets checks that the reminder offsets of a survey definition are positive and that the survey has an end date
+func validateSurveyReminderOffsets(survey model.Survey) error {
+	if len(survey.ReminderOffsets) == 0 {
+		return nil
+	}
+	if survey.EndDate == nil {
+		return errors.ErrorData(logutils.StatusMissing, "survey end date", logutils.StringArgs("reminder_offsets"))
+	}
+	for _, offset := range survey.ReminderOffsets {
+		if offset < 1 {
+			return errors.ErrorData(logutils.StatusInvalid, "survey reminder offset", &logutils.FieldArgs{"offset": offset})
+		}
+	}
+	return nil
+}
+
+// dueReminderOffset returns the reminder offset due at now: the smallest offset whose time has been reached. Larger
+// offsets missed in the meantime (eg. by surveys published shortly before their end date) are not sent.
+func dueReminderOffset(survey model.Survey, now time.Time) (int, bool) {
+	if survey.EndDate == nil || !survey.EndDate.After(now) {
+		return 0, false
+	}
+	due, found := 0, false
+	for _, offset := range survey.ReminderOffsets {
+		if offset < 1 || now.Before(survey.EndDate.Add(-time.Duration(offset)*time.Hour)) {
+			continue
+		}
+		if !found || offset < due {
+			due, found = offset, true
+		}
+	}
+	return due, found
+}
+
+// sendSurveyReminders reminds the audience of the surveys approaching their end date
+func (a *Application) sendSurveyReminders() {
+	now := time.Now().UTC()
+	surveys, err := a.storage.GetSurveysForReminders(now)
+	if err != nil {
+		a.logger.Errorf("error getting surveys for reminders - %s", err)
+		return
+	}
+
+	for _, survey := range surveys {
+		err = a.sendSurveyReminder(survey, now)
+		if err != nil {
+			a.logger.Errorf("error sending the reminders of survey %s - %s", survey.ID, err)
+		}
+	}
+}
+
+// sendSurveyReminder sends the due reminder of a survey to the users of its audience who have not responded and have not
+// muted its reminders. Each reminder is recorded before it is sent, so nobody is reminded twice at the same offset.
+func (a *Application) sendSurveyReminder(survey model.Survey, now time.Time) error {
+	offset, ok := dueReminderOffset(survey, now)
+	if !ok {
+		return nil
+	}
+
+	//1. find the audience
+	audience, err := a.surveyAudience(survey)
+	if err != nil {
+		return err
+	}
+	if len(audience) == 0 {
+		return nil
+	}
+
+	//2. skip the users who have responded or muted the reminders
+	skipped := map[string]bool{}
+	respondents, err := a.storage.GetSurveyRespondents(survey.OrgID, survey.AppID, survey.ID, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionGet, "survey respondents", &logutils.FieldArgs{"survey_id": survey.ID}, err)
+	}
+	for _, userID := range respondents {
+		skipped[userID] = true
+	}
+	mutes, err := a.storage.GetSurveyReminderMutes(survey.OrgID, survey.AppID, audience, &survey.ID)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurveyReminderMute, &logutils.FieldArgs{"survey_id": survey.ID}, err)
+	}
+	for _, mute := range mutes {
+		skipped[mute.UserID] = true
+	}
+
+	//3. record the reminders, users already reminded at this offset are skipped
+	recipients := make([]model.NotificationMessageRecipient, 0)
+	for _, userID := range audience {
+		if skipped[userID] {
+			continue
+		}
+		skipped[userID] = true
+
+		reminder := model.SurveyReminder{ID: uuid.NewString(), OrgID: survey.OrgID, AppID: survey.AppID, SurveyID: survey.ID, UserID: userID,
+			Offset: offset, DateSent: now}
+		err = a.storage.InsertSurveyReminder(reminder)
+		if _, ok := err.(*model.DuplicateKeyError); ok {
+			continue
+		}
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionInsert, model.TypeSurveyReminder, &logutils.FieldArgs{"survey_id": survey.ID, "user_id": userID}, err)
+		}
+		recipients = append(recipients, model.NotificationMessageRecipient{UserID: userID})
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	//4. send the reminders
+	a.notifications.SendNotification(model.NotificationMessage{OrgID: survey.OrgID, AppID: survey.AppID, Subject: survey.Title, Body: surveyReminderBody,
+		Data: map[string]string{"type": "survey", "entity_type": "survey", "entity_id": survey.ID, "entity_name": survey.Title}, Recipients: recipients})
+	a.logger.Infof("reminded %d users of survey %s %d hours before it closes", len(recipients), survey.ID, offset)
+	return nil
+}
+
+// muteSurveyReminders stops the reminders of a survey, or of all surveys if surveyID is empty, for a user. Muting
+// reminders that are already muted returns the existing mute.
+func (a appClient) muteSurveyReminders(orgID string, appID string, userID string, surveyID string) (*model.SurveyReminderMute, error) {
+	if surveyID != "" {
+		survey, err := a.app.storage.GetSurvey(surveyID, orgID, appID)
+		if err != nil {
+			return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+		}
+		if survey == nil {
+			return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": surveyID, "app_id": appID, "org_id": orgID})
+		}
+	}
+
+	mute := model.SurveyReminderMute{ID: uuid.NewString(), OrgID: orgID, AppID: appID, UserID: userID, SurveyID: surveyID, DateCreated: time.Now().UTC()}
+	err := a.app.storage.InsertSurveyReminderMute(mute)
+	if _, ok := err.(*model.DuplicateKeyError); ok {
+		mutes, err := a.app.storage.GetSurveyReminderMutes(orgID, appID, []string{userID}, &surveyID)
+		if err != nil {
+			return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurveyReminderMute, nil, err)
+		}
+		for _, existing := range mutes {
+			if existing.SurveyID == surveyID {
+				return &existing, nil
+			}
+		}
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurveyReminderMute, &logutils.FieldArgs{"survey_id": surveyID})
+	}
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionInsert, model.TypeSurveyReminderMute, nil, err)
+	}
+	return &mute, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// surveyResponseDraftsInterval is how often stale survey response drafts are deleted
+	surveyResponseDraftsInterval = time.Hour
+	// surveyResponseDraftExpiration is how long a survey response draft is kept after it was last saved
+	surveyResponseDraftExpiration = 30 * 24 * time.Hour
+)
+
+// newSurveyResponseDraft returns an in progress response of the user to the survey without any answers
+func newSurveyResponseDraft(survey model.Survey, userID string, now time.Time) *model.SurveyResponse {
+	data := make(map[string]model.SurveyData, len(survey.Data))
+	for key, question := range survey.Data {
+		question.Response = nil
+		data[key] = question
+	}
+	survey.Data = data
+	survey.SurveyStats = nil
+	survey.ResultJSON = ""
+
+	return &model.SurveyResponse{ID: uuid.NewString(), UserID: userID, OrgID: survey.OrgID, AppID: survey.AppID, Survey: survey.RespondentView(),
+		Status: model.SurveyResponseStatusInProgress, SurveyRevision: survey.Revision, DateCreated: now}
+}
+
+// deleteStaleSurveyResponseDrafts deletes the survey response drafts that have not been saved within the expiration period
+func (a *Application) deleteStaleSurveyResponseDrafts() {
+	count, err := a.storage.DeleteStaleSurveyResponseDrafts(time.Now().UTC().Add(-surveyResponseDraftExpiration))
+	if err != nil {
+		a.logger.Errorf("error deleting stale survey response drafts - %s", err)
+		return
+	}
+	if count > 0 {
+		a.logger.Infof("deleted %d stale survey response drafts", count)
+	}
+}
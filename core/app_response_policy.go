@@ -0,0 +1,126 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/interfaces"
+	"application/core/model"
+	"fmt"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// validateSurveyResponsePolicy checks that the response policy of a survey definition is complete
+func validateSurveyResponsePolicy(policy *model.SurveyResponsePolicy) error {
+	if policy == nil {
+		return nil
+	}
+	switch policy.Mode {
+	case "", model.ResponsePolicyUnlimited, model.ResponsePolicySingle, model.ResponsePolicySingleEditable:
+		return nil
+	case model.ResponsePolicyPerPeriod:
+		if policy.Limit < 1 {
+			return errors.ErrorData(logutils.StatusInvalid, "response policy limit", &logutils.FieldArgs{"limit": policy.Limit})
+		}
+		if _, err := responsePeriodStart(policy.Period, time.Now()); err != nil {
+			return err
+		}
+		return nil
+	default:
+		return errors.ErrorData(logutils.StatusInvalid, "response policy mode", logutils.StringArgs(policy.Mode))
+	}
+}
+
+// responsePeriodStart returns the start of the response policy period containing now
+func responsePeriodStart(period string, now time.Time) (time.Time, error) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case model.ResponsePeriodDay:
+		return day, nil
+	case model.ResponsePeriodWeek:
+		// weeks start on Monday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)), nil
+	case model.ResponsePeriodMonth:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	default:
+		return time.Time{}, errors.ErrorData(logutils.StatusInvalid, "response policy period", logutils.StringArgs(period))
+	}
+}
+
+// surveyResponseLimitKeys checks the survey response policy for a new response and returns the limit keys it may be stored
+// with, in the order they should be tried. Responses sharing a limit key conflict on a unique index, so concurrent submissions
+// cannot exceed the limit. Single response policies of recurring surveys allow a single response per response window. Per
+// period policies have one slot per allowed response in the period; slots freed by deleted responses are reused.
+func surveyResponseLimitKeys(storage interfaces.Storage, survey model.Survey, surveyResponse model.SurveyResponse) ([]string, error) {
+	mode := survey.ResponsePolicyMode()
+	var since *time.Time
+	var key string
+	limit := int64(1)
+	switch mode {
+	case model.ResponsePolicySingle, model.ResponsePolicySingleEditable:
+		key = fmt.Sprintf("%s/%s", survey.ID, surveyResponse.UserID)
+		if current := survey.ResponseRecurrenceWindow(surveyResponse.DateCreated); current != nil {
+			since = &current.Start
+			key = fmt.Sprintf("%s/%s", key, current.Start.Format(time.RFC3339))
+		}
+	case model.ResponsePolicyPerPeriod:
+		start, err := responsePeriodStart(survey.ResponsePolicy.Period, surveyResponse.DateCreated)
+		if err != nil {
+			return nil, err
+		}
+		since = &start
+		limit = int64(survey.ResponsePolicy.Limit)
+		key = fmt.Sprintf("%s/%s/%s", survey.ID, surveyResponse.UserID, start.Format(time.DateOnly))
+	default:
+		return nil, nil
+	}
+
+	count, err := storage.CountSurveyResponses(surveyResponse.OrgID, surveyResponse.AppID, surveyResponse.UserID, survey.ID, since)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionCount, model.TypeSurveyResponse, nil, err)
+	}
+	if count >= limit {
+		return nil, &model.SurveyResponseLimitError{Code: model.ResponseLimitReached, Policy: *survey.ResponsePolicy}
+	}
+	if mode != model.ResponsePolicyPerPeriod {
+		return []string{key}, nil
+	}
+
+	// the next slot is usually free, the lower ones are only free after a deletion
+	keys := make([]string, 0, limit)
+	for i := int64(0); i < limit; i++ {
+		keys = append(keys, fmt.Sprintf("%s/%d", key, (count+i)%limit))
+	}
+	return keys, nil
+}
+
+// storeWithLimitKey stores a survey response with the first of the limit keys that is not taken. It returns the
+// DuplicateKeyError of the last attempt if all the limit keys are taken.
+func storeWithLimitKey(limitKeys []string, store func(limitKey string) error) error {
+	if len(limitKeys) == 0 {
+		return store("")
+	}
+	var err error
+	for _, limitKey := range limitKeys {
+		err = store(limitKey)
+		if _, ok := err.(*model.DuplicateKeyError); !ok {
+			return err
+		}
+	}
+	return err
+}
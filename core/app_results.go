@@ -0,0 +1,141 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"application/utils"
+	"fmt"
+	"sort"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// surveyResultsInterval returns the interval of the survey results timeline. Responses are counted per day by default.
+func surveyResultsInterval(interval string) (string, error) {
+	switch interval {
+	case "":
+		return model.ResponsePeriodDay, nil
+	case model.ResponsePeriodDay, model.ResponsePeriodWeek, model.ResponsePeriodMonth:
+		return interval, nil
+	default:
+		return "", errors.ErrorData(logutils.StatusInvalid, "interval", logutils.StringArgs(interval))
+	}
+}
+
+// isCountedQuestion returns true if the answers to the question are counted by value
+func isCountedQuestion(question model.SurveyData) bool {
+	return question.Type == model.SurveyDataTypeMultipleChoice || question.Type == model.SurveyDataTypeTrueFalse || isLikertQuestion(question)
+}
+
+// isMeasuredQuestion returns true if the answers to the question are numbers with a histogram, mean and median
+func isMeasuredQuestion(question model.SurveyData) bool {
+	return question.Type == model.SurveyDataTypeNumeric || isLikertQuestion(question)
+}
+
+func isLikertQuestion(question model.SurveyData) bool {
+	return question.Style != nil && *question.Style == model.SurveyDataStyleLikert
+}
+
+// surveyResultValueKeys returns the keys of the survey questions whose answers are aggregated by value. The values of
+// other answers (eg. free text) are never part of the results.
+func surveyResultValueKeys(survey model.Survey) []string {
+	keys := make([]string, 0)
+	for key, question := range survey.Data {
+		if isCountedQuestion(question) || isMeasuredQuestion(question) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// buildSurveyResults builds the per question results of a survey from the response aggregates
+func buildSurveyResults(survey model.Survey, aggregates model.SurveyResponseAggregates, interval string) model.SurveyResults {
+	results := model.SurveyResults{SurveyID: survey.ID, Responses: aggregates.Responses, Questions: make(map[string]model.SurveyQuestionResults),
+		Timeline: aggregates.Timeline, Interval: interval}
+	for key, question := range survey.Data {
+		if !isSurveyQuestion(question) {
+			continue
+		}
+		questionResults := model.SurveyQuestionResults{Type: question.Type, Style: question.Style, Responses: aggregates.Answers[key]}
+		if isCountedQuestion(question) {
+			questionResults.Counts = make(map[string]int64)
+		}
+		results.Questions[key] = questionResults
+	}
+
+	histograms := make(map[string]map[float64]int64)
+	for _, value := range aggregates.Values {
+		question, ok := survey.Data[value.Key]
+		if !ok {
+			continue
+		}
+		questionResults := results.Questions[value.Key]
+		if isCountedQuestion(question) {
+			questionResults.Counts[fmt.Sprint(value.Value)] += value.Count
+		}
+		if number, ok := utils.ToFloat64(value.Value); ok && isMeasuredQuestion(question) {
+			if histograms[value.Key] == nil {
+				histograms[value.Key] = make(map[float64]int64)
+			}
+			histograms[value.Key][number] += value.Count
+		}
+		results.Questions[value.Key] = questionResults
+	}
+
+	for key, histogram := range histograms {
+		questionResults := results.Questions[key]
+		questionResults.Histogram, questionResults.Mean, questionResults.Median = histogramStats(histogram)
+		results.Questions[key] = questionResults
+	}
+	return results
+}
+
+// histogramStats returns the histogram buckets sorted by value and the mean and median of the counted values
+func histogramStats(histogram map[float64]int64) ([]model.SurveyHistogramBucket, *float64, *float64) {
+	buckets := make([]model.SurveyHistogramBucket, 0, len(histogram))
+	var total int64
+	var sum float64
+	for value, count := range histogram {
+		buckets = append(buckets, model.SurveyHistogramBucket{Value: value, Count: count})
+		total += count
+		sum += value * float64(count)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Value < buckets[j].Value
+	})
+	if total == 0 {
+		return buckets, nil, nil
+	}
+
+	mean := sum / float64(total)
+	// the median is the average of the middle values, which are the same value if the count is odd
+	lower, upper := histogramValueAt(buckets, (total-1)/2), histogramValueAt(buckets, total/2)
+	median := (lower + upper) / 2
+	return buckets, &mean, &median
+}
+
+// histogramValueAt returns the value at the provided position of the sorted values in the histogram
+func histogramValueAt(buckets []model.SurveyHistogramBucket, position int64) float64 {
+	for _, bucket := range buckets {
+		if position < bucket.Count {
+			return bucket.Value
+		}
+		position -= bucket.Count
+	}
+	return buckets[len(buckets)-1].Value
+}
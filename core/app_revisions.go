@@ -0,0 +1,165 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/interfaces"
+	"application/core/model"
+	"application/utils"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// surveyDiffIgnoredFields are the survey fields that change with every revision or are not part of the definition
+var surveyDiffIgnoredFields = map[string]bool{"revision": true, "date_updated": true, "template": true}
+
+func newSurveyRevision(survey model.Survey, userID string, restoredFrom *int) model.SurveyRevision {
+	return model.SurveyRevision{ID: uuid.NewString(), SurveyID: survey.ID, OrgID: survey.OrgID, AppID: survey.AppID, Revision: survey.Revision,
+		Survey: survey, UserID: userID, RestoredFrom: restoredFrom, DateCreated: time.Now().UTC()}
+}
+
+// updateSurveyWithRevision updates the survey, stores the updated definition as a new revision and records the update in
+// the audit log. It must be called within a transaction.
+func updateSurveyWithRevision(storage interfaces.Storage, survey model.Survey, userID string, admin bool, restoredFrom *int, audit model.AuditContext) error {
+	//1. find the current survey
+	current, err := storage.GetSurvey(survey.ID, survey.OrgID, survey.AppID)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+	}
+	if current == nil {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": survey.ID, "app_id": survey.AppID, "org_id": survey.OrgID})
+	}
+
+	//2. keep the definition of surveys created before revisions were tracked as revision 0
+	if current.Revision == 0 {
+		err = storage.InsertSurveyRevision(newSurveyRevision(*current, current.CreatorID, nil))
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionInsert, model.TypeSurveyRevision, nil, err)
+		}
+	}
+
+	//3. update the survey
+	survey.Revision = current.Revision + 1
+	survey.Status = updatedSurveyStatus(*current, survey, time.Now().UTC())
+	err = storage.UpdateSurvey(survey, admin)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, nil, err)
+	}
+
+	//4. store the updated definition as a new revision
+	updated, err := storage.GetSurvey(survey.ID, survey.OrgID, survey.AppID)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+	}
+	err = storage.InsertSurveyRevision(newSurveyRevision(*updated, userID, restoredFrom))
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionInsert, model.TypeSurveyRevision, nil, err)
+	}
+
+	return insertAuditEvent(storage, audit, model.AuditEntitySurvey, updated.ID, updated.OrgID, updated.AppID, *current, *updated)
+}
+
+// restoredSurvey returns the survey with the definition of the provided revision
+func restoredSurvey(current model.Survey, revision model.Survey) model.Survey {
+	revision.ID = current.ID
+	revision.OrgID = current.OrgID
+	revision.AppID = current.AppID
+	revision.CreatorID = current.CreatorID
+	revision.CalendarEventID = current.CalendarEventID
+	revision.DateCreated = current.DateCreated
+	return revision
+}
+
+// diffSurveys returns the differences between two survey definitions, sorted by path
+func diffSurveys(from model.Survey, to model.Survey) ([]model.SurveyChange, error) {
+	fromData, err := surveyDefinitionMap(from)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := surveyDefinitionMap(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]model.SurveyChange, 0)
+	diffValues("", fromData, toData, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+func surveyDefinitionMap(survey model.Survey) (map[string]interface{}, error) {
+	data, err := json.Marshal(survey)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionMarshal, model.TypeSurvey, nil, err)
+	}
+	var definition map[string]interface{}
+	err = json.Unmarshal(data, &definition)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionUnmarshal, model.TypeSurvey, nil, err)
+	}
+	for field := range surveyDiffIgnoredFields {
+		delete(definition, field)
+	}
+	return definition, nil
+}
+
+// diffValues adds the differences between two decoded JSON values to changes. Objects are compared field by field,
+// any other values (including lists) are compared as a whole.
+func diffValues(path string, from interface{}, to interface{}, changes *[]model.SurveyChange) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		for key, fromValue := range fromMap {
+			toValue, ok := toMap[key]
+			if !ok {
+				*changes = append(*changes, model.SurveyChange{Path: joinPath(path, key), Type: model.SurveyChangeRemoved, Old: fromValue})
+				continue
+			}
+			diffValues(joinPath(path, key), fromValue, toValue, changes)
+		}
+		for key, toValue := range toMap {
+			if _, ok := fromMap[key]; !ok {
+				*changes = append(*changes, model.SurveyChange{Path: joinPath(path, key), Type: model.SurveyChangeAdded, New: toValue})
+			}
+		}
+		return
+	}
+
+	if utils.ValuesEqual(from, to) {
+		return
+	}
+	switch {
+	case from == nil:
+		*changes = append(*changes, model.SurveyChange{Path: path, Type: model.SurveyChangeAdded, New: to})
+	case to == nil:
+		*changes = append(*changes, model.SurveyChange{Path: path, Type: model.SurveyChangeRemoved, Old: from})
+	default:
+		*changes = append(*changes, model.SurveyChange{Path: path, Type: model.SurveyChangeModified, Old: from, New: to})
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
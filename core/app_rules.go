@@ -0,0 +1,149 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"application/core/rules"
+	"application/utils"
+	"encoding/json"
+	"sort"
+)
+
+// evaluateSurveyResponse applies the rules of the survey to the provided answers. Default responses are filled in,
+// the path through the survey is determined, the answers are validated along the path, the stats are computed and
+// the result rules are evaluated. Errors in the survey rules do not fail the evaluation and are returned in RuleErrors.
+func evaluateSurveyResponse(survey model.Survey, data map[string]model.SurveyData) model.SurveyEvaluation {
+	evaluation := model.SurveyEvaluation{Data: make(map[string]model.SurveyData, len(data)), RuleErrors: make([]string, 0)}
+	for key, answer := range data {
+		evaluation.Data[key] = answer
+	}
+	engine := rules.NewEngine(survey, evaluation.Data)
+
+	// fill in the default responses of unanswered questions
+	keys := make([]string, 0, len(survey.Data))
+	for key := range survey.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !isEmptyResponse(utils.NormalizeValue(evaluation.Data[key].Response)) {
+			continue
+		}
+		response, err := engine.DefaultResponse(key)
+		if err != nil {
+			evaluation.RuleErrors = append(evaluation.RuleErrors, err.Error())
+			continue
+		}
+		if response == nil {
+			continue
+		}
+		answer, ok := evaluation.Data[key]
+		if !ok {
+			answer = survey.Data[key]
+		}
+		answer.Response = response
+		evaluation.Data[key] = answer
+		engine.SetResponse(key, response)
+	}
+
+	path, err := engine.Path()
+	evaluation.Path = path
+	evaluation.PathComplete = err == nil
+	if err != nil {
+		evaluation.RuleErrors = append(evaluation.RuleErrors, err.Error())
+	}
+	evaluation.ValidationErrors = validateSurveyResponse(survey, evaluation.Data, path, evaluation.PathComplete)
+
+	stats, ruleErrs := computeSurveyStats(survey, evaluation.Data, engine)
+	for _, ruleErr := range ruleErrs {
+		evaluation.RuleErrors = append(evaluation.RuleErrors, ruleErr.Error())
+	}
+	evaluation.SurveyStats = stats
+	engine.SetStats(stats)
+
+	result, actions, err := engine.Results()
+	if err != nil {
+		evaluation.RuleErrors = append(evaluation.RuleErrors, err.Error())
+	}
+	evaluation.Actions = actions
+	if result != nil {
+		evaluation.Result = result
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			evaluation.RuleErrors = append(evaluation.RuleErrors, err.Error())
+		} else {
+			evaluation.ResultJSON = string(resultJSON)
+		}
+	}
+	return evaluation
+}
+
+// linkedSurveyDataKeys returns the keys of the survey data that can be reached through the survey structure: the default
+// data key, follow-up keys, page data keys and keys used by the follow-up rules. Questions that are not linked are not
+// part of any path and may be answered independently.
+func linkedSurveyDataKeys(survey model.Survey) map[string]bool {
+	rulesJSON := make([]string, 0)
+	if survey.DefaultDataKeyRule != nil {
+		rulesJSON = append(rulesJSON, *survey.DefaultDataKeyRule)
+	}
+
+	linked := map[string]bool{}
+	if survey.DefaultDataKey != nil {
+		linked[*survey.DefaultDataKey] = true
+	}
+	for _, question := range survey.Data {
+		if question.DefaultFollowUpKey != nil {
+			linked[*question.DefaultFollowUpKey] = true
+		}
+		for _, dataKey := range question.DataKeys {
+			linked[dataKey] = true
+		}
+		if question.FollowUpRule != nil {
+			rulesJSON = append(rulesJSON, *question.FollowUpRule)
+		}
+	}
+
+	for _, ruleJSON := range rulesJSON {
+		rule, err := rules.Parse(ruleJSON)
+		if err != nil {
+			// reported as a rule error by the evaluation
+			continue
+		}
+		for _, key := range ruleResultValues(survey, rules.GetDependencies(rule), map[string]bool{}) {
+			if _, ok := survey.Data[key]; ok {
+				linked[key] = true
+			}
+		}
+	}
+	return linked
+}
+
+// ruleResultValues returns the string literals a rule can evaluate to, including the ones of the sub rules it references
+func ruleResultValues(survey model.Survey, dependencies rules.Dependencies, visited map[string]bool) []string {
+	values := append([]string{}, dependencies.Values...)
+	for _, ruleKey := range dependencies.RuleKeys {
+		if visited[ruleKey] {
+			continue
+		}
+		visited[ruleKey] = true
+		subRule, err := rules.ParseData(survey.SubRules[ruleKey])
+		if err != nil {
+			continue
+		}
+		values = append(values, ruleResultValues(survey, rules.GetDependencies(subRule), visited)...)
+	}
+	return values
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/logs"
+)
+
+// scheduledJob is a task that runs periodically in the background
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      func()
+}
+
+// schedulerLogic runs the scheduled jobs. The first run of each job happens one interval after start.
+type schedulerLogic struct {
+	logger logs.Logger
+
+	jobs []scheduledJob
+}
+
+func (s *schedulerLogic) add(name string, interval time.Duration, run func()) {
+	s.jobs = append(s.jobs, scheduledJob{name: name, interval: interval, run: run})
+}
+
+func (s schedulerLogic) start() {
+	for _, job := range s.jobs {
+		go s.runJob(job)
+	}
+}
+
+func (s schedulerLogic) runJob(job scheduledJob) {
+	s.logger.Infof("scheduled job %s -> every %s", job.name, job.interval)
+
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.runOnce(job)
+	}
+}
+
+// runOnce runs the job and recovers from panics so one failing run does not stop the job
+func (s schedulerLogic) runOnce(job scheduledJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Errorf("scheduled job %s panicked - %v", job.name, r)
+		}
+	}()
+	job.run()
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"application/core/rules"
+	"application/utils"
+	"math"
+	"sort"
+)
+
+// scoreTolerance is the maximum difference between two scores that are considered equal
+const scoreTolerance = 1e-6
+
+// computeSurveyStats computes the stats of a survey response from the survey definition and the provided answers.
+// Score rules are evaluated by the provided engine, and the computed question scores are set on it.
+// Questions whose score rule fails are scored as if they had no score rule and the errors are returned.
+func computeSurveyStats(survey model.Survey, data map[string]model.SurveyData, engine *rules.Engine) (model.SurveyStats, []error) {
+	stats := model.SurveyStats{Scores: map[string]float64{}, MaximumScores: map[string]float64{}, ResponseData: map[string]interface{}{}}
+	ruleErrs := make([]error, 0)
+
+	// score rules may reference the scores of other questions, so evaluate them in a stable order
+	keys := make([]string, 0, len(survey.Data))
+	for key := range survey.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		question := survey.Data[key]
+		if !isSurveyQuestion(question) {
+			continue
+		}
+
+		stats.Total++
+		response := utils.NormalizeValue(data[key].Response)
+		answered := !isEmptyResponse(response)
+		if answered {
+			stats.Complete++
+		}
+
+		if !survey.Scored {
+			continue
+		}
+		score, maximum, scorable := scoreSurveyData(question, response)
+		ruleScore, hasRule, err := engine.Score(key)
+		if err != nil {
+			ruleErrs = append(ruleErrs, err)
+		} else if hasRule {
+			score, scorable = ruleScore, true
+			if question.MaximumScore != nil {
+				maximum = *question.MaximumScore
+			}
+		}
+		if !scorable {
+			continue
+		}
+		if answered {
+			stats.Scored++
+			engine.SetScore(key, score)
+		}
+		for _, section := range surveyDataSections(question) {
+			stats.MaximumScores[section] += maximum
+			if answered {
+				stats.Scores[section] += score
+			}
+		}
+	}
+
+	for _, key := range survey.ResponseKeys {
+		if answer, ok := data[key]; ok && answer.Response != nil {
+			stats.ResponseData[key] = answer.Response
+		}
+	}
+	return stats, ruleErrs
+}
+
+// scoreSurveyData returns the score for the response to a question and the maximum score that could have been achieved.
+// scorable is false if the question does not define any scoring.
+func scoreSurveyData(question model.SurveyData, response interface{}) (score float64, maximum float64, scorable bool) {
+	switch {
+	case question.SelfScore != nil && *question.SelfScore:
+		// the numeric response is the score itself
+		score, _ = utils.ToFloat64(response)
+		if question.MaximumScore != nil {
+			maximum = *question.MaximumScore
+		} else if question.Maximum != nil {
+			maximum = *question.Maximum
+		}
+		return score, maximum, true
+	case question.CorrectAnswer != nil || len(question.CorrectAnswers) > 0:
+		maximum = 1
+		if question.MaximumScore != nil {
+			maximum = *question.MaximumScore
+		}
+		if isCorrectResponse(question, response) {
+			score = maximum
+		}
+		return score, maximum, true
+	case hasOptionScores(question.Options):
+		values := responseValues(response)
+		best := math.Inf(-1)
+		for _, option := range question.Options {
+			if option.Score == nil {
+				continue
+			}
+			if *option.Score > 0 {
+				maximum += *option.Score
+			}
+			best = math.Max(best, *option.Score)
+			for _, value := range values {
+				if utils.ValuesEqual(utils.NormalizeValue(option.Value), value) {
+					score += *option.Score
+					break
+				}
+			}
+		}
+		if question.AllowMultiple == nil || !*question.AllowMultiple {
+			maximum = best
+		}
+		if question.MaximumScore != nil {
+			maximum = *question.MaximumScore
+		}
+		return score, maximum, true
+	}
+	return 0, 0, false
+}
+
+// isCorrectResponse checks the response against the correct answer(s) of the question
+func isCorrectResponse(question model.SurveyData, response interface{}) bool {
+	if len(question.CorrectAnswers) == 0 {
+		return utils.ValuesEqual(utils.NormalizeValue(question.CorrectAnswer), response)
+	}
+
+	values := responseValues(response)
+	if len(values) != len(question.CorrectAnswers) {
+		return false
+	}
+	for _, correct := range question.CorrectAnswers {
+		found := false
+		for _, value := range values {
+			if utils.ValuesEqual(utils.NormalizeValue(correct), value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// surveyDataSections returns the sections the score of a question counts towards. Questions without a section count towards the "" section.
+func surveyDataSections(question model.SurveyData) []string {
+	sections := make([]string, 0)
+	if question.Section != nil {
+		sections = append(sections, *question.Section)
+	}
+	for _, section := range question.Sections {
+		duplicate := false
+		for _, existing := range sections {
+			if existing == section {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			sections = append(sections, section)
+		}
+	}
+	if len(sections) == 0 {
+		sections = append(sections, "")
+	}
+	return sections
+}
+
+// surveyStatsMatch checks if the scoring of two sets of stats is the same
+func surveyStatsMatch(a model.SurveyStats, b model.SurveyStats) bool {
+	return a.Scored == b.Scored && scoresMatch(a.Scores, b.Scores) && scoresMatch(a.MaximumScores, b.MaximumScores)
+}
+
+func scoresMatch(a map[string]float64, b map[string]float64) bool {
+	for key, value := range a {
+		if math.Abs(value-b[key]) > scoreTolerance {
+			return false
+		}
+	}
+	for key, value := range b {
+		if _, ok := a[key]; !ok && math.Abs(value) > scoreTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+func hasOptionScores(options []model.OptionData) bool {
+	for _, option := range options {
+		if option.Score != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func responseValues(response interface{}) []interface{} {
+	if response == nil {
+		return nil
+	}
+	if values, ok := response.([]interface{}); ok {
+		return values
+	}
+	return []interface{}{response}
+}
@@ -35,20 +35,59 @@ func (a appShared) getSurvey(id string, orgID string, appID string) (*model.Surv
 	return a.app.storage.GetSurvey(id, orgID, appID)
 }
 
-func (a appShared) getSurveys(orgID string, appID string, userID *string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, []model.SurveyResponse, error) {
-	surveys, surveysResponse, err := a.app.storage.GetSurveysAndSurveyResponses(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID,
-		public, archived, limit, offset, userID, filter)
-	if err != nil {
-		return nil, nil, err
+func (a appShared) getSurveys(orgID string, appID string, userID *string, audience *model.SurveyAudienceUser, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool, admin bool) ([]model.Survey, []model.SurveyResponse, error) {
+	if audience != nil {
+		err := a.resolveSurveyAudience(orgID, appID, surveyIDs, calendarEventID, audience)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return surveys, surveysResponse, nil
+	return a.app.storage.GetSurveysAndSurveyResponses(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID,
+		public, archived, limit, offset, userID, audience, filter, admin)
 }
 
-func (a appShared) createSurvey(survey model.Survey, externalIDs map[string]string) (*model.Survey, error) {
+func (a appShared) createSurvey(survey model.Survey, externalIDs map[string]string, strict bool, audit model.AuditContext) (*model.Survey, error) {
+	if strict {
+		lint := lintSurvey(survey)
+		if !lint.Valid {
+			return nil, &model.SurveyLintError{Errors: lint.Errors}
+		}
+	}
+
+	err := validateSurveyResponsePolicy(survey.ResponsePolicy)
+	if err != nil {
+		return nil, err
+	}
+	err = validateSurveyRecurrence(survey)
+	if err != nil {
+		return nil, err
+	}
+	err = validateSurveyReminderOffsets(survey)
+	if err != nil {
+		return nil, err
+	}
+	err = validateSurveyAudience(survey)
+	if err != nil {
+		return nil, err
+	}
+	err = validateSurveyAlertRules(survey)
+	if err != nil {
+		return nil, err
+	}
+	status, err := createdSurveyStatus(survey, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
 	survey.ID = uuid.NewString()
 	survey.DateCreated = time.Now().UTC()
 	survey.DateUpdated = nil
+	survey.Status = status
+	survey.DeletedAt = nil
+	survey.DeletedCalendarEventID = ""
+	survey.Template = nil
+	survey.RecurrenceState = nil
 
 	if survey.CalendarEventID != "" {
 		// check if user is admin of calendar event
@@ -61,50 +100,116 @@ func (a appShared) createSurvey(survey model.Survey, externalIDs map[string]stri
 		}
 	}
 
-	return a.app.storage.CreateSurvey(survey)
+	survey.Revision = 1
+	var created *model.Survey
+	transaction := func(storage interfaces.Storage) error {
+		var err error
+		created, err = storage.CreateSurvey(survey)
+		if err != nil {
+			// another survey exists for the calendar event
+			if _, ok := err.(*model.DuplicateKeyError); ok {
+				return err
+			}
+			return errors.WrapErrorAction(logutils.ActionCreate, model.TypeSurvey, nil, err)
+		}
+
+		err = storage.InsertSurveyRevision(newSurveyRevision(*created, created.CreatorID, nil))
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionInsert, model.TypeSurveyRevision, nil, err)
+		}
+
+		return insertAuditEvent(storage, audit, model.AuditEntitySurvey, created.ID, created.OrgID, created.AppID, nil, *created)
+	}
+
+	err = a.app.storage.PerformTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
-func (a appShared) updateSurvey(survey model.Survey, userID string, externalIDs map[string]string, admin bool) error {
+func (a appShared) updateSurvey(survey model.Survey, userID string, externalIDs map[string]string, admin bool, strict bool, audit model.AuditContext) error {
+	if strict {
+		lint := lintSurvey(survey)
+		if !lint.Valid {
+			return &model.SurveyLintError{Errors: lint.Errors}
+		}
+	}
+
+	err := validateSurveyResponsePolicy(survey.ResponsePolicy)
+	if err != nil {
+		return err
+	}
+	err = validateSurveyRecurrence(survey)
+	if err != nil {
+		return err
+	}
+	err = validateSurveyReminderOffsets(survey)
+	if err != nil {
+		return err
+	}
+	err = validateSurveyAudience(survey)
+	if err != nil {
+		return err
+	}
+	err = validateSurveyAlertRules(survey)
+	if err != nil {
+		return err
+	}
+
 	// if user is not already an admin and survey has associated event, check if user is event admin
 	if !admin && survey.CalendarEventID != "" {
-		var err error
 		admin, err = a.isEventAdmin(survey.OrgID, survey.AppID, survey.CalendarEventID, userID, externalIDs)
 		if err != nil {
 			return errors.WrapErrorAction("checking", "event admin", nil, err)
 		}
 	}
 
-	return a.app.storage.UpdateSurvey(survey, admin)
+	transaction := func(storage interfaces.Storage) error {
+		return updateSurveyWithRevision(storage, survey, userID, admin, nil, audit)
+	}
+	return a.app.storage.PerformTransaction(transaction)
 }
 
-func (a appShared) deleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool) error {
-	transaction := func(storage interfaces.Storage) error {
-		//1. find survey
-		survey, err := storage.GetSurvey(id, orgID, appID)
+func (a appShared) deleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool, responsesPolicy string, audit model.AuditContext) error {
+	policy, err := a.app.deletedSurveyResponses(responsesPolicy)
+	if err != nil {
+		return err
+	}
+
+	//1. find survey
+	survey, err := a.app.storage.GetSurvey(id, orgID, appID)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+	}
+	if survey == nil {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": id, "app_id": appID, "org_id": orgID})
+	}
+
+	//2. if user is not already an admin and survey has associated event, check if user is event admin
+	if !admin && survey.CalendarEventID != "" {
+		admin, err = a.isEventAdmin(survey.OrgID, survey.AppID, survey.CalendarEventID, userID, externalIDs)
 		if err != nil {
-			return errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
-		}
-		if survey == nil {
-			return errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": id, "app_id": appID, "org_id": orgID})
+			return errors.WrapErrorAction("checking", "event admin", nil, err)
 		}
+	}
 
-		//2. if user is not already an admin and survey has associated event, check if user is event admin
-		if !admin && survey.CalendarEventID != "" {
-			admin, err = a.isEventAdmin(survey.OrgID, survey.AppID, survey.CalendarEventID, userID, externalIDs)
-			if err != nil {
-				return errors.WrapErrorAction("checking", "event admin", nil, err)
-			}
+	deletedAt := time.Now().UTC()
+	transaction := func(storage interfaces.Storage) error {
+		//3. move survey to the trash, its revisions are kept and the responses policy is applied when it is purged
+		err := storage.DeleteSurvey(survey.ID, survey.OrgID, survey.AppID, userID, admin, policy)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurvey, nil, err)
 		}
 
-		//3. delete survey
-		err = storage.DeleteSurvey(survey.ID, survey.OrgID, survey.AppID, userID, admin)
+		//4. mark the responses to the survey as deleted with it
+		_, err = storage.SetSurveyResponsesSurveyDeleted(survey.ID, survey.OrgID, survey.AppID, &deletedAt)
 		if err != nil {
-			return errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurvey, nil, err)
+			return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurveyResponse, &logutils.FieldArgs{"survey_id": survey.ID}, err)
 		}
 
-		return nil
+		return insertAuditEvent(storage, audit, model.AuditEntitySurvey, survey.ID, survey.OrgID, survey.AppID, *survey, nil)
 	}
-
 	return a.app.storage.PerformTransaction(transaction)
 }
 
@@ -130,6 +235,19 @@ func (a appShared) isEventAdmin(orgID string, appID string, eventID string, user
 	return false, nil
 }
 
+// getEventEndDate reads the end of the calendar event from the Calendar BB. It returns nil if the event does not exist or has no end.
+func (a appShared) getEventEndDate(orgID string, appID string, eventID string) (*time.Time, error) {
+	event, err := a.app.calendar.GetEvent(orgID, appID, eventID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, calendar.TypeCalendarEvent, &logutils.FieldArgs{"calendar_event_id": eventID}, err)
+	}
+	if event == nil || event.EndDate == nil {
+		return nil, nil
+	}
+	endDate := event.EndDate.UTC()
+	return &endDate, nil
+}
+
 func (a appShared) hasAttendedEvent(orgID string, appID string, eventID string, userID string, externalIDs map[string]string) (bool, error) {
 	// Get external ID
 	envConfig, err := a.app.GetEnvConfigs()
@@ -153,6 +271,28 @@ func (a appShared) hasAttendedEvent(orgID string, appID string, eventID string,
 	return false, nil
 }
 
+func (a appShared) isRegisteredForEvent(orgID string, appID string, eventID string, userID string, externalIDs map[string]string) (bool, error) {
+	// Get external ID
+	envConfig, err := a.app.GetEnvConfigs()
+	if err != nil {
+		return false, errors.WrapErrorAction(logutils.ActionGet, model.TypeConfig, logutils.StringArgs(model.ConfigTypeEnv), err)
+	}
+	externalID := externalIDs[envConfig.ExternalID]
+
+	registered := true
+	eventUsers, err := a.app.calendar.GetEventUsers(orgID, appID, eventID, []calendar.User{{AccountID: userID, ExternalID: externalID}}, &registered, "", nil)
+	if err != nil {
+		return false, errors.WrapErrorAction(logutils.ActionGet, calendar.TypeCalendarUser, &logutils.FieldArgs{"calendar_event_id": eventID, "user_id": userID, "external_id": externalID}, err)
+	}
+	for _, eventUser := range eventUsers {
+		if ((externalID != "" && eventUser.User.ExternalID == externalID) || eventUser.User.AccountID == userID) && eventUser.Registered {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (a appShared) getUserData(orgID string, appID string, userID *string) (*model.UserData, error) {
 	var (
 		surveys          []model.Survey
@@ -26,3 +26,7 @@ func mockPerformTransaction(storage *mocks.Storage) {
 		return arg(storage)
 	})
 }
+
+func mockInsertAuditEvent(storage *mocks.Storage) {
+	storage.On("InsertAuditEvent", mock.AnythingOfType("model.AuditEvent")).Return(nil).Maybe()
+}
@@ -0,0 +1,225 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"application/driven/calendar"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	// eventSurveysInterval is how often the subscriptions of ended calendar events are processed
+	eventSurveysInterval = time.Minute
+	// eventSurveyClaimTimeout is how long a subscription may stay processing before it is processed again
+	eventSurveyClaimTimeout = 10 * time.Minute
+	// eventSurveyNotificationBody is the body of the push notification sent to event attendees about a follow-up survey
+	eventSurveyNotificationBody = "Tell us about the event you attended"
+)
+
+// subscribeEventSurvey creates or replaces the subscription of a calendar event. Only event admins may subscribe, with a
+// template from the catalog or one of their own templates. The end of the event is read from the Calendar BB.
+func (a appClient) subscribeEventSurvey(subscription model.EventSurveySubscription, externalIDs map[string]string) (*model.EventSurveySubscription, error) {
+	//1. check the subscription
+	if subscription.CalendarEventID == "" {
+		return nil, errors.ErrorData(logutils.StatusMissing, "calendar event id", nil)
+	}
+	if subscription.Duration != nil && *subscription.Duration < 1 {
+		return nil, errors.ErrorData(logutils.StatusInvalid, "duration", &logutils.FieldArgs{"duration": *subscription.Duration})
+	}
+
+	//2. check the template
+	template, err := a.app.storage.GetSurvey(subscription.TemplateID, subscription.OrgID, subscription.AppID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+	}
+	if template == nil || template.Template == nil {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurveyTemplate, &logutils.FieldArgs{"id": subscription.TemplateID})
+	}
+	if !template.Template.InCatalog && template.CreatorID != subscription.CreatorID {
+		return nil, errors.ErrorData(logutils.StatusInvalid, "user", &logutils.FieldArgs{"id": subscription.TemplateID, "creator": false, "in_catalog": false})
+	}
+
+	//3. check that the user is an event admin
+	admin, err := a.app.shared.isEventAdmin(subscription.OrgID, subscription.AppID, subscription.CalendarEventID, subscription.CreatorID, externalIDs)
+	if err != nil {
+		return nil, errors.WrapErrorAction("checking", "event admin", nil, err)
+	}
+	if !admin {
+		return nil, errors.Newf("account not an admin of calendar event")
+	}
+
+	//4. read the end of the event
+	eventEndDate, err := a.app.shared.getEventEndDate(subscription.OrgID, subscription.AppID, subscription.CalendarEventID)
+	if err != nil {
+		return nil, err
+	}
+	if eventEndDate == nil {
+		return nil, errors.ErrorData(logutils.StatusMissing, "event end date", &logutils.FieldArgs{"calendar_event_id": subscription.CalendarEventID})
+	}
+	subscription.EventEndDate = *eventEndDate
+
+	//5. save the subscription, the external IDs are kept to check the event admin again when the survey is created
+	current, err := a.app.storage.GetEventSurveySubscription(subscription.CalendarEventID, subscription.OrgID, subscription.AppID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeEventSurveySubscription, nil, err)
+	}
+
+	now := time.Now().UTC()
+	subscription.ExternalIDs = externalIDs
+	subscription.Status = model.EventSurveySubscriptionPending
+	subscription.SurveyID = nil
+	subscription.Notified = 0
+	subscription.Error = nil
+	if current == nil {
+		subscription.ID = uuid.NewString()
+		subscription.DateCreated = now
+		subscription.DateUpdated = nil
+		err = a.app.storage.InsertEventSurveySubscription(subscription)
+	} else {
+		if current.Status != model.EventSurveySubscriptionPending && current.Status != model.EventSurveySubscriptionFailed {
+			return nil, errors.ErrorData(logutils.StatusInvalid, model.TypeEventSurveySubscription, &logutils.FieldArgs{"calendar_event_id": current.CalendarEventID, "status": current.Status})
+		}
+		subscription.ID = current.ID
+		subscription.DateCreated = current.DateCreated
+		subscription.DateUpdated = &now
+		err = a.app.storage.UpdateEventSurveySubscription(subscription)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// checkEventSurveySubscriber returns an error if the user neither created the subscription nor is an admin of its event
+func (a appClient) checkEventSurveySubscriber(subscription model.EventSurveySubscription, userID string, externalIDs map[string]string) error {
+	if subscription.CreatorID == userID {
+		return nil
+	}
+	admin, err := a.app.shared.isEventAdmin(subscription.OrgID, subscription.AppID, subscription.CalendarEventID, userID, externalIDs)
+	if err != nil {
+		return errors.WrapErrorAction("checking", "event admin", nil, err)
+	}
+	if !admin {
+		return errors.Newf("account not an admin of calendar event")
+	}
+	return nil
+}
+
+// createEventSurveys creates the follow-up surveys of the calendar events which have ended
+func (a *Application) createEventSurveys() {
+	for {
+		now := time.Now().UTC()
+		subscription, err := a.storage.ClaimEventSurveySubscription(now, now.Add(-eventSurveyClaimTimeout))
+		if err != nil {
+			a.logger.Errorf("error claiming event survey subscription - %s", err)
+			return
+		}
+		if subscription == nil {
+			return
+		}
+		a.createEventSurvey(*subscription, now)
+	}
+}
+
+// createEventSurvey clones the template of the subscription into the follow-up survey of its event and notifies the attendees.
+// The subscription is moved back to pending if the event has been rescheduled to end later.
+func (a *Application) createEventSurvey(subscription model.EventSurveySubscription, now time.Time) {
+	eventEndDate, err := a.shared.getEventEndDate(subscription.OrgID, subscription.AppID, subscription.CalendarEventID)
+	if err != nil {
+		// the subscription is claimed again once the claim times out
+		a.logger.Errorf("error getting end date of calendar event %s - %s", subscription.CalendarEventID, err)
+		return
+	}
+	if eventEndDate == nil {
+		a.failEventSurvey(subscription, errors.ErrorData(logutils.StatusMissing, "event end date", &logutils.FieldArgs{"calendar_event_id": subscription.CalendarEventID}))
+		return
+	}
+	if eventEndDate.After(now) {
+		err = a.storage.RescheduleEventSurveySubscription(subscription.ID, *eventEndDate)
+		if err != nil {
+			a.logger.Errorf("error rescheduling event survey subscription %s - %s", subscription.ID, err)
+		}
+		return
+	}
+
+	overrides := model.SurveyCloneOverrides{Title: subscription.Title, CalendarEventID: &subscription.CalendarEventID, StartDate: &now}
+	if subscription.Duration != nil {
+		endDate := now.Add(time.Duration(*subscription.Duration) * time.Hour)
+		overrides.EndDate = &endDate
+	}
+	audit := model.AuditContext{Actor: model.AuditActor{AccountID: subscription.CreatorID, AppID: subscription.AppID, OrgID: subscription.OrgID, System: true}}
+
+	survey, err := a.shared.cloneSurvey(subscription.TemplateID, subscription.OrgID, subscription.AppID, subscription.CreatorID, subscription.ExternalIDs, false, overrides, audit)
+	if err != nil {
+		a.failEventSurvey(subscription, err)
+		return
+	}
+
+	var message *string
+	notified, err := a.notifyEventAttendees(subscription, *survey)
+	if err != nil {
+		a.logger.Errorf("error notifying attendees of calendar event %s - %s", subscription.CalendarEventID, err)
+		errorMessage := err.Error()
+		message = &errorMessage
+	}
+	err = a.storage.UpdateEventSurveySubscriptionResult(subscription.ID, model.EventSurveySubscriptionCreated, &survey.ID, notified, message)
+	if err != nil {
+		a.logger.Errorf("error updating event survey subscription %s - %s", subscription.ID, err)
+		return
+	}
+	a.logger.Infof("created survey %s for calendar event %s and notified %d attendees", survey.ID, subscription.CalendarEventID, notified)
+}
+
+// failEventSurvey records that the follow-up survey of the subscription could not be created
+func (a *Application) failEventSurvey(subscription model.EventSurveySubscription, failure error) {
+	a.logger.Errorf("error creating survey for calendar event %s - %s", subscription.CalendarEventID, failure)
+	message := failure.Error()
+	err := a.storage.UpdateEventSurveySubscriptionResult(subscription.ID, model.EventSurveySubscriptionFailed, nil, 0, &message)
+	if err != nil {
+		a.logger.Errorf("error updating event survey subscription %s - %s", subscription.ID, err)
+	}
+}
+
+// notifyEventAttendees sends a push notification about the follow-up survey to the attendees of its event
+func (a *Application) notifyEventAttendees(subscription model.EventSurveySubscription, survey model.Survey) (int, error) {
+	attended := true
+	eventUsers, err := a.calendar.GetEventUsers(subscription.OrgID, subscription.AppID, subscription.CalendarEventID, nil, nil, "", &attended)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionGet, calendar.TypeCalendarUser, &logutils.FieldArgs{"calendar_event_id": subscription.CalendarEventID}, err)
+	}
+
+	recipients := make([]model.NotificationMessageRecipient, 0, len(eventUsers))
+	added := map[string]bool{}
+	for _, eventUser := range eventUsers {
+		accountID := eventUser.User.AccountID
+		if !eventUser.Attended || accountID == "" || added[accountID] {
+			continue
+		}
+		added[accountID] = true
+		recipients = append(recipients, model.NotificationMessageRecipient{UserID: accountID})
+	}
+	if len(recipients) == 0 {
+		return 0, nil
+	}
+
+	a.notifications.SendNotification(model.NotificationMessage{OrgID: survey.OrgID, AppID: survey.AppID, Subject: survey.Title, Body: eventSurveyNotificationBody,
+		Data: map[string]string{"type": "survey", "entity_type": "survey", "entity_id": survey.ID, "entity_name": survey.Title}, Recipients: recipients})
+	return len(recipients), nil
+}
@@ -14,11 +14,86 @@
 
 package core
 
+import (
+	"application/core/interfaces"
+	"application/core/model"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	// dataKeyReencryptionInterval is how often the survey responses are checked for re-encryption with rotated data keys
+	dataKeyReencryptionInterval = time.Minute
+	// dataKeyReencryptionBatchSize is the number of survey responses re-encrypted at once
+	dataKeyReencryptionBatchSize = 100
+)
+
 // appSystem contains system implementations
 type appSystem struct {
 	app *Application
 }
 
+// GetDataKeys returns the data keys of an org
+func (a appSystem) GetDataKeys(orgID string) ([]model.DataKey, error) {
+	return a.app.storage.GetDataKeys(orgID)
+}
+
+// RotateDataKey creates a new data key for an org. The responses of the org to sensitive surveys are re-encrypted with it
+// in the background by reencryptSurveyResponses. Previous data keys are kept, so responses that have not been
+// re-encrypted yet can still be read.
+func (a appSystem) RotateDataKey(orgID string) (*model.DataKeyRotation, error) {
+	var key *model.DataKey
+	transaction := func(storage interfaces.Storage) error {
+		var err error
+		key, err = storage.RotateDataKey(orgID)
+		return err
+	}
+	err := a.app.storage.PerformTransaction(transaction)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionCreate, model.TypeDataKey, &logutils.FieldArgs{"org_id": orgID}, err)
+	}
+
+	pending, err := a.app.storage.CountSurveyResponsesToReencrypt(orgID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionCount, model.TypeSurveyResponse, &logutils.FieldArgs{"org_id": orgID}, err)
+	}
+	return &model.DataKeyRotation{Key: *key, Pending: pending}, nil
+}
+
+// reencryptSurveyResponses re-encrypts in batches the responses to sensitive surveys of the orgs whose data key was
+// rotated. The progress is kept in the responses themselves, so an interrupted re-encryption resumes on the next run.
+func (a *Application) reencryptSurveyResponses() {
+	keys, err := a.storage.GetDataKeysToReencrypt()
+	if err != nil {
+		a.logger.Errorf("error getting the data keys to re-encrypt with - %s", err)
+		return
+	}
+
+	for _, key := range keys {
+		var total int64
+		for {
+			count, err := a.storage.ReencryptSurveyResponses(key.OrgID, dataKeyReencryptionBatchSize)
+			if err != nil {
+				a.logger.Errorf("error re-encrypting the survey responses of org %s - %s", key.OrgID, err)
+				break
+			}
+			total += count
+			if count < dataKeyReencryptionBatchSize {
+				err = a.storage.SetDataKeyReencrypted(key.ID, time.Now().UTC())
+				if err != nil {
+					a.logger.Errorf("error completing the re-encryption with data key %s - %s", key.ID, err)
+				}
+				break
+			}
+		}
+		if total > 0 {
+			a.logger.Infof("re-encrypted %d survey responses of org %s", total, key.OrgID)
+		}
+	}
+}
+
 // newAppSystem creates new appSystem
 func newAppSystem(app *Application) appSystem {
 	return appSystem{app: app}
@@ -0,0 +1,46 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core_test
+
+import (
+	"application/core/interfaces/mocks"
+	"application/core/model"
+	"errors"
+	"testing"
+)
+
+func TestAppSystem_RotateDataKey(t *testing.T) {
+	key := model.DataKey{ID: "key", OrgID: "org", Version: 2, Active: true}
+
+	storage := mocks.NewStorage(t)
+	mockPerformTransaction(storage)
+	storage.On("RotateDataKey", "org").Return(&key, nil).Once()
+	storage.On("CountSurveyResponsesToReencrypt", "org").Return(int64(3), nil).Once()
+	app := buildTestApplication(storage)
+
+	rotation, err := app.System.RotateDataKey("org")
+	if err != nil {
+		t.Fatalf("System.RotateDataKey() error = %v", err)
+	}
+	if rotation.Key.ID != "key" || rotation.Pending != 3 {
+		t.Errorf("System.RotateDataKey() = %+v, want key and 3 responses pending re-encryption", rotation)
+	}
+
+	// nothing is counted if the new key could not be created
+	storage.On("RotateDataKey", "org").Return(nil, errors.New("no master key")).Once()
+	if _, err = app.System.RotateDataKey("org"); err == nil {
+		t.Error("System.RotateDataKey() error = nil, want error")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"application/utils"
+	"encoding/json"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// setSurveyTemplate marks the survey as a template, or removes the mark if template is nil. Only admins and the creator
+// may mark a survey as a template, and only admins may list it in the template catalog.
+func (a appShared) setSurveyTemplate(id string, orgID string, appID string, userID string, admin bool, template *model.SurveyTemplate, audit model.AuditContext) (*model.Survey, error) {
+	//1. find survey
+	survey, err := a.app.storage.GetSurvey(id, orgID, appID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+	}
+	if survey == nil {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"id": id, "app_id": appID, "org_id": orgID})
+	}
+	if !admin && survey.CreatorID != userID {
+		return nil, errors.ErrorData(logutils.StatusInvalid, "user", &logutils.FieldArgs{"id": id, "creator": false})
+	}
+
+	//2. keep the catalog listing and creation date of an existing template
+	if template != nil {
+		if template.Name == "" {
+			template.Name = survey.Title
+		}
+		template.DateCreated = time.Now().UTC()
+		if survey.Template != nil {
+			template.DateCreated = survey.Template.DateCreated
+		}
+		if !admin {
+			template.InCatalog = survey.Template != nil && survey.Template.InCatalog
+		}
+	}
+
+	//3. update the template
+	err = a.app.storage.UpdateSurveyTemplate(id, orgID, appID, template)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurveyTemplate, &logutils.FieldArgs{"id": id}, err)
+	}
+
+	before := *survey
+	survey.Template = template
+	a.recordAuditEvent(audit, model.AuditEntitySurvey, survey.ID, survey.OrgID, survey.AppID, before, *survey)
+	return survey, nil
+}
+
+// cloneSurvey creates a new survey from the template with the provided ID. Users may clone the templates in the catalog
+// and their own templates.
+func (a appShared) cloneSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool, overrides model.SurveyCloneOverrides,
+	audit model.AuditContext) (*model.Survey, error) {
+	//1. find template
+	template, err := a.app.storage.GetSurvey(id, orgID, appID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err)
+	}
+	if template == nil || template.Template == nil {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeSurveyTemplate, &logutils.FieldArgs{"id": id, "app_id": appID, "org_id": orgID})
+	}
+	if !admin && !template.Template.InCatalog && template.CreatorID != userID {
+		return nil, errors.ErrorData(logutils.StatusInvalid, "user", &logutils.FieldArgs{"id": id, "creator": false, "in_catalog": false})
+	}
+
+	//2. copy the definition
+	survey, err := clonedSurvey(*template, overrides, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		// users only create user surveys
+		survey.Type = "user"
+	}
+	if !admin && template.CreatorID != userID {
+		// the audience and alert rules of a catalog template are not shown to other users, so they are not copied either
+		survey = survey.RespondentView()
+	}
+
+	//3. create the survey
+	return a.createSurvey(survey, externalIDs, false, audit)
+}
+
+// clonedSurvey returns a deep copy of the template definition with the overrides applied. The copy has no calendar event
+// or dates unless they are overridden. The recurrence is kept, so a recurring template is only cloned with a start date.
+func clonedSurvey(template model.Survey, overrides model.SurveyCloneOverrides, creatorID string) (model.Survey, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return model.Survey{}, errors.WrapErrorAction(logutils.ActionMarshal, model.TypeSurvey, nil, err)
+	}
+	var survey model.Survey
+	err = json.Unmarshal(data, &survey)
+	if err != nil {
+		return model.Survey{}, errors.WrapErrorAction(logutils.ActionUnmarshal, model.TypeSurvey, nil, err)
+	}
+
+	survey.ID = ""
+	survey.CreatorID = creatorID
+	survey.SurveyStats = nil
+	survey.DateUpdated = nil
+	survey.Revision = 0
+	survey.Archived = nil
+	survey.ResponseWindowOverride = nil
+	survey.Template = nil
+	survey.DeletedAt = nil
+
+	if overrides.Title != nil {
+		survey.Title = *overrides.Title
+	}
+	survey.CalendarEventID = utils.GetString(overrides.CalendarEventID)
+	survey.StartDate = overrides.StartDate
+	survey.EndDate = overrides.EndDate
+	if survey.Recurrence != nil && survey.StartDate == nil {
+		// the recurrence windows follow the start date of the new survey
+		return model.Survey{}, errors.ErrorData(logutils.StatusMissing, "survey start date", &logutils.FieldArgs{"template_id": template.ID, "recurrence": template.Recurrence.Rule})
+	}
+	survey.Status = utils.GetString(overrides.Status)
+	return survey, nil
+}
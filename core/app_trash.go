@@ -0,0 +1,135 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/interfaces"
+	"application/core/model"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	// surveyPurgeInterval is how often the surveys in the trash are checked for purging
+	surveyPurgeInterval = time.Hour
+	// defaultSurveyPurgePeriod is how long deleted surveys are kept in the trash unless the env config sets a purge period
+	defaultSurveyPurgePeriod = 30 * 24 * time.Hour
+	// deletedSurveyResponsesBatchSize is the number of responses to a purged survey deleted or archived at once
+	deletedSurveyResponsesBatchSize = 500
+)
+
+// surveyPurgePeriod returns how long deleted surveys are kept in the trash
+func (a *Application) surveyPurgePeriod() time.Duration {
+	envConfig, err := a.GetEnvConfigs()
+	if err != nil {
+		a.logger.Warnf("error getting survey purge period, using the default - %s", err)
+		return defaultSurveyPurgePeriod
+	}
+	if envConfig.SurveyPurgePeriod <= 0 {
+		return defaultSurveyPurgePeriod
+	}
+	return time.Duration(envConfig.SurveyPurgePeriod) * 24 * time.Hour
+}
+
+// validateDeletedSurveyResponses checks the policy for the responses to a deleted survey
+func validateDeletedSurveyResponses(policy string) error {
+	switch policy {
+	case model.DeletedSurveyResponsesKeep, model.DeletedSurveyResponsesCascade, model.DeletedSurveyResponsesArchive:
+		return nil
+	default:
+		return errors.ErrorData(logutils.StatusInvalid, "deleted survey responses policy", logutils.StringArgs(policy))
+	}
+}
+
+// deletedSurveyResponses returns the policy for the responses to a deleted survey. The requested policy takes
+// precedence over the env config, and responses are kept if neither sets one.
+func (a *Application) deletedSurveyResponses(requested string) (string, error) {
+	if requested != "" {
+		return requested, validateDeletedSurveyResponses(requested)
+	}
+
+	envConfig, err := a.GetEnvConfigs()
+	if err != nil {
+		a.logger.Warnf("error getting the deleted survey responses policy, keeping the responses - %s", err)
+		return model.DeletedSurveyResponsesKeep, nil
+	}
+	if envConfig.DeletedSurveyResponses == "" {
+		return model.DeletedSurveyResponsesKeep, nil
+	}
+	return envConfig.DeletedSurveyResponses, validateDeletedSurveyResponses(envConfig.DeletedSurveyResponses)
+}
+
+// handleDeletedSurveyResponses deletes or archives in batches the responses to a purged survey as its policy requires.
+// The responses are kept until the survey is purged so that restoring the survey restores them as well.
+func handleDeletedSurveyResponses(storage interfaces.Storage, survey model.Survey) error {
+	var handleBatch func() (int64, error)
+	switch survey.DeletedResponses {
+	case model.DeletedSurveyResponsesCascade:
+		handleBatch = func() (int64, error) {
+			return storage.DeleteSurveyResponsesForSurvey(survey.ID, survey.OrgID, survey.AppID, deletedSurveyResponsesBatchSize)
+		}
+	case model.DeletedSurveyResponsesArchive:
+		archivedAt := time.Now().UTC()
+		handleBatch = func() (int64, error) {
+			return storage.ArchiveSurveyResponses(survey.ID, survey.OrgID, survey.AppID, archivedAt, deletedSurveyResponsesBatchSize)
+		}
+	default:
+		return nil
+	}
+
+	for {
+		count, err := handleBatch()
+		if err != nil {
+			return errors.WrapErrorAction("applying", "deleted survey responses policy", &logutils.FieldArgs{"survey_id": survey.ID, "policy": survey.DeletedResponses}, err)
+		}
+		if count == 0 {
+			return nil
+		}
+	}
+}
+
+// purgeDeletedSurveys deletes the surveys which have been in the trash for longer than the purge period. The responses
+// policy of each survey is applied in the transaction purging it, so a survey whose responses could not be deleted or
+// archived stays in the trash and is purged by a later run.
+func (a *Application) purgeDeletedSurveys() {
+	before := time.Now().UTC().Add(-a.surveyPurgePeriod())
+	surveys, err := a.storage.GetPurgeableSurveys(before)
+	if err != nil {
+		a.logger.Errorf("error getting the surveys to purge - %s", err)
+		return
+	}
+
+	count := 0
+	for _, survey := range surveys {
+		transaction := func(storage interfaces.Storage) error {
+			err := handleDeletedSurveyResponses(storage, survey)
+			if err != nil {
+				return err
+			}
+			return storage.PurgeDeletedSurvey(survey.ID, survey.OrgID, survey.AppID)
+		}
+		err = a.storage.PerformTransaction(transaction)
+		if err != nil {
+			a.logger.Errorf("error purging deleted survey %s - %s", survey.ID, err)
+			continue
+		}
+		count++
+	}
+	if count > 0 {
+		a.logger.Infof("purged %d deleted surveys", count)
+	}
+}
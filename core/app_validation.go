@@ -0,0 +1,261 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"application/core/model"
+	"application/utils"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// dateTimeResponseLayouts are the accepted formats for responses to date/time questions
+var dateTimeResponseLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02", "01-02-2006 15:04", "01-02-2006"}
+
+// validateSurveyResponse checks the answers in data against the question definitions of the provided survey.
+// Questions on the path through the survey must be answered unless they can be skipped. If the path is complete,
+// answers to linked questions that are not on the path are rejected as well. If there is no complete path, every
+// question must be answered unless it can be skipped.
+func validateSurveyResponse(survey model.Survey, data map[string]model.SurveyData, path []string, pathComplete bool) []model.SurveyDataError {
+	errs := make([]model.SurveyDataError, 0)
+	if len(survey.Data) == 0 {
+		return errs
+	}
+
+	for key := range data {
+		if _, ok := survey.Data[key]; !ok {
+			errs = append(errs, surveyDataError(key, model.ValidationCodeUnknownKey, "question does not exist in survey"))
+		}
+	}
+
+	onPath := make(map[string]bool, len(path))
+	for _, key := range path {
+		onPath[key] = true
+	}
+	checkPath := pathComplete && len(path) > 0
+	linked := linkedSurveyDataKeys(survey)
+	for key, question := range survey.Data {
+		if !isSurveyQuestion(question) {
+			continue
+		}
+
+		var response interface{}
+		if answer, ok := data[key]; ok {
+			response = utils.NormalizeValue(answer.Response)
+		}
+		if isEmptyResponse(response) {
+			if (onPath[key] || !checkPath) && !question.AllowSkip {
+				errs = append(errs, surveyDataError(key, model.ValidationCodeMissing, "an answer is required"))
+			}
+			continue
+		}
+		if checkPath && !onPath[key] && linked[key] {
+			errs = append(errs, surveyDataError(key, model.ValidationCodeNotOnPath, "question is skipped by the answers to previous questions"))
+			continue
+		}
+
+		errs = append(errs, validateSurveyDataResponse(key, question, response)...)
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Key == errs[j].Key {
+			return errs[i].Code < errs[j].Code
+		}
+		return errs[i].Key < errs[j].Key
+	})
+	return errs
+}
+
+// validateSurveyResponses sets the validation errors of the provided survey responses against the current survey definition
+func validateSurveyResponses(survey model.Survey, surveyResponses []model.SurveyResponse) {
+	for i := range surveyResponses {
+		evaluation := evaluateSurveyResponse(survey, surveyResponses[i].Survey.Data)
+		if len(evaluation.ValidationErrors) > 0 {
+			surveyResponses[i].ValidationErrors = evaluation.ValidationErrors
+		}
+	}
+}
+
+// validateSurveyDataResponse checks a non-empty response against the definition of a single question
+func validateSurveyDataResponse(key string, question model.SurveyData, response interface{}) []model.SurveyDataError {
+	switch question.Type {
+	case model.SurveyDataTypeTrueFalse:
+		if len(question.Options) > 0 {
+			if !hasOptionValue(question.Options, response) {
+				return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeInvalidOption, "answer is not one of the question options")}
+			}
+		} else if _, ok := response.(bool); !ok {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeInvalidType, "answer must be a boolean")}
+		}
+	case model.SurveyDataTypeMultipleChoice:
+		return validateMultipleChoiceResponse(key, question, response)
+	case model.SurveyDataTypeNumeric:
+		value, ok := utils.ToFloat64(response)
+		if !ok {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeInvalidType, "answer must be a number")}
+		}
+		if question.WholeNum != nil && *question.WholeNum && value != math.Trunc(value) {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeNotWholeNumber, "answer must be a whole number")}
+		}
+		if (question.Minimum != nil && value < *question.Minimum) || (question.Maximum != nil && value > *question.Maximum) {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeOutOfRange, "answer is outside of the allowed range")}
+		}
+	case model.SurveyDataTypeText:
+		value, ok := response.(string)
+		if !ok {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeInvalidType, "answer must be a string")}
+		}
+		length := utf8.RuneCountInString(value)
+		if (question.MinLength != nil && length < *question.MinLength) || (question.MaxLength != nil && length > *question.MaxLength) {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeInvalidLength, "answer length is outside of the allowed range")}
+		}
+	case model.SurveyDataTypeDateTime:
+		value, ok := response.(string)
+		if !ok {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeInvalidType, "answer must be a string")}
+		}
+		date, ok := parseDateTimeResponse(value)
+		if !ok {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeInvalidFormat, "answer is not a valid date")}
+		}
+		if (question.StartTime != nil && date.Before(*question.StartTime)) || (question.EndTime != nil && date.After(*question.EndTime)) {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeOutOfRange, "answer is outside of the allowed range")}
+		}
+	case model.SurveyDataTypeEntry:
+		return validateDataEntryResponse(key, question, response)
+	}
+	return nil
+}
+
+func validateMultipleChoiceResponse(key string, question model.SurveyData, response interface{}) []model.SurveyDataError {
+	values, isList := response.([]interface{})
+	if !isList {
+		values = []interface{}{response}
+	} else if len(values) > 1 && (question.AllowMultiple == nil || !*question.AllowMultiple) {
+		return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeMultipleNotAllowed, "only one answer is allowed")}
+	}
+
+	if len(question.Options) == 0 {
+		return nil
+	}
+	for _, value := range values {
+		if !hasOptionValue(question.Options, value) {
+			return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeInvalidOption, fmt.Sprintf("answer %v is not one of the question options", value))}
+		}
+	}
+	return nil
+}
+
+func validateDataEntryResponse(key string, question model.SurveyData, response interface{}) []model.SurveyDataError {
+	entry, ok := response.(map[string]interface{})
+	if !ok {
+		return []model.SurveyDataError{surveyDataError(key, model.ValidationCodeInvalidType, "answer must be an object")}
+	}
+	if len(question.DataFormat) == 0 {
+		return nil
+	}
+
+	errs := make([]model.SurveyDataError, 0)
+	for field := range entry {
+		if _, ok := question.DataFormat[field]; !ok {
+			errs = append(errs, surveyDataError(key+"."+field, model.ValidationCodeUnknownKey, "field does not exist in data format"))
+		}
+	}
+	for field, format := range question.DataFormat {
+		value := entry[field]
+		if isEmptyResponse(value) {
+			if !question.AllowSkip {
+				errs = append(errs, surveyDataError(key+"."+field, model.ValidationCodeMissing, "a value is required"))
+			}
+			continue
+		}
+		if !matchesDataFormat(format, value) {
+			errs = append(errs, surveyDataError(key+"."+field, model.ValidationCodeInvalidFormat, fmt.Sprintf("value must be of type %s", format)))
+		}
+	}
+	return errs
+}
+
+// matchesDataFormat checks if a data entry value matches the provided format. Unknown formats are accepted.
+func matchesDataFormat(format string, value interface{}) bool {
+	switch strings.ToLower(format) {
+	case "int", "integer":
+		number, ok := utils.ToFloat64(value)
+		return ok && number == math.Trunc(number)
+	case "double", "float", "number", "num":
+		_, ok := utils.ToFloat64(value)
+		return ok
+	case "bool", "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string", "text":
+		_, ok := value.(string)
+		return ok
+	case "date", "date_time", "datetime":
+		str, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, ok = parseDateTimeResponse(str)
+		return ok
+	default:
+		return true
+	}
+}
+
+func parseDateTimeResponse(value string) (time.Time, bool) {
+	for _, layout := range dateTimeResponseLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isSurveyQuestion returns true if the survey data expects a response from the user
+func isSurveyQuestion(question model.SurveyData) bool {
+	return question.Type != model.SurveyDataTypePage && question.Type != model.SurveyDataTypeResult
+}
+
+func isEmptyResponse(response interface{}) bool {
+	switch value := response.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []interface{}:
+		return len(value) == 0
+	case map[string]interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+func hasOptionValue(options []model.OptionData, value interface{}) bool {
+	for _, option := range options {
+		if utils.ValuesEqual(utils.NormalizeValue(option.Value), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func surveyDataError(key string, code string, message string) model.SurveyDataError {
+	return model.SurveyDataError{Key: key, Code: code, Message: message}
+}
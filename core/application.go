@@ -18,6 +18,7 @@ import (
 	"application/core/interfaces"
 	"application/core/model"
 	corebb "application/driven/core"
+	"sync"
 
 	"github.com/rokwire/core-auth-library-go/v3/authutils"
 	"github.com/rokwire/logging-library-go/v2/errors"
@@ -56,8 +57,15 @@ type Application struct {
 	storage         interfaces.Storage
 	notifications   interfaces.Notifications
 	calendar        interfaces.Calendar
+	webhooks        interfaces.Webhooks
+	sms             interfaces.SMS
+	alertChannels   map[string]alertChannel
 	corebb          *corebb.Adapter
 	deleteDataLogic deleteDataLogic
+	scheduler       schedulerLogic
+
+	// eventRegistrations caches the event registrations of users checked when listing surveys
+	eventRegistrations sync.Map
 }
 
 // Start starts the core part of the application
@@ -66,6 +74,7 @@ func (a *Application) Start() {
 	storageListener := storageListener{app: a}
 	a.storage.RegisterStorageListener(&storageListener)
 	a.deleteDataLogic.start()
+	a.scheduler.start()
 }
 
 // GetEnvConfigs retrieves the cached database env configs
@@ -83,11 +92,12 @@ func (a *Application) GetEnvConfigs() (*model.EnvConfigData, error) {
 
 // NewApplication creates new Application
 func NewApplication(version string, build string, storage interfaces.Storage, notifications interfaces.Notifications, calendar interfaces.Calendar,
-	coreBB *corebb.Adapter, serviceID string, logger *logs.Logger) *Application {
+	webhooks interfaces.Webhooks, sms interfaces.SMS, coreBB *corebb.Adapter, serviceID string, logger *logs.Logger) *Application {
 	deleteDataLogic := deleteDataLogic{logger: *logger, core: coreBB, serviceID: serviceID, storage: storage}
 
 	application := Application{version: version, build: build, storage: storage, notifications: notifications,
-		calendar: calendar, deleteDataLogic: deleteDataLogic, logger: logger}
+		calendar: calendar, webhooks: webhooks, sms: sms, deleteDataLogic: deleteDataLogic, logger: logger}
+	application.alertChannels = newAlertChannels(&application)
 
 	//add the drivers ports/interfaces
 	application.Default = newAppDefault(&application)
@@ -99,5 +109,16 @@ func NewApplication(version string, build string, storage interfaces.Storage, no
 	application.System = newAppSystem(&application)
 	application.shared = newAppShared(&application)
 
+	//add the scheduled jobs
+	application.scheduler = schedulerLogic{logger: *logger}
+	application.scheduler.add("survey statuses", surveyStatusInterval, application.updateSurveyStatuses)
+	application.scheduler.add("survey response drafts", surveyResponseDraftsInterval, application.deleteStaleSurveyResponseDrafts)
+	application.scheduler.add("deleted surveys", surveyPurgeInterval, application.purgeDeletedSurveys)
+	application.scheduler.add("event surveys", eventSurveysInterval, application.createEventSurveys)
+	application.scheduler.add("recurring surveys", surveyRecurrenceInterval, application.processRecurringSurveys)
+	application.scheduler.add("survey reminders", surveyRemindersInterval, application.sendSurveyReminders)
+	application.scheduler.add("data key re-encryption", dataKeyReencryptionInterval, application.reencryptSurveyResponses)
+	application.scheduler.add("event registrations cache", eventRegistrationCacheTTL, application.pruneEventRegistrations)
+
 	return &application
 }
@@ -19,6 +19,7 @@ import (
 	"application/core/interfaces"
 	"application/core/interfaces/mocks"
 	"application/core/model"
+	"application/driven/calendar"
 	"errors"
 	"reflect"
 	"testing"
@@ -35,9 +36,51 @@ const (
 )
 
 func buildTestApplication(storage interfaces.Storage) *core.Application {
+	return buildTestApplicationWithNotifications(storage, nil)
+}
+
+func buildTestApplicationWithNotifications(storage interfaces.Storage, notifications interfaces.Notifications) *core.Application {
+	loggerOpts := logs.LoggerOpts{SuppressRequests: logs.NewStandardHealthCheckHTTPRequestProperties(serviceID + "/version")}
+	logger := logs.NewLogger(serviceID, &loggerOpts)
+	return core.NewApplication("1.1.1", "build", storage, notifications, nil, nil, nil, nil, "", logger)
+}
+
+func buildTestApplicationWithCalendar(storage interfaces.Storage, calendarBB interfaces.Calendar) *core.Application {
 	loggerOpts := logs.LoggerOpts{SuppressRequests: logs.NewStandardHealthCheckHTTPRequestProperties(serviceID + "/version")}
 	logger := logs.NewLogger(serviceID, &loggerOpts)
-	return core.NewApplication("1.1.1", "build", storage, nil, nil, nil, "", logger)
+	return core.NewApplication("1.1.1", "build", storage, nil, calendarBB, nil, nil, nil, "", logger)
+}
+
+// calendarStub answers the Calendar BB requests with fixed events and event users
+type calendarStub struct {
+	events map[string]calendar.Event
+	users  []calendar.EventPerson
+}
+
+func (c *calendarStub) GetEventUsers(orgID string, appID string, eventID string, users []calendar.User, registered *bool, role string, attended *bool) ([]calendar.EventPerson, error) {
+	return c.users, nil
+}
+
+func (c *calendarStub) GetEvent(orgID string, appID string, eventID string) (*calendar.Event, error) {
+	event, ok := c.events[eventID]
+	if !ok {
+		return nil, nil
+	}
+	return &event, nil
+}
+
+// notificationsRecorder records the messages and emails sent through the Notifications BB
+type notificationsRecorder struct {
+	notifications []model.NotificationMessage
+	mails         []string
+}
+
+func (n *notificationsRecorder) SendNotification(notification model.NotificationMessage) {
+	n.notifications = append(n.notifications, notification)
+}
+
+func (n *notificationsRecorder) SendMail(toEmail string, subject string, body string) {
+	n.mails = append(n.mails, toEmail+"|"+subject+"|"+body)
 }
 
 func TestApplication_Start(t *testing.T) {
@@ -14,21 +14,37 @@
 
 package core
 
-import "application/core/model"
+import (
+	"application/core/interfaces"
+	"application/core/model"
+	"time"
+)
 
 // Shared exposes shared APIs for other interface implementations
 type Shared interface {
 	// Surveys
 	getSurvey(id string, orgID string, appID string) (*model.Survey, error)
-	getSurveys(orgID string, appID string, userID *string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, []model.SurveyResponse, error)
-	createSurvey(survey model.Survey, externalIDs map[string]string) (*model.Survey, error)
-	updateSurvey(survey model.Survey, userID string, externalIDs map[string]string, admin bool) error
-	deleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool) error
+	getSurveys(orgID string, appID string, userID *string, audience *model.SurveyAudienceUser, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool, admin bool) ([]model.Survey, []model.SurveyResponse, error)
+	createSurvey(survey model.Survey, externalIDs map[string]string, strict bool, audit model.AuditContext) (*model.Survey, error)
+	updateSurvey(survey model.Survey, userID string, externalIDs map[string]string, admin bool, strict bool, audit model.AuditContext) error
+	deleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool, responsesPolicy string, audit model.AuditContext) error
+	publishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool, audit model.AuditContext) (*model.Survey, error)
+	unpublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool, audit model.AuditContext) (*model.Survey, error)
+	setSurveyTemplate(id string, orgID string, appID string, userID string, admin bool, template *model.SurveyTemplate, audit model.AuditContext) (*model.Survey, error)
+	cloneSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, admin bool, overrides model.SurveyCloneOverrides, audit model.AuditContext) (*model.Survey, error)
+
+	exportSurveyResponses(survey model.Survey, startDate *time.Time, endDate *time.Time, writer interfaces.ExportWriter) error
+	checkRawSurveyResponses(survey model.Survey) error
 
 	isEventAdmin(orgID string, appID string, eventID string, userID string, externalIDs map[string]string) (bool, error)
 	hasAttendedEvent(orgID string, appID string, eventID string, userID string, externalIDs map[string]string) (bool, error)
+	getEventEndDate(orgID string, appID string, eventID string) (*time.Time, error)
+	isRegisteredForEvent(orgID string, appID string, eventID string, userID string, externalIDs map[string]string) (bool, error)
+	checkSurveyAudience(survey model.Survey, user model.SurveyAudienceUser) error
 
 	getUserData(orgID string, appID string, userID *string) (*model.UserData, error)
+
+	recordAuditEvent(audit model.AuditContext, entity string, entityID string, orgID string, appID string, before interface{}, after interface{})
 }
 
 // Core exposes Core APIs for the driver adapters
@@ -29,23 +29,48 @@ type Default interface {
 // Client exposes client APIs for the driver adapters
 type Client interface {
 	// Surveys
-	GetSurvey(id string, orgID string, appID string) (*model.Survey, error)
-	GetSurveys(orgID string, appID string, userID *string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, []model.SurveyResponse, error)
-	CreateSurvey(survey model.Survey, externalIDs map[string]string) (*model.Survey, error)
-	UpdateSurvey(survey model.Survey, userID string, externalIDs map[string]string) error
-	DeleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string) error
+	GetSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, permissions []string) (*model.Survey, error)
+	GetSurveys(orgID string, appID string, userID *string, externalIDs map[string]string, permissions []string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, []model.SurveyResponse, error)
+	CreateSurvey(survey model.Survey, externalIDs map[string]string, strict bool, audit model.AuditContext) (*model.Survey, error)
+	UpdateSurvey(survey model.Survey, userID string, externalIDs map[string]string, strict bool, audit model.AuditContext) error
+	ValidateSurvey(survey model.Survey) model.SurveyLint
+	DeleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) error
+	PublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) (*model.Survey, error)
+	UnpublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) (*model.Survey, error)
+	EvaluateSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, permissions []string, data map[string]model.SurveyData) (*model.SurveyEvaluation, error)
+
+	// Survey Templates
+	GetSurveyTemplates(orgID string, appID string, userID string, mine bool, category *string, limit *int, offset *int) ([]model.Survey, error)
+	SetSurveyTemplate(id string, orgID string, appID string, userID string, template model.SurveyTemplate, audit model.AuditContext) (*model.Survey, error)
+	RemoveSurveyTemplate(id string, orgID string, appID string, userID string, audit model.AuditContext) (*model.Survey, error)
+	CloneSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, overrides model.SurveyCloneOverrides, audit model.AuditContext) (*model.Survey, error)
+
+	// Event Survey Subscriptions
+	SubscribeEventSurvey(subscription model.EventSurveySubscription, externalIDs map[string]string) (*model.EventSurveySubscription, error)
+	GetEventSurveySubscription(calendarEventID string, orgID string, appID string, userID string, externalIDs map[string]string) (*model.EventSurveySubscription, error)
+	UnsubscribeEventSurvey(calendarEventID string, orgID string, appID string, userID string, externalIDs map[string]string) error
+
+	// Survey Reminders
+	GetSurveyReminderMutes(orgID string, appID string, userID string) ([]model.SurveyReminderMute, error)
+	MuteSurveyReminders(orgID string, appID string, userID string, surveyID string) (*model.SurveyReminderMute, error)
+	UnmuteSurveyReminders(orgID string, appID string, userID string, surveyID string) error
 
 	// Survey Response
 	GetSurveyResponse(id string, orgID string, appID string, userID string) (*model.SurveyResponse, error)
 	GetUserSurveyResponses(orgID string, appID string, userID string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time, limit *int, offset *int) ([]model.SurveyResponse, error)
-	GetAllSurveyResponses(orgID string, appID string, userID string, surveyID string, startDate *time.Time, endDate *time.Time, limit *int, offset *int, externalIDs map[string]string) ([]model.SurveyResponse, error)
-	CreateSurveyResponse(surveyResponse model.SurveyResponse, externalIDs map[string]string) (*model.SurveyResponse, error)
-	UpdateSurveyResponse(surveyResponse model.SurveyResponse) error
-	DeleteSurveyResponse(id string, orgID string, appID string, userID string) error
-	DeleteSurveyResponses(orgID string, appID string, userID string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time) error
+	GetAllSurveyResponses(orgID string, appID string, userID string, surveyID string, startDate *time.Time, endDate *time.Time, limit *int, offset *int, externalIDs map[string]string, validate bool) ([]model.SurveyResponse, error)
+	ExportSurveyResponses(orgID string, appID string, userID string, surveyID string, startDate *time.Time, endDate *time.Time, externalIDs map[string]string, writer ExportWriter) error
+	GetSurveyResults(surveyID string, orgID string, appID string, userID string, externalIDs map[string]string, startDate *time.Time, endDate *time.Time, interval string) (*model.SurveyResults, error)
+	CreateSurveyResponse(surveyResponse model.SurveyResponse, externalIDs map[string]string, permissions []string, audit model.AuditContext) (*model.SurveyResponse, error)
+	UpdateSurveyResponse(surveyResponse model.SurveyResponse, externalIDs map[string]string, permissions []string, audit model.AuditContext) error
+	GetSurveyResponseDraft(surveyID string, orgID string, appID string, userID string) (*model.SurveyResponse, error)
+	SaveSurveyResponseDraft(surveyID string, orgID string, appID string, userID string, key string, answer model.SurveyData, externalIDs map[string]string, permissions []string, audit model.AuditContext) (*model.SurveyResponse, error)
+	FinalizeSurveyResponseDraft(surveyID string, orgID string, appID string, userID string, externalIDs map[string]string, permissions []string, audit model.AuditContext) (*model.SurveyResponse, error)
+	DeleteSurveyResponse(id string, orgID string, appID string, userID string, audit model.AuditContext) error
+	DeleteSurveyResponses(orgID string, appID string, userID string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time, audit model.AuditContext) error
 
 	// Survey Alerts
-	CreateSurveyAlert(surveyAlert model.SurveyAlert) error
+	CreateSurveyAlert(surveyAlert model.SurveyAlert) ([]model.AlertDelivery, error)
 
 	// User data
 	GetUserData(orgID string, appID string, userID *string) (*model.UserData, error)
@@ -56,32 +81,63 @@ type Admin interface {
 	// Configs
 	GetConfig(id string, claims *tokenauth.Claims) (*model.Config, error)
 	GetConfigs(configType *string, claims *tokenauth.Claims) ([]model.Config, error)
-	CreateConfig(config model.Config, claims *tokenauth.Claims) (*model.Config, error)
-	UpdateConfig(config model.Config, claims *tokenauth.Claims) error
-	DeleteConfig(id string, claims *tokenauth.Claims) error
+	CreateConfig(config model.Config, claims *tokenauth.Claims, audit model.AuditContext) (*model.Config, error)
+	UpdateConfig(config model.Config, claims *tokenauth.Claims, audit model.AuditContext) error
+	DeleteConfig(id string, claims *tokenauth.Claims, audit model.AuditContext) error
 
 	// Surveys
 	GetSurvey(id string, orgID string, appID string) (*model.Survey, error)
 	GetSurveys(orgID string, appID string, userID *string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, []model.SurveyResponse, error)
-	CreateSurvey(survey model.Survey, externalIDs map[string]string) (*model.Survey, error)
-	UpdateSurvey(survey model.Survey, userID string, externalIDs map[string]string) error
-	DeleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string) error
+	CreateSurvey(survey model.Survey, externalIDs map[string]string, strict bool, audit model.AuditContext) (*model.Survey, error)
+	UpdateSurvey(survey model.Survey, userID string, externalIDs map[string]string, strict bool, audit model.AuditContext) error
+	ValidateSurvey(survey model.Survey) model.SurveyLint
+	DeleteSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, responsesPolicy string, audit model.AuditContext) error
+	PublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) (*model.Survey, error)
+	UnpublishSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, audit model.AuditContext) (*model.Survey, error)
+	SetSurveyResponseWindowOverride(id string, orgID string, appID string, override *bool, audit model.AuditContext) (*model.Survey, error)
+	GetDeletedSurveys(orgID string, appID string, limit *int, offset *int) ([]model.Survey, error)
+	RestoreSurvey(id string, orgID string, appID string, audit model.AuditContext) (*model.Survey, error)
+
+	// Survey Templates
+	GetSurveyTemplates(orgID string, appID string, filter model.SurveyTemplateFilter) ([]model.Survey, error)
+	SetSurveyTemplate(id string, orgID string, appID string, userID string, template model.SurveyTemplate, audit model.AuditContext) (*model.Survey, error)
+	RemoveSurveyTemplate(id string, orgID string, appID string, userID string, audit model.AuditContext) (*model.Survey, error)
+	CloneSurvey(id string, orgID string, appID string, userID string, externalIDs map[string]string, overrides model.SurveyCloneOverrides, audit model.AuditContext) (*model.Survey, error)
+
+	// Event Survey Subscriptions
+	GetEventSurveySubscriptions(orgID string, appID string, status *string, limit *int, offset *int) ([]model.EventSurveySubscription, error)
+
+	// Survey Reminders
+	GetSurveyReminders(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyReminder, error)
+	GetSurveyAudience(surveyID string, orgID string, appID string) (*model.SurveyAudiencePreview, error)
+
+	// Survey Revisions
+	GetSurveyRevisions(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyRevision, error)
+	GetSurveyRevision(surveyID string, orgID string, appID string, revision int) (*model.SurveyRevision, error)
+	GetSurveyRevisionDiff(surveyID string, orgID string, appID string, from int, to int) (*model.SurveyRevisionDiff, error)
+	RestoreSurveyRevision(surveyID string, orgID string, appID string, revision int, userID string, audit model.AuditContext) (*model.Survey, error)
 
 	// Survey Responses
-	GetAllSurveyResponses(orgID string, appID string, surveyID string, userID string, externalIDs map[string]string, startDate *time.Time, endDate *time.Time, limit *int, offset *int) ([]model.SurveyResponse, error)
-	GetAllSurveysResponses(orgID string, appID string, surveyID string, userID string, externalIDs map[string]string, startDate *time.Time, endDate *time.Time, limit *int, offset *int) ([]model.SurveyResponse, error)
+	GetAllSurveyResponses(orgID string, appID string, surveyID string, userID string, externalIDs map[string]string, startDate *time.Time, endDate *time.Time, limit *int, offset *int, validate bool) ([]model.SurveyResponse, error)
+	GetAllSurveysResponses(orgID string, appID string, surveyID string, userID string, externalIDs map[string]string, startDate *time.Time, endDate *time.Time, limit *int, offset *int, validate bool) ([]model.SurveyResponse, error)
+	ExportSurveyResponses(orgID string, appID string, surveyID string, startDate *time.Time, endDate *time.Time, writer ExportWriter) error
 
 	// Alert Contacts
 	GetAlertContacts(orgID string, appID string) ([]model.AlertContact, error)
 	GetAlertContact(id string, orgID string, appID string) (*model.AlertContact, error)
-	CreateAlertContact(alertContact model.AlertContact) (*model.AlertContact, error)
-	UpdateAlertContact(alertContact model.AlertContact) error
-	DeleteAlertContact(id string, orgID string, appID string) error
+	CreateAlertContact(alertContact model.AlertContact, audit model.AuditContext) (*model.AlertContact, error)
+	UpdateAlertContact(alertContact model.AlertContact, audit model.AuditContext) error
+	DeleteAlertContact(id string, orgID string, appID string, audit model.AuditContext) error
+
+	// Audit Events
+	GetAuditEvents(orgID string, appID string, filter model.AuditEventFilter) ([]model.AuditEvent, error)
 }
 
 // Analytics exposes Analytics APIs for the driver adapters
 type Analytics interface {
 	GetAnonymousSurveyResponses(surveyTypes []string, startDate *time.Time, endDate *time.Time) ([]model.SurveyResponseAnonymous, error)
+	StreamAnonymousSurveyResponses(surveyTypes []string, startDate *time.Time, endDate *time.Time, after string,
+		handle func(surveyResponse model.SurveyResponseAnonymous, cursor string) error) error
 }
 
 // BBs exposes Building Block APIs for the driver adapters
@@ -94,4 +150,12 @@ type TPS interface {
 
 // System exposes system administrative APIs for the driver adapters
 type System interface {
+	GetDataKeys(orgID string) ([]model.DataKey, error)
+	RotateDataKey(orgID string) (*model.DataKeyRotation, error)
+}
+
+// ExportWriter writes the rows of an export in the format requested by the driver adapter
+type ExportWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []string) error
 }
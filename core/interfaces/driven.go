@@ -34,22 +34,80 @@ type Storage interface {
 
 	GetSurvey(id string, orgID string, appID string) (*model.Survey, error)
 	GetSurveys(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, error)
+	GetSurveyAudiences(orgID string, appID string, surveyIDs []string, calendarEventID string) ([]model.Survey, error)
 	GetSurveysLight(orgID string, appID string, creatorID *string) ([]model.Survey, error)
 
 	CreateSurvey(survey model.Survey) (*model.Survey, error)
 	UpdateSurvey(survey model.Survey, admin bool) error
-	DeleteSurvey(id string, orgID string, appID string, creatorID string, admin bool) error
+	UpdateSurveyStatus(id string, orgID string, appID string, status string, target string) error
+	UpdateSurveyStatuses(now time.Time) (int64, error)
+	UpdateSurveyResponseWindowOverride(id string, orgID string, appID string, override *bool) error
+	GetRecurringSurveys() ([]model.Survey, error)
+	UpdateSurveyRecurrenceWindow(id string, windowStart *time.Time, target *time.Time) (bool, error)
+	UpdateSurveyRecurrenceReminder(id string, windowStart time.Time) (bool, error)
+	GetSurveysForReminders(now time.Time) ([]model.Survey, error)
+	GetSurveyReminders(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyReminder, error)
+	InsertSurveyReminder(reminder model.SurveyReminder) error
+	GetSurveyReminderMutes(orgID string, appID string, userIDs []string, surveyID *string) ([]model.SurveyReminderMute, error)
+	InsertSurveyReminderMute(mute model.SurveyReminderMute) error
+	DeleteSurveyReminderMute(orgID string, appID string, userID string, surveyID string) error
+	DeleteSurveyRemindersWithIDs(orgID string, appID string, accountsIDs []string) error
+	DeleteSurvey(id string, orgID string, appID string, creatorID string, admin bool, responsesPolicy string) error
+	GetDeletedSurveys(orgID string, appID string, limit *int, offset *int) ([]model.Survey, error)
+	GetDeletedSurvey(id string, orgID string, appID string) (*model.Survey, error)
+	RestoreSurvey(id string, orgID string, appID string) error
+	GetSurveyTemplates(orgID string, appID string, filter model.SurveyTemplateFilter) ([]model.Survey, error)
+	UpdateSurveyTemplate(id string, orgID string, appID string, template *model.SurveyTemplate) error
+	GetEventSurveySubscription(calendarEventID string, orgID string, appID string) (*model.EventSurveySubscription, error)
+	GetEventSurveySubscriptions(orgID string, appID string, status *string, limit *int, offset *int) ([]model.EventSurveySubscription, error)
+	InsertEventSurveySubscription(subscription model.EventSurveySubscription) error
+	UpdateEventSurveySubscription(subscription model.EventSurveySubscription) error
+	DeleteEventSurveySubscription(calendarEventID string, orgID string, appID string) error
+	ClaimEventSurveySubscription(now time.Time, staleBefore time.Time) (*model.EventSurveySubscription, error)
+	UpdateEventSurveySubscriptionResult(id string, status string, surveyID *string, notified int, message *string) error
+	RescheduleEventSurveySubscription(id string, eventEndDate time.Time) error
+	GetPurgeableSurveys(before time.Time) ([]model.Survey, error)
+	PurgeDeletedSurvey(id string, orgID string, appID string) error
 	DeleteSurveysWithIDs(orgID string, appID string, accountsIDs []string) error
 
+	GetSurveyRevisions(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyRevision, error)
+	GetSurveyRevision(surveyID string, orgID string, appID string, revision int) (*model.SurveyRevision, error)
+	InsertSurveyRevision(surveyRevision model.SurveyRevision) error
+	DeleteSurveyRevisionsWithIDs(orgID string, appID string, accountsIDs []string) error
+
+	GetAuditEvents(orgID string, appID string, filter model.AuditEventFilter) ([]model.AuditEvent, error)
+	InsertAuditEvent(auditEvent model.AuditEvent) error
+
 	GetSurveyResponse(id string, orgID string, appID string, userID string) (*model.SurveyResponse, error)
 	GetSurveyResponses(orgID *string, appID *string, userID *string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time, limit *int, offset *int) ([]model.SurveyResponse, error)
 	CreateSurveyResponse(surveyResponse model.SurveyResponse) (*model.SurveyResponse, error)
+	GetSurveyResponseByIdempotencyKey(orgID string, appID string, userID string, surveyID string, key string) (*model.SurveyResponse, error)
+	CountSurveyResponses(orgID string, appID string, userID string, surveyID string, since *time.Time) (int64, error)
+	GetSurveyRespondents(orgID string, appID string, surveyID string, since *time.Time) ([]string, error)
+	StreamSurveyResponses(orgID string, appID string, surveyID string, startDate *time.Time, endDate *time.Time, handle func(surveyResponse model.SurveyResponse) error) error
+	GetDataKeys(orgID string) ([]model.DataKey, error)
+	RotateDataKey(orgID string) (*model.DataKey, error)
+	GetDataKeysToReencrypt() ([]model.DataKey, error)
+	SetDataKeyReencrypted(id string, date time.Time) error
+	CountSurveyResponsesToReencrypt(orgID string) (int64, error)
+	ReencryptSurveyResponses(orgID string, limit int) (int64, error)
+
+	CountSurveyResponsesBySurvey(orgID string, appID string, surveyID string) (int64, error)
+	StreamSurveyResponsesByType(surveyTypes []string, startDate *time.Time, endDate *time.Time, after *model.SurveyResponseCursor, handle func(surveyResponse model.SurveyResponse) error) error
+	GetSurveyResponseAggregates(orgID string, appID string, surveyID string, valueKeys []string, startDate *time.Time, endDate *time.Time, interval string) (*model.SurveyResponseAggregates, error)
 	UpdateSurveyResponse(surveyResponse model.SurveyResponse) error
+	GetSurveyResponseDraft(orgID string, appID string, userID string, surveyID string) (*model.SurveyResponse, error)
+	UpdateSurveyResponseDraft(surveyResponse model.SurveyResponse) error
+	CompleteSurveyResponseDraft(surveyResponse model.SurveyResponse) error
+	DeleteStaleSurveyResponseDrafts(before time.Time) (int64, error)
 	DeleteSurveyResponse(id string, orgID string, appID string, userID string) error
+	SetSurveyResponsesSurveyDeleted(surveyID string, orgID string, appID string, deletedAt *time.Time) (int64, error)
+	DeleteSurveyResponsesForSurvey(surveyID string, orgID string, appID string, limit int) (int64, error)
+	ArchiveSurveyResponses(surveyID string, orgID string, appID string, archivedAt time.Time, limit int) (int64, error)
 	DeleteSurveyResponses(orgID string, appID string, userID string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time) error
 	DeleteSurveyResponsesWithIDs(orgID string, appID string, accountsIDs []string) error
 
-	GetSurveysAndSurveyResponses(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, public *bool, archived *bool, limit *int, offset *int, userID *string, filter *model.SurveyTimeFilter) ([]model.Survey, []model.SurveyResponse, error)
+	GetSurveysAndSurveyResponses(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, public *bool, archived *bool, limit *int, offset *int, userID *string, audience *model.SurveyAudienceUser, filter *model.SurveyTimeFilter, includeDrafts bool) ([]model.Survey, []model.SurveyResponse, error)
 
 	GetAlertContacts(orgID string, appID string) ([]model.AlertContact, error)
 	GetAlertContact(id string, orgID string, appID string) (*model.AlertContact, error)
@@ -71,7 +129,18 @@ type Notifications interface {
 	SendMail(toEmail string, subject string, body string)
 }
 
+// Webhooks is the interface for posting survey alerts to webhook endpoints
+type Webhooks interface {
+	Post(url string, body []byte, headers map[string]string) error
+}
+
+// SMS is the interface for sending text messages through the configured SMS provider
+type SMS interface {
+	SendSMS(toPhone string, body string) error
+}
+
 // Calendar is the interface for accessing the Calendar BB
 type Calendar interface {
 	GetEventUsers(orgID string, appID string, eventID string, users []calendar.User, registered *bool, role string, attended *bool) ([]calendar.EventPerson, error)
+	GetEvent(orgID string, appID string, eventID string) (*calendar.Event, error)
 }
@@ -17,6 +17,166 @@ type Storage struct {
 	mock.Mock
 }
 
+// ArchiveSurveyResponses provides a mock function with given fields: surveyID, orgID, appID, archivedAt, limit
+func (_m *Storage) ArchiveSurveyResponses(surveyID string, orgID string, appID string, archivedAt time.Time, limit int) (int64, error) {
+	ret := _m.Called(surveyID, orgID, appID, archivedAt, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveSurveyResponses")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Time, int) (int64, error)); ok {
+		return rf(surveyID, orgID, appID, archivedAt, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Time, int) int64); ok {
+		r0 = rf(surveyID, orgID, appID, archivedAt, limit)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, time.Time, int) error); ok {
+		r1 = rf(surveyID, orgID, appID, archivedAt, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimEventSurveySubscription provides a mock function with given fields: now, staleBefore
+func (_m *Storage) ClaimEventSurveySubscription(now time.Time, staleBefore time.Time) (*model.EventSurveySubscription, error) {
+	ret := _m.Called(now, staleBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimEventSurveySubscription")
+	}
+
+	var r0 *model.EventSurveySubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, time.Time) (*model.EventSurveySubscription, error)); ok {
+		return rf(now, staleBefore)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, time.Time) *model.EventSurveySubscription); ok {
+		r0 = rf(now, staleBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.EventSurveySubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, time.Time) error); ok {
+		r1 = rf(now, staleBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompleteSurveyResponseDraft provides a mock function with given fields: surveyResponse
+func (_m *Storage) CompleteSurveyResponseDraft(surveyResponse model.SurveyResponse) error {
+	ret := _m.Called(surveyResponse)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteSurveyResponseDraft")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.SurveyResponse) error); ok {
+		r0 = rf(surveyResponse)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CountSurveyResponses provides a mock function with given fields: orgID, appID, userID, surveyID, since
+func (_m *Storage) CountSurveyResponses(orgID string, appID string, userID string, surveyID string, since *time.Time) (int64, error) {
+	ret := _m.Called(orgID, appID, userID, surveyID, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountSurveyResponses")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, *time.Time) (int64, error)); ok {
+		return rf(orgID, appID, userID, surveyID, since)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, *time.Time) int64); ok {
+		r0 = rf(orgID, appID, userID, surveyID, since)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, *time.Time) error); ok {
+		r1 = rf(orgID, appID, userID, surveyID, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountSurveyResponsesBySurvey provides a mock function with given fields: orgID, appID, surveyID
+func (_m *Storage) CountSurveyResponsesBySurvey(orgID string, appID string, surveyID string) (int64, error) {
+	ret := _m.Called(orgID, appID, surveyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountSurveyResponsesBySurvey")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (int64, error)); ok {
+		return rf(orgID, appID, surveyID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) int64); ok {
+		r0 = rf(orgID, appID, surveyID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(orgID, appID, surveyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountSurveyResponsesToReencrypt provides a mock function with given fields: orgID
+func (_m *Storage) CountSurveyResponsesToReencrypt(orgID string) (int64, error) {
+	ret := _m.Called(orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountSurveyResponsesToReencrypt")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(orgID)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(orgID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateAlertContact provides a mock function with given fields: alertContact
 func (_m *Storage) CreateAlertContact(alertContact model.AlertContact) (*model.AlertContact, error) {
 	ret := _m.Called(alertContact)
@@ -143,17 +303,99 @@ func (_m *Storage) DeleteConfig(id string) error {
 	return r0
 }
 
-// DeleteSurvey provides a mock function with given fields: id, orgID, appID, creatorID, admin
-func (_m *Storage) DeleteSurvey(id string, orgID string, appID string, creatorID string, admin bool) error {
-	ret := _m.Called(id, orgID, appID, creatorID, admin)
+// DeleteEventSurveySubscription provides a mock function with given fields: calendarEventID, orgID, appID
+func (_m *Storage) DeleteEventSurveySubscription(calendarEventID string, orgID string, appID string) error {
+	ret := _m.Called(calendarEventID, orgID, appID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEventSurveySubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(calendarEventID, orgID, appID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteStaleSurveyResponseDrafts provides a mock function with given fields: before
+func (_m *Storage) DeleteStaleSurveyResponseDrafts(before time.Time) (int64, error) {
+	ret := _m.Called(before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteStaleSurveyResponseDrafts")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteSurvey provides a mock function with given fields: id, orgID, appID, creatorID, admin, responsesPolicy
+func (_m *Storage) DeleteSurvey(id string, orgID string, appID string, creatorID string, admin bool, responsesPolicy string) error {
+	ret := _m.Called(id, orgID, appID, creatorID, admin, responsesPolicy)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteSurvey")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string, string, bool) error); ok {
-		r0 = rf(id, orgID, appID, creatorID, admin)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, bool, string) error); ok {
+		r0 = rf(id, orgID, appID, creatorID, admin, responsesPolicy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSurveyReminderMute provides a mock function with given fields: orgID, appID, userID, surveyID
+func (_m *Storage) DeleteSurveyReminderMute(orgID string, appID string, userID string, surveyID string) error {
+	ret := _m.Called(orgID, appID, userID, surveyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSurveyReminderMute")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) error); ok {
+		r0 = rf(orgID, appID, userID, surveyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSurveyRemindersWithIDs provides a mock function with given fields: orgID, appID, accountsIDs
+func (_m *Storage) DeleteSurveyRemindersWithIDs(orgID string, appID string, accountsIDs []string) error {
+	ret := _m.Called(orgID, appID, accountsIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSurveyRemindersWithIDs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, []string) error); ok {
+		r0 = rf(orgID, appID, accountsIDs)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -197,6 +439,34 @@ func (_m *Storage) DeleteSurveyResponses(orgID string, appID string, userID stri
 	return r0
 }
 
+// DeleteSurveyResponsesForSurvey provides a mock function with given fields: surveyID, orgID, appID, limit
+func (_m *Storage) DeleteSurveyResponsesForSurvey(surveyID string, orgID string, appID string, limit int) (int64, error) {
+	ret := _m.Called(surveyID, orgID, appID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSurveyResponsesForSurvey")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, int) (int64, error)); ok {
+		return rf(surveyID, orgID, appID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, int) int64); ok {
+		r0 = rf(surveyID, orgID, appID, limit)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, int) error); ok {
+		r1 = rf(surveyID, orgID, appID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteSurveyResponsesWithIDs provides a mock function with given fields: orgID, appID, accountsIDs
 func (_m *Storage) DeleteSurveyResponsesWithIDs(orgID string, appID string, accountsIDs []string) error {
 	ret := _m.Called(orgID, appID, accountsIDs)
@@ -215,6 +485,24 @@ func (_m *Storage) DeleteSurveyResponsesWithIDs(orgID string, appID string, acco
 	return r0
 }
 
+// DeleteSurveyRevisionsWithIDs provides a mock function with given fields: orgID, appID, accountsIDs
+func (_m *Storage) DeleteSurveyRevisionsWithIDs(orgID string, appID string, accountsIDs []string) error {
+	ret := _m.Called(orgID, appID, accountsIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSurveyRevisionsWithIDs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, []string) error); ok {
+		r0 = rf(orgID, appID, accountsIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteSurveysWithIDs provides a mock function with given fields: orgID, appID, accountsIDs
 func (_m *Storage) DeleteSurveysWithIDs(orgID string, appID string, accountsIDs []string) error {
 	ret := _m.Called(orgID, appID, accountsIDs)
@@ -413,29 +701,29 @@ func (_m *Storage) GetAlertContactsByKey(key string, orgID string, appID string)
 	return r0, r1
 }
 
-// GetSurvey provides a mock function with given fields: id, orgID, appID
-func (_m *Storage) GetSurvey(id string, orgID string, appID string) (*model.Survey, error) {
-	ret := _m.Called(id, orgID, appID)
+// GetAuditEvents provides a mock function with given fields: orgID, appID, filter
+func (_m *Storage) GetAuditEvents(orgID string, appID string, filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	ret := _m.Called(orgID, appID, filter)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetSurvey")
+		panic("no return value specified for GetAuditEvents")
 	}
 
-	var r0 *model.Survey
+	var r0 []model.AuditEvent
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, string) (*model.Survey, error)); ok {
-		return rf(id, orgID, appID)
+	if rf, ok := ret.Get(0).(func(string, string, model.AuditEventFilter) ([]model.AuditEvent, error)); ok {
+		return rf(orgID, appID, filter)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, string) *model.Survey); ok {
-		r0 = rf(id, orgID, appID)
+	if rf, ok := ret.Get(0).(func(string, string, model.AuditEventFilter) []model.AuditEvent); ok {
+		r0 = rf(orgID, appID, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Survey)
+			r0 = ret.Get(0).([]model.AuditEvent)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
-		r1 = rf(id, orgID, appID)
+	if rf, ok := ret.Get(1).(func(string, string, model.AuditEventFilter) error); ok {
+		r1 = rf(orgID, appID, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -443,29 +731,29 @@ func (_m *Storage) GetSurvey(id string, orgID string, appID string) (*model.Surv
 	return r0, r1
 }
 
-// GetSurveyResponse provides a mock function with given fields: id, orgID, appID, userID
-func (_m *Storage) GetSurveyResponse(id string, orgID string, appID string, userID string) (*model.SurveyResponse, error) {
-	ret := _m.Called(id, orgID, appID, userID)
+// GetDataKeys provides a mock function with given fields: orgID
+func (_m *Storage) GetDataKeys(orgID string) ([]model.DataKey, error) {
+	ret := _m.Called(orgID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetSurveyResponse")
+		panic("no return value specified for GetDataKeys")
 	}
 
-	var r0 *model.SurveyResponse
+	var r0 []model.DataKey
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, string, string) (*model.SurveyResponse, error)); ok {
-		return rf(id, orgID, appID, userID)
+	if rf, ok := ret.Get(0).(func(string) ([]model.DataKey, error)); ok {
+		return rf(orgID)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, string, string) *model.SurveyResponse); ok {
-		r0 = rf(id, orgID, appID, userID)
+	if rf, ok := ret.Get(0).(func(string) []model.DataKey); ok {
+		r0 = rf(orgID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.SurveyResponse)
+			r0 = ret.Get(0).([]model.DataKey)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
-		r1 = rf(id, orgID, appID, userID)
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(orgID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -473,29 +761,29 @@ func (_m *Storage) GetSurveyResponse(id string, orgID string, appID string, user
 	return r0, r1
 }
 
-// GetSurveyResponses provides a mock function with given fields: orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset
-func (_m *Storage) GetSurveyResponses(orgID *string, appID *string, userID *string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time, limit *int, offset *int) ([]model.SurveyResponse, error) {
-	ret := _m.Called(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset)
+// GetDataKeysToReencrypt provides a mock function with no fields
+func (_m *Storage) GetDataKeysToReencrypt() ([]model.DataKey, error) {
+	ret := _m.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetSurveyResponses")
+		panic("no return value specified for GetDataKeysToReencrypt")
 	}
 
-	var r0 []model.SurveyResponse
+	var r0 []model.DataKey
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*string, *string, *string, []string, []string, *time.Time, *time.Time, *int, *int) ([]model.SurveyResponse, error)); ok {
-		return rf(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset)
+	if rf, ok := ret.Get(0).(func() ([]model.DataKey, error)); ok {
+		return rf()
 	}
-	if rf, ok := ret.Get(0).(func(*string, *string, *string, []string, []string, *time.Time, *time.Time, *int, *int) []model.SurveyResponse); ok {
-		r0 = rf(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset)
+	if rf, ok := ret.Get(0).(func() []model.DataKey); ok {
+		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.SurveyResponse)
+			r0 = ret.Get(0).([]model.DataKey)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*string, *string, *string, []string, []string, *time.Time, *time.Time, *int, *int) error); ok {
-		r1 = rf(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset)
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -503,29 +791,29 @@ func (_m *Storage) GetSurveyResponses(orgID *string, appID *string, userID *stri
 	return r0, r1
 }
 
-// GetSurveys provides a mock function with given fields: orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed
-func (_m *Storage) GetSurveys(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, error) {
-	ret := _m.Called(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+// GetDeletedSurvey provides a mock function with given fields: id, orgID, appID
+func (_m *Storage) GetDeletedSurvey(id string, orgID string, appID string) (*model.Survey, error) {
+	ret := _m.Called(id, orgID, appID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetSurveys")
+		panic("no return value specified for GetDeletedSurvey")
 	}
 
-	var r0 []model.Survey
+	var r0 *model.Survey
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, *string, []string, []string, string, *int, *int, *model.SurveyTimeFilter, *bool, *bool, *bool) ([]model.Survey, error)); ok {
-		return rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+	if rf, ok := ret.Get(0).(func(string, string, string) (*model.Survey, error)); ok {
+		return rf(id, orgID, appID)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, *string, []string, []string, string, *int, *int, *model.SurveyTimeFilter, *bool, *bool, *bool) []model.Survey); ok {
-		r0 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+	if rf, ok := ret.Get(0).(func(string, string, string) *model.Survey); ok {
+		r0 = rf(id, orgID, appID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Survey)
+			r0 = ret.Get(0).(*model.Survey)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, *string, []string, []string, string, *int, *int, *model.SurveyTimeFilter, *bool, *bool, *bool) error); ok {
-		r1 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(id, orgID, appID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -533,68 +821,911 @@ func (_m *Storage) GetSurveys(orgID string, appID string, creatorID *string, sur
 	return r0, r1
 }
 
-// GetSurveysAndSurveyResponses provides a mock function with given fields: orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, filter
-func (_m *Storage) GetSurveysAndSurveyResponses(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, public *bool, archived *bool, limit *int, offset *int, userID *string, filter *model.SurveyTimeFilter) ([]model.Survey, []model.SurveyResponse, error) {
-	ret := _m.Called(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, filter)
+// GetDeletedSurveys provides a mock function with given fields: orgID, appID, limit, offset
+func (_m *Storage) GetDeletedSurveys(orgID string, appID string, limit *int, offset *int) ([]model.Survey, error) {
+	ret := _m.Called(orgID, appID, limit, offset)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetSurveysAndSurveyResponses")
+		panic("no return value specified for GetDeletedSurveys")
 	}
 
 	var r0 []model.Survey
-	var r1 []model.SurveyResponse
-	var r2 error
-	if rf, ok := ret.Get(0).(func(string, string, *string, []string, []string, string, *bool, *bool, *int, *int, *string, *model.SurveyTimeFilter) ([]model.Survey, []model.SurveyResponse, error)); ok {
-		return rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, filter)
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, *int, *int) ([]model.Survey, error)); ok {
+		return rf(orgID, appID, limit, offset)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, *string, []string, []string, string, *bool, *bool, *int, *int, *string, *model.SurveyTimeFilter) []model.Survey); ok {
-		r0 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, filter)
+	if rf, ok := ret.Get(0).(func(string, string, *int, *int) []model.Survey); ok {
+		r0 = rf(orgID, appID, limit, offset)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.Survey)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, *string, []string, []string, string, *bool, *bool, *int, *int, *string, *model.SurveyTimeFilter) []model.SurveyResponse); ok {
-		r1 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, filter)
+	if rf, ok := ret.Get(1).(func(string, string, *int, *int) error); ok {
+		r1 = rf(orgID, appID, limit, offset)
 	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).([]model.SurveyResponse)
-		}
+		r1 = ret.Error(1)
 	}
 
-	if rf, ok := ret.Get(2).(func(string, string, *string, []string, []string, string, *bool, *bool, *int, *int, *string, *model.SurveyTimeFilter) error); ok {
-		r2 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, filter)
-	} else {
-		r2 = ret.Error(2)
-	}
+	return r0, r1
+}
+
+// GetEventSurveySubscription provides a mock function with given fields: calendarEventID, orgID, appID
+func (_m *Storage) GetEventSurveySubscription(calendarEventID string, orgID string, appID string) (*model.EventSurveySubscription, error) {
+	ret := _m.Called(calendarEventID, orgID, appID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEventSurveySubscription")
+	}
+
+	var r0 *model.EventSurveySubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (*model.EventSurveySubscription, error)); ok {
+		return rf(calendarEventID, orgID, appID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) *model.EventSurveySubscription); ok {
+		r0 = rf(calendarEventID, orgID, appID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.EventSurveySubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(calendarEventID, orgID, appID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEventSurveySubscriptions provides a mock function with given fields: orgID, appID, status, limit, offset
+func (_m *Storage) GetEventSurveySubscriptions(orgID string, appID string, status *string, limit *int, offset *int) ([]model.EventSurveySubscription, error) {
+	ret := _m.Called(orgID, appID, status, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEventSurveySubscriptions")
+	}
+
+	var r0 []model.EventSurveySubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, *string, *int, *int) ([]model.EventSurveySubscription, error)); ok {
+		return rf(orgID, appID, status, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, *string, *int, *int) []model.EventSurveySubscription); ok {
+		r0 = rf(orgID, appID, status, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.EventSurveySubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, *string, *int, *int) error); ok {
+		r1 = rf(orgID, appID, status, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPurgeableSurveys provides a mock function with given fields: before
+func (_m *Storage) GetPurgeableSurveys(before time.Time) ([]model.Survey, error) {
+	ret := _m.Called(before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPurgeableSurveys")
+	}
+
+	var r0 []model.Survey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]model.Survey, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) []model.Survey); ok {
+		r0 = rf(before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Survey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecurringSurveys provides a mock function with no fields
+func (_m *Storage) GetRecurringSurveys() ([]model.Survey, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecurringSurveys")
+	}
+
+	var r0 []model.Survey
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.Survey, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Survey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Survey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurvey provides a mock function with given fields: id, orgID, appID
+func (_m *Storage) GetSurvey(id string, orgID string, appID string) (*model.Survey, error) {
+	ret := _m.Called(id, orgID, appID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurvey")
+	}
+
+	var r0 *model.Survey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (*model.Survey, error)); ok {
+		return rf(id, orgID, appID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) *model.Survey); ok {
+		r0 = rf(id, orgID, appID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Survey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(id, orgID, appID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyAudiences provides a mock function with given fields: orgID, appID, surveyIDs, calendarEventID
+func (_m *Storage) GetSurveyAudiences(orgID string, appID string, surveyIDs []string, calendarEventID string) ([]model.Survey, error) {
+	ret := _m.Called(orgID, appID, surveyIDs, calendarEventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyAudiences")
+	}
+
+	var r0 []model.Survey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, []string, string) ([]model.Survey, error)); ok {
+		return rf(orgID, appID, surveyIDs, calendarEventID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, []string, string) []model.Survey); ok {
+		r0 = rf(orgID, appID, surveyIDs, calendarEventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Survey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, []string, string) error); ok {
+		r1 = rf(orgID, appID, surveyIDs, calendarEventID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyReminderMutes provides a mock function with given fields: orgID, appID, userIDs, surveyID
+func (_m *Storage) GetSurveyReminderMutes(orgID string, appID string, userIDs []string, surveyID *string) ([]model.SurveyReminderMute, error) {
+	ret := _m.Called(orgID, appID, userIDs, surveyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyReminderMutes")
+	}
+
+	var r0 []model.SurveyReminderMute
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, []string, *string) ([]model.SurveyReminderMute, error)); ok {
+		return rf(orgID, appID, userIDs, surveyID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, []string, *string) []model.SurveyReminderMute); ok {
+		r0 = rf(orgID, appID, userIDs, surveyID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SurveyReminderMute)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, []string, *string) error); ok {
+		r1 = rf(orgID, appID, userIDs, surveyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyReminders provides a mock function with given fields: surveyID, orgID, appID, limit, offset
+func (_m *Storage) GetSurveyReminders(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyReminder, error) {
+	ret := _m.Called(surveyID, orgID, appID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyReminders")
+	}
+
+	var r0 []model.SurveyReminder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, *int, *int) ([]model.SurveyReminder, error)); ok {
+		return rf(surveyID, orgID, appID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, *int, *int) []model.SurveyReminder); ok {
+		r0 = rf(surveyID, orgID, appID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SurveyReminder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, *int, *int) error); ok {
+		r1 = rf(surveyID, orgID, appID, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyRespondents provides a mock function with given fields: orgID, appID, surveyID, since
+func (_m *Storage) GetSurveyRespondents(orgID string, appID string, surveyID string, since *time.Time) ([]string, error) {
+	ret := _m.Called(orgID, appID, surveyID, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyRespondents")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, *time.Time) ([]string, error)); ok {
+		return rf(orgID, appID, surveyID, since)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, *time.Time) []string); ok {
+		r0 = rf(orgID, appID, surveyID, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, *time.Time) error); ok {
+		r1 = rf(orgID, appID, surveyID, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyResponse provides a mock function with given fields: id, orgID, appID, userID
+func (_m *Storage) GetSurveyResponse(id string, orgID string, appID string, userID string) (*model.SurveyResponse, error) {
+	ret := _m.Called(id, orgID, appID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyResponse")
+	}
+
+	var r0 *model.SurveyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (*model.SurveyResponse, error)); ok {
+		return rf(id, orgID, appID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *model.SurveyResponse); ok {
+		r0 = rf(id, orgID, appID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SurveyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(id, orgID, appID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyResponseAggregates provides a mock function with given fields: orgID, appID, surveyID, valueKeys, startDate, endDate, interval
+func (_m *Storage) GetSurveyResponseAggregates(orgID string, appID string, surveyID string, valueKeys []string, startDate *time.Time, endDate *time.Time, interval string) (*model.SurveyResponseAggregates, error) {
+	ret := _m.Called(orgID, appID, surveyID, valueKeys, startDate, endDate, interval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyResponseAggregates")
+	}
+
+	var r0 *model.SurveyResponseAggregates
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, []string, *time.Time, *time.Time, string) (*model.SurveyResponseAggregates, error)); ok {
+		return rf(orgID, appID, surveyID, valueKeys, startDate, endDate, interval)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, []string, *time.Time, *time.Time, string) *model.SurveyResponseAggregates); ok {
+		r0 = rf(orgID, appID, surveyID, valueKeys, startDate, endDate, interval)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SurveyResponseAggregates)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, []string, *time.Time, *time.Time, string) error); ok {
+		r1 = rf(orgID, appID, surveyID, valueKeys, startDate, endDate, interval)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyResponseByIdempotencyKey provides a mock function with given fields: orgID, appID, userID, surveyID, key
+func (_m *Storage) GetSurveyResponseByIdempotencyKey(orgID string, appID string, userID string, surveyID string, key string) (*model.SurveyResponse, error) {
+	ret := _m.Called(orgID, appID, userID, surveyID, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyResponseByIdempotencyKey")
+	}
+
+	var r0 *model.SurveyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string) (*model.SurveyResponse, error)); ok {
+		return rf(orgID, appID, userID, surveyID, key)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string) *model.SurveyResponse); ok {
+		r0 = rf(orgID, appID, userID, surveyID, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SurveyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, string) error); ok {
+		r1 = rf(orgID, appID, userID, surveyID, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyResponseDraft provides a mock function with given fields: orgID, appID, userID, surveyID
+func (_m *Storage) GetSurveyResponseDraft(orgID string, appID string, userID string, surveyID string) (*model.SurveyResponse, error) {
+	ret := _m.Called(orgID, appID, userID, surveyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyResponseDraft")
+	}
+
+	var r0 *model.SurveyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (*model.SurveyResponse, error)); ok {
+		return rf(orgID, appID, userID, surveyID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *model.SurveyResponse); ok {
+		r0 = rf(orgID, appID, userID, surveyID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SurveyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(orgID, appID, userID, surveyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyResponses provides a mock function with given fields: orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset
+func (_m *Storage) GetSurveyResponses(orgID *string, appID *string, userID *string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time, limit *int, offset *int) ([]model.SurveyResponse, error) {
+	ret := _m.Called(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyResponses")
+	}
+
+	var r0 []model.SurveyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*string, *string, *string, []string, []string, *time.Time, *time.Time, *int, *int) ([]model.SurveyResponse, error)); ok {
+		return rf(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(*string, *string, *string, []string, []string, *time.Time, *time.Time, *int, *int) []model.SurveyResponse); ok {
+		r0 = rf(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SurveyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*string, *string, *string, []string, []string, *time.Time, *time.Time, *int, *int) error); ok {
+		r1 = rf(orgID, appID, userID, surveyIDs, surveyTypes, startDate, endDate, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyRevision provides a mock function with given fields: surveyID, orgID, appID, revision
+func (_m *Storage) GetSurveyRevision(surveyID string, orgID string, appID string, revision int) (*model.SurveyRevision, error) {
+	ret := _m.Called(surveyID, orgID, appID, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyRevision")
+	}
+
+	var r0 *model.SurveyRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, int) (*model.SurveyRevision, error)); ok {
+		return rf(surveyID, orgID, appID, revision)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, int) *model.SurveyRevision); ok {
+		r0 = rf(surveyID, orgID, appID, revision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SurveyRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, int) error); ok {
+		r1 = rf(surveyID, orgID, appID, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyRevisions provides a mock function with given fields: surveyID, orgID, appID, limit, offset
+func (_m *Storage) GetSurveyRevisions(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyRevision, error) {
+	ret := _m.Called(surveyID, orgID, appID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyRevisions")
+	}
+
+	var r0 []model.SurveyRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, *int, *int) ([]model.SurveyRevision, error)); ok {
+		return rf(surveyID, orgID, appID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, *int, *int) []model.SurveyRevision); ok {
+		r0 = rf(surveyID, orgID, appID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SurveyRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, *int, *int) error); ok {
+		r1 = rf(surveyID, orgID, appID, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyTemplates provides a mock function with given fields: orgID, appID, filter
+func (_m *Storage) GetSurveyTemplates(orgID string, appID string, filter model.SurveyTemplateFilter) ([]model.Survey, error) {
+	ret := _m.Called(orgID, appID, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyTemplates")
+	}
+
+	var r0 []model.Survey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, model.SurveyTemplateFilter) ([]model.Survey, error)); ok {
+		return rf(orgID, appID, filter)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, model.SurveyTemplateFilter) []model.Survey); ok {
+		r0 = rf(orgID, appID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Survey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, model.SurveyTemplateFilter) error); ok {
+		r1 = rf(orgID, appID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveys provides a mock function with given fields: orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed
+func (_m *Storage) GetSurveys(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, limit *int, offset *int, filter *model.SurveyTimeFilter, public *bool, archived *bool, completed *bool) ([]model.Survey, error) {
+	ret := _m.Called(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveys")
+	}
+
+	var r0 []model.Survey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, *string, []string, []string, string, *int, *int, *model.SurveyTimeFilter, *bool, *bool, *bool) ([]model.Survey, error)); ok {
+		return rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, *string, []string, []string, string, *int, *int, *model.SurveyTimeFilter, *bool, *bool, *bool) []model.Survey); ok {
+		r0 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Survey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, *string, []string, []string, string, *int, *int, *model.SurveyTimeFilter, *bool, *bool, *bool) error); ok {
+		r1 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, limit, offset, filter, public, archived, completed)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveysAndSurveyResponses provides a mock function with given fields: orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, audience, filter, includeDrafts
+func (_m *Storage) GetSurveysAndSurveyResponses(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, public *bool, archived *bool, limit *int, offset *int, userID *string, audience *model.SurveyAudienceUser, filter *model.SurveyTimeFilter, includeDrafts bool) ([]model.Survey, []model.SurveyResponse, error) {
+	ret := _m.Called(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, audience, filter, includeDrafts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveysAndSurveyResponses")
+	}
+
+	var r0 []model.Survey
+	var r1 []model.SurveyResponse
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, string, *string, []string, []string, string, *bool, *bool, *int, *int, *string, *model.SurveyAudienceUser, *model.SurveyTimeFilter, bool) ([]model.Survey, []model.SurveyResponse, error)); ok {
+		return rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, audience, filter, includeDrafts)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, *string, []string, []string, string, *bool, *bool, *int, *int, *string, *model.SurveyAudienceUser, *model.SurveyTimeFilter, bool) []model.Survey); ok {
+		r0 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, audience, filter, includeDrafts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Survey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, *string, []string, []string, string, *bool, *bool, *int, *int, *string, *model.SurveyAudienceUser, *model.SurveyTimeFilter, bool) []model.SurveyResponse); ok {
+		r1 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, audience, filter, includeDrafts)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]model.SurveyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string, *string, []string, []string, string, *bool, *bool, *int, *int, *string, *model.SurveyAudienceUser, *model.SurveyTimeFilter, bool) error); ok {
+		r2 = rf(orgID, appID, creatorID, surveyIDs, surveyTypes, calendarEventID, public, archived, limit, offset, userID, audience, filter, includeDrafts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetSurveysForReminders provides a mock function with given fields: now
+func (_m *Storage) GetSurveysForReminders(now time.Time) ([]model.Survey, error) {
+	ret := _m.Called(now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveysForReminders")
+	}
+
+	var r0 []model.Survey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]model.Survey, error)); ok {
+		return rf(now)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) []model.Survey); ok {
+		r0 = rf(now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Survey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveysLight provides a mock function with given fields: orgID, appID, creatorID
+func (_m *Storage) GetSurveysLight(orgID string, appID string, creatorID *string) ([]model.Survey, error) {
+	ret := _m.Called(orgID, appID, creatorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveysLight")
+	}
+
+	var r0 []model.Survey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, *string) ([]model.Survey, error)); ok {
+		return rf(orgID, appID, creatorID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, *string) []model.Survey); ok {
+		r0 = rf(orgID, appID, creatorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Survey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, *string) error); ok {
+		r1 = rf(orgID, appID, creatorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertAuditEvent provides a mock function with given fields: auditEvent
+func (_m *Storage) InsertAuditEvent(auditEvent model.AuditEvent) error {
+	ret := _m.Called(auditEvent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertAuditEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.AuditEvent) error); ok {
+		r0 = rf(auditEvent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertConfig provides a mock function with given fields: config
+func (_m *Storage) InsertConfig(config model.Config) error {
+	ret := _m.Called(config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertConfig")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.Config) error); ok {
+		r0 = rf(config)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertEventSurveySubscription provides a mock function with given fields: subscription
+func (_m *Storage) InsertEventSurveySubscription(subscription model.EventSurveySubscription) error {
+	ret := _m.Called(subscription)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertEventSurveySubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.EventSurveySubscription) error); ok {
+		r0 = rf(subscription)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertSurveyReminder provides a mock function with given fields: reminder
+func (_m *Storage) InsertSurveyReminder(reminder model.SurveyReminder) error {
+	ret := _m.Called(reminder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertSurveyReminder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.SurveyReminder) error); ok {
+		r0 = rf(reminder)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertSurveyReminderMute provides a mock function with given fields: mute
+func (_m *Storage) InsertSurveyReminderMute(mute model.SurveyReminderMute) error {
+	ret := _m.Called(mute)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertSurveyReminderMute")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.SurveyReminderMute) error); ok {
+		r0 = rf(mute)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertSurveyRevision provides a mock function with given fields: surveyRevision
+func (_m *Storage) InsertSurveyRevision(surveyRevision model.SurveyRevision) error {
+	ret := _m.Called(surveyRevision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertSurveyRevision")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.SurveyRevision) error); ok {
+		r0 = rf(surveyRevision)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PerformTransaction provides a mock function with given fields: _a0
+func (_m *Storage) PerformTransaction(_a0 func(interfaces.Storage) error) error {
+	ret := _m.Called(_a0)
 
-	return r0, r1, r2
+	if len(ret) == 0 {
+		panic("no return value specified for PerformTransaction")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(func(interfaces.Storage) error) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
-// GetSurveysLight provides a mock function with given fields: orgID, appID, creatorID
-func (_m *Storage) GetSurveysLight(orgID string, appID string, creatorID *string) ([]model.Survey, error) {
-	ret := _m.Called(orgID, appID, creatorID)
+// PurgeDeletedSurvey provides a mock function with given fields: id, orgID, appID
+func (_m *Storage) PurgeDeletedSurvey(id string, orgID string, appID string) error {
+	ret := _m.Called(id, orgID, appID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetSurveysLight")
+		panic("no return value specified for PurgeDeletedSurvey")
 	}
 
-	var r0 []model.Survey
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(id, orgID, appID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReencryptSurveyResponses provides a mock function with given fields: orgID, limit
+func (_m *Storage) ReencryptSurveyResponses(orgID string, limit int) (int64, error) {
+	ret := _m.Called(orgID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReencryptSurveyResponses")
+	}
+
+	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, *string) ([]model.Survey, error)); ok {
-		return rf(orgID, appID, creatorID)
+	if rf, ok := ret.Get(0).(func(string, int) (int64, error)); ok {
+		return rf(orgID, limit)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, *string) []model.Survey); ok {
-		r0 = rf(orgID, appID, creatorID)
+	if rf, ok := ret.Get(0).(func(string, int) int64); ok {
+		r0 = rf(orgID, limit)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(orgID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterStorageListener provides a mock function with given fields: listener
+func (_m *Storage) RegisterStorageListener(listener interfaces.StorageListener) {
+	_m.Called(listener)
+}
+
+// RescheduleEventSurveySubscription provides a mock function with given fields: id, eventEndDate
+func (_m *Storage) RescheduleEventSurveySubscription(id string, eventEndDate time.Time) error {
+	ret := _m.Called(id, eventEndDate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RescheduleEventSurveySubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(id, eventEndDate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreSurvey provides a mock function with given fields: id, orgID, appID
+func (_m *Storage) RestoreSurvey(id string, orgID string, appID string) error {
+	ret := _m.Called(id, orgID, appID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreSurvey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(id, orgID, appID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateDataKey provides a mock function with given fields: orgID
+func (_m *Storage) RotateDataKey(orgID string) (*model.DataKey, error) {
+	ret := _m.Called(orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateDataKey")
+	}
+
+	var r0 *model.DataKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.DataKey, error)); ok {
+		return rf(orgID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.DataKey); ok {
+		r0 = rf(orgID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Survey)
+			r0 = ret.Get(0).(*model.DataKey)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, *string) error); ok {
-		r1 = rf(orgID, appID, creatorID)
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(orgID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -602,17 +1733,17 @@ func (_m *Storage) GetSurveysLight(orgID string, appID string, creatorID *string
 	return r0, r1
 }
 
-// InsertConfig provides a mock function with given fields: config
-func (_m *Storage) InsertConfig(config model.Config) error {
-	ret := _m.Called(config)
+// SetDataKeyReencrypted provides a mock function with given fields: id, date
+func (_m *Storage) SetDataKeyReencrypted(id string, date time.Time) error {
+	ret := _m.Called(id, date)
 
 	if len(ret) == 0 {
-		panic("no return value specified for InsertConfig")
+		panic("no return value specified for SetDataKeyReencrypted")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(model.Config) error); ok {
-		r0 = rf(config)
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(id, date)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -620,17 +1751,45 @@ func (_m *Storage) InsertConfig(config model.Config) error {
 	return r0
 }
 
-// PerformTransaction provides a mock function with given fields: _a0
-func (_m *Storage) PerformTransaction(_a0 func(interfaces.Storage) error) error {
-	ret := _m.Called(_a0)
+// SetSurveyResponsesSurveyDeleted provides a mock function with given fields: surveyID, orgID, appID, deletedAt
+func (_m *Storage) SetSurveyResponsesSurveyDeleted(surveyID string, orgID string, appID string, deletedAt *time.Time) (int64, error) {
+	ret := _m.Called(surveyID, orgID, appID, deletedAt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for PerformTransaction")
+		panic("no return value specified for SetSurveyResponsesSurveyDeleted")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, *time.Time) (int64, error)); ok {
+		return rf(surveyID, orgID, appID, deletedAt)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, *time.Time) int64); ok {
+		r0 = rf(surveyID, orgID, appID, deletedAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, *time.Time) error); ok {
+		r1 = rf(surveyID, orgID, appID, deletedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StreamSurveyResponses provides a mock function with given fields: orgID, appID, surveyID, startDate, endDate, handle
+func (_m *Storage) StreamSurveyResponses(orgID string, appID string, surveyID string, startDate *time.Time, endDate *time.Time, handle func(model.SurveyResponse) error) error {
+	ret := _m.Called(orgID, appID, surveyID, startDate, endDate, handle)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamSurveyResponses")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(func(interfaces.Storage) error) error); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(string, string, string, *time.Time, *time.Time, func(model.SurveyResponse) error) error); ok {
+		r0 = rf(orgID, appID, surveyID, startDate, endDate, handle)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -638,9 +1797,22 @@ func (_m *Storage) PerformTransaction(_a0 func(interfaces.Storage) error) error
 	return r0
 }
 
-// RegisterStorageListener provides a mock function with given fields: listener
-func (_m *Storage) RegisterStorageListener(listener interfaces.StorageListener) {
-	_m.Called(listener)
+// StreamSurveyResponsesByType provides a mock function with given fields: surveyTypes, startDate, endDate, after, handle
+func (_m *Storage) StreamSurveyResponsesByType(surveyTypes []string, startDate *time.Time, endDate *time.Time, after *model.SurveyResponseCursor, handle func(model.SurveyResponse) error) error {
+	ret := _m.Called(surveyTypes, startDate, endDate, after, handle)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamSurveyResponsesByType")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string, *time.Time, *time.Time, *model.SurveyResponseCursor, func(model.SurveyResponse) error) error); ok {
+		r0 = rf(surveyTypes, startDate, endDate, after, handle)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
 // UpdateAlertContact provides a mock function with given fields: alertContact
@@ -679,6 +1851,42 @@ func (_m *Storage) UpdateConfig(config model.Config) error {
 	return r0
 }
 
+// UpdateEventSurveySubscription provides a mock function with given fields: subscription
+func (_m *Storage) UpdateEventSurveySubscription(subscription model.EventSurveySubscription) error {
+	ret := _m.Called(subscription)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEventSurveySubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.EventSurveySubscription) error); ok {
+		r0 = rf(subscription)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateEventSurveySubscriptionResult provides a mock function with given fields: id, status, surveyID, notified, message
+func (_m *Storage) UpdateEventSurveySubscriptionResult(id string, status string, surveyID *string, notified int, message *string) error {
+	ret := _m.Called(id, status, surveyID, notified, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEventSurveySubscriptionResult")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, *string, int, *string) error); ok {
+		r0 = rf(id, status, surveyID, notified, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateSurvey provides a mock function with given fields: survey, admin
 func (_m *Storage) UpdateSurvey(survey model.Survey, admin bool) error {
 	ret := _m.Called(survey, admin)
@@ -697,6 +1905,62 @@ func (_m *Storage) UpdateSurvey(survey model.Survey, admin bool) error {
 	return r0
 }
 
+// UpdateSurveyRecurrenceReminder provides a mock function with given fields: id, windowStart
+func (_m *Storage) UpdateSurveyRecurrenceReminder(id string, windowStart time.Time) (bool, error) {
+	ret := _m.Called(id, windowStart)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSurveyRecurrenceReminder")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) (bool, error)); ok {
+		return rf(id, windowStart)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Time) bool); ok {
+		r0 = rf(id, windowStart)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(id, windowStart)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateSurveyRecurrenceWindow provides a mock function with given fields: id, windowStart, target
+func (_m *Storage) UpdateSurveyRecurrenceWindow(id string, windowStart *time.Time, target *time.Time) (bool, error) {
+	ret := _m.Called(id, windowStart, target)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSurveyRecurrenceWindow")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, *time.Time, *time.Time) (bool, error)); ok {
+		return rf(id, windowStart, target)
+	}
+	if rf, ok := ret.Get(0).(func(string, *time.Time, *time.Time) bool); ok {
+		r0 = rf(id, windowStart, target)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *time.Time, *time.Time) error); ok {
+		r1 = rf(id, windowStart, target)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateSurveyResponse provides a mock function with given fields: surveyResponse
 func (_m *Storage) UpdateSurveyResponse(surveyResponse model.SurveyResponse) error {
 	ret := _m.Called(surveyResponse)
@@ -715,6 +1979,106 @@ func (_m *Storage) UpdateSurveyResponse(surveyResponse model.SurveyResponse) err
 	return r0
 }
 
+// UpdateSurveyResponseDraft provides a mock function with given fields: surveyResponse
+func (_m *Storage) UpdateSurveyResponseDraft(surveyResponse model.SurveyResponse) error {
+	ret := _m.Called(surveyResponse)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSurveyResponseDraft")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.SurveyResponse) error); ok {
+		r0 = rf(surveyResponse)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSurveyResponseWindowOverride provides a mock function with given fields: id, orgID, appID, override
+func (_m *Storage) UpdateSurveyResponseWindowOverride(id string, orgID string, appID string, override *bool) error {
+	ret := _m.Called(id, orgID, appID, override)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSurveyResponseWindowOverride")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, *bool) error); ok {
+		r0 = rf(id, orgID, appID, override)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSurveyStatus provides a mock function with given fields: id, orgID, appID, status, target
+func (_m *Storage) UpdateSurveyStatus(id string, orgID string, appID string, status string, target string) error {
+	ret := _m.Called(id, orgID, appID, status, target)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSurveyStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string) error); ok {
+		r0 = rf(id, orgID, appID, status, target)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSurveyStatuses provides a mock function with given fields: now
+func (_m *Storage) UpdateSurveyStatuses(now time.Time) (int64, error) {
+	ret := _m.Called(now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSurveyStatuses")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return rf(now)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateSurveyTemplate provides a mock function with given fields: id, orgID, appID, template
+func (_m *Storage) UpdateSurveyTemplate(id string, orgID string, appID string, template *model.SurveyTemplate) error {
+	ret := _m.Called(id, orgID, appID, template)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSurveyTemplate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, *model.SurveyTemplate) error); ok {
+		r0 = rf(id, orgID, appID, template)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewStorage(t interface {
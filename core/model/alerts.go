@@ -25,6 +25,31 @@ const (
 	TypeSurveyAlert logutils.MessageDataType = "survey alert"
 	//TypeAlertContact example type
 	TypeAlertContact logutils.MessageDataType = "alert contact"
+	//TypeAlertDelivery alert delivery type
+	TypeAlertDelivery logutils.MessageDataType = "alert delivery"
+
+	//AlertChannelEmail sends the alert by email to the contact address
+	AlertChannelEmail string = "email"
+	//AlertChannelPush sends the alert as a push notification to the account ID in the contact address or to the topic param
+	AlertChannelPush string = "push"
+	//AlertChannelWebhook posts the alert to the URL in the contact address, signed with the secret param
+	AlertChannelWebhook string = "webhook"
+	//AlertChannelSMS sends the alert as a text message to the phone number in the contact address
+	AlertChannelSMS string = "sms"
+
+	//AlertDeliverySent the alert was delivered to the contact
+	AlertDeliverySent string = "sent"
+	//AlertDeliveryQueued the alert was handed to the Notifications BB for delivery
+	AlertDeliveryQueued string = "queued"
+	//AlertDeliveryFailed the alert could not be delivered to the contact
+	AlertDeliveryFailed string = "failed"
+	//AlertDeliveryInvalid the contact or the alert content is not valid for the channel of the contact
+	AlertDeliveryInvalid string = "invalid"
+
+	//AlertRuleScore matches the responses whose score for a section reaches a threshold
+	AlertRuleScore string = "score"
+	//AlertRuleAnswer matches the responses whose answer to a question is a value
+	AlertRuleAnswer string = "answer"
 )
 
 // SurveyAlert is a survey alert to be sent to notifications BB
@@ -47,3 +72,30 @@ type AlertContact struct {
 	DateCreated time.Time              `json:"date_created" bson:"date_created"`
 	DateUpdated *time.Time             `json:"date_updated" bson:"date_updated"`
 }
+
+// SurveyAlertRule sends an alert to the alert contacts of ContactKey when a response to the survey matches the rule
+type SurveyAlertRule struct {
+	Type       string `json:"type" bson:"type"`
+	ContactKey string `json:"contact_key" bson:"contact_key"`
+
+	// Section is the section whose score is compared with Threshold. The scores of all sections are summed if it is empty.
+	Section   string   `json:"section,omitempty" bson:"section,omitempty"`
+	Threshold *float64 `json:"threshold,omitempty" bson:"threshold,omitempty"`
+
+	// Key is the question whose answer is compared with Value. Answers with several values match if any of them is Value.
+	Key   string      `json:"key,omitempty" bson:"key,omitempty"`
+	Value interface{} `json:"value,omitempty" bson:"value,omitempty"`
+
+	// Subject and Body are text/template templates rendered with the survey, the response and the matched score or answer
+	Subject string `json:"subject" bson:"subject"`
+	Body    string `json:"body" bson:"body"`
+}
+
+// AlertDelivery is the result of sending a survey alert to an alert contact
+type AlertDelivery struct {
+	ContactID string `json:"contact_id"`
+	Type      string `json:"type"`
+	Address   string `json:"address"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
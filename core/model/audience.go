@@ -0,0 +1,96 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"regexp"
+
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	//TypeSurveyAudience survey audience type
+	TypeSurveyAudience logutils.MessageDataType = "survey audience"
+)
+
+// SurveyAudience restricts a survey to the users matching any of its criteria. Surveys without an audience are for all
+// users of the app/org.
+type SurveyAudience struct {
+	AccountIDs []string `json:"account_ids,omitempty" bson:"account_ids,omitempty"`
+	// Permissions matches the users whose token grants any of the permissions. Roles and groups grant their permissions in the token.
+	Permissions []string `json:"permissions,omitempty" bson:"permissions,omitempty"`
+	// ExternalIDPatterns matches the users with an external ID of any of the types (eg. uin) matching the regular expression
+	ExternalIDPatterns map[string]string `json:"external_id_patterns,omitempty" bson:"external_id_patterns,omitempty"`
+	// EventRegistration matches the users registered to the calendar event of the survey
+	EventRegistration bool `json:"event_registration,omitempty" bson:"event_registration,omitempty"`
+}
+
+// SurveyAudienceUser is the identity of a user from the token claims checked against survey audiences
+type SurveyAudienceUser struct {
+	AccountID   string
+	Permissions []string
+	ExternalIDs map[string]string
+	// SurveyIDs are the surveys whose external ID patterns or event registration match the user. They are resolved
+	// before surveys are listed so that the query can match them with the other criteria.
+	SurveyIDs []string
+}
+
+// Empty returns true if the audience has no criteria
+func (a SurveyAudience) Empty() bool {
+	return len(a.AccountIDs) == 0 && len(a.Permissions) == 0 && len(a.ExternalIDPatterns) == 0 && !a.EventRegistration
+}
+
+// Listable returns true if all the users of the audience can be listed. Users matching by permissions or external IDs
+// are only known from their tokens.
+func (a SurveyAudience) Listable() bool {
+	return len(a.Permissions) == 0 && len(a.ExternalIDPatterns) == 0
+}
+
+// MatchesClaims returns true if the user matches the account IDs, permissions or external ID patterns of the audience.
+// Event registration is checked separately.
+func (a SurveyAudience) MatchesClaims(user SurveyAudienceUser) bool {
+	for _, accountID := range a.AccountIDs {
+		if accountID == user.AccountID {
+			return true
+		}
+	}
+	for _, permission := range a.Permissions {
+		for _, granted := range user.Permissions {
+			if permission == granted {
+				return true
+			}
+		}
+	}
+	for idType, pattern := range a.ExternalIDPatterns {
+		externalID, ok := user.ExternalIDs[idType]
+		if !ok || externalID == "" {
+			continue
+		}
+		if matched, err := regexp.MatchString(pattern, externalID); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SurveyAudiencePreview lists the users known to match the audience of a survey
+type SurveyAudiencePreview struct {
+	SurveyID   string          `json:"survey_id"`
+	Audience   *SurveyAudience `json:"audience"`
+	AccountIDs []string        `json:"account_ids"`
+	// Partial is true if the survey is also for users who cannot be listed: all users of surveys without an audience, or
+	// the users matching by permissions or external IDs
+	Partial bool `json:"partial"`
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	//TypeAuditEvent audit event type
+	TypeAuditEvent logutils.MessageDataType = "audit event"
+
+	//AuditEntitySurvey is the entity of audit events on surveys
+	AuditEntitySurvey string = "survey"
+	//AuditEntitySurveyResponse is the entity of audit events on survey responses
+	AuditEntitySurveyResponse string = "survey_response"
+	//AuditEntityAlertContact is the entity of audit events on alert contacts
+	AuditEntityAlertContact string = "alert_contact"
+	//AuditEntityConfig is the entity of audit events on configs
+	AuditEntityConfig string = "config"
+
+	//AuditActionCreate is the action of audit events recording a creation
+	AuditActionCreate string = "create"
+	//AuditActionUpdate is the action of audit events recording an update
+	AuditActionUpdate string = "update"
+	//AuditActionDelete is the action of audit events recording a deletion
+	AuditActionDelete string = "delete"
+)
+
+// AuditEvent records a create, update or delete of an entity
+type AuditEvent struct {
+	ID          string         `json:"id" bson:"_id"`
+	OrgID       string         `json:"org_id" bson:"org_id"`
+	AppID       string         `json:"app_id" bson:"app_id"`
+	Entity      string         `json:"entity" bson:"entity"`
+	EntityID    string         `json:"entity_id" bson:"entity_id"`
+	Action      string         `json:"action" bson:"action"`
+	Actor       AuditActor     `json:"actor" bson:"actor"`
+	RequestID   string         `json:"request_id" bson:"request_id"`
+	Changes     []SurveyChange `json:"changes" bson:"changes"`
+	DateCreated time.Time      `json:"date_created" bson:"date_created"`
+}
+
+// AuditActor is the account which made a change recorded in the audit log
+type AuditActor struct {
+	AccountID string `json:"account_id" bson:"account_id"`
+	AppID     string `json:"app_id" bson:"app_id"`
+	OrgID     string `json:"org_id" bson:"org_id"`
+	Admin     bool   `json:"admin" bson:"admin"`
+	System    bool   `json:"system" bson:"system"`
+	Service   bool   `json:"service" bson:"service"`
+}
+
+// AuditContext identifies the actor and the request of a change
+type AuditContext struct {
+	Actor     AuditActor
+	RequestID string
+}
+
+// AuditEventFilter filters the audit log
+type AuditEventFilter struct {
+	Entity    *string
+	EntityID  *string
+	ActorID   *string
+	StartDate *time.Time
+	EndDate   *time.Time
+	Limit     *int
+	Offset    *int
+}
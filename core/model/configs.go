@@ -31,6 +31,13 @@ const (
 
 	// ConfigTypeEnv is the Config Type for EnvConfigData
 	ConfigTypeEnv string = "env"
+	// ConfigTypePrivacy is the Config Type for PrivacyConfigData
+	ConfigTypePrivacy string = "privacy"
+
+	// AnalyticsFreeTextRemove removes free text answers from analytics payloads
+	AnalyticsFreeTextRemove string = "remove"
+	// AnalyticsFreeTextHash replaces free text answers in analytics payloads with a keyed hash
+	AnalyticsFreeTextHash string = "hash"
 )
 
 // Config contain generic configs
@@ -47,8 +54,25 @@ type Config struct {
 
 // EnvConfigData contains environment configs for this service
 type EnvConfigData struct {
-	AnalyticsToken string `json:"analytics_token" bson:"analytics_token"`
-	ExternalID     string `json:"external_id" bson:"external_id"`
+	AnalyticsToken         string            `json:"analytics_token" bson:"analytics_token"`
+	AnalyticsSecret        string            `json:"analytics_secret" bson:"analytics_secret"`
+	AnalyticsProfile       *AnalyticsProfile `json:"analytics_profile,omitempty" bson:"analytics_profile,omitempty"`
+	ExternalID             string            `json:"external_id" bson:"external_id"`
+	SurveyPurgePeriod      int               `json:"survey_purge_period,omitempty" bson:"survey_purge_period,omitempty"`           // days deleted surveys are kept in the trash (default 30)
+	DeletedSurveyResponses string            `json:"deleted_survey_responses,omitempty" bson:"deleted_survey_responses,omitempty"` // keep (default), cascade or archive
+}
+
+// AnalyticsProfile configures the anonymization of survey responses sent to analytics. Responses only include
+// answers, a pseudonymous respondent ID and the response date when a profile is configured.
+type AnalyticsProfile struct {
+	FreeText        string `json:"free_text" bson:"free_text"`               // remove (default) or hash
+	TimeGranularity string `json:"time_granularity" bson:"time_granularity"` // day (default), week or month
+}
+
+// PrivacyConfigData contains the privacy settings of an app/org
+type PrivacyConfigData struct {
+	// MinGroupSize is the smallest number of responses reported in aggregate results or exported from anonymous surveys
+	MinGroupSize int `json:"min_group_size" bson:"min_group_size"`
 }
 
 // GetConfigData returns a pointer to the given config's Data as the given type T
@@ -61,5 +85,5 @@ func GetConfigData[T ConfigData](c Config) (*T, error) {
 
 // ConfigData represents any set of data that may be stored in a config
 type ConfigData interface {
-	EnvConfigData | map[string]interface{}
+	EnvConfigData | PrivacyConfigData | map[string]interface{}
 }
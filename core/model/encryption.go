@@ -0,0 +1,54 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	//TypeDataKey data key type
+	TypeDataKey logutils.MessageDataType = "data key"
+	//TypeDataKeyRotation data key rotation type
+	TypeDataKeyRotation logutils.MessageDataType = "data key rotation"
+)
+
+// DataKey is a key encrypting the sensitive data of an org. The key itself is stored wrapped by the master key and
+// never leaves the storage.
+type DataKey struct {
+	ID          string    `json:"id" bson:"_id"`
+	OrgID       string    `json:"org_id" bson:"org_id"`
+	Version     int       `json:"version" bson:"version"`
+	Active      bool      `json:"active" bson:"active"`
+	WrappedKey  []byte    `json:"-" bson:"wrapped_key"`
+	DateCreated time.Time `json:"date_created" bson:"date_created"`
+	// ReencryptedAt is when the responses of the org were all re-encrypted with the key, nil while re-encryption is pending
+	ReencryptedAt *time.Time `json:"reencrypted_at" bson:"reencrypted_at"`
+}
+
+// DataKeyRotation is the result of the rotation of the data key of an org
+type DataKeyRotation struct {
+	Key DataKey `json:"key"`
+	// Pending is the number of survey responses to be re-encrypted with the new key in the background
+	Pending int64 `json:"pending"`
+}
+
+// EncryptedData is data encrypted with a data key
+type EncryptedData struct {
+	KeyID      string `bson:"key_id"`
+	Ciphertext []byte `bson:"ciphertext"`
+}
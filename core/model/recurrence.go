@@ -0,0 +1,326 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	//TypeSurveyRecurrence survey recurrence type
+	TypeSurveyRecurrence logutils.MessageDataType = "survey recurrence"
+
+	// RecurrenceDaily repeats the survey response window every Interval days
+	RecurrenceDaily string = "DAILY"
+	// RecurrenceWeekly repeats the survey response window on the ByDay weekdays every Interval weeks
+	RecurrenceWeekly string = "WEEKLY"
+	// RecurrenceMonthly repeats the survey response window on the start date day of the month every Interval months
+	RecurrenceMonthly string = "MONTHLY"
+
+	// maxRecurrenceOccurrences stops the occurrence search for rules that would otherwise never end
+	maxRecurrenceOccurrences int = 100000
+)
+
+// recurrenceWeekdays maps the RRULE weekday codes to weekdays
+var recurrenceWeekdays = map[string]time.Weekday{"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday}
+
+// SurveyRecurrence repeats the response window of a survey. The first window opens at the survey start date and the
+// following windows open at the occurrences of Rule, keeping the time of day of the start date in TimeZone.
+type SurveyRecurrence struct {
+	// Rule is an RRULE (RFC 5545) with FREQ=DAILY, WEEKLY or MONTHLY and optional INTERVAL, BYDAY, COUNT and UNTIL parts
+	Rule string `json:"rule" bson:"rule"`
+	// TimeZone is the IANA time zone of the occurrences. Defaults to UTC.
+	TimeZone string `json:"time_zone,omitempty" bson:"time_zone,omitempty"`
+	// Duration is the number of minutes each window stays open. Windows stay open until the next occurrence by default.
+	Duration int `json:"duration,omitempty" bson:"duration,omitempty"`
+	// NotifyOnOpen notifies the participants of the survey when a window opens
+	NotifyOnOpen bool `json:"notify_on_open,omitempty" bson:"notify_on_open,omitempty"`
+	// ReminderBefore reminds the participants without a response in the current window this many minutes before it closes
+	ReminderBefore *int `json:"reminder_before,omitempty" bson:"reminder_before,omitempty"`
+}
+
+// SurveyRecurrenceState tracks the response windows of a recurring survey handled by the scheduler
+type SurveyRecurrenceState struct {
+	WindowStart   *time.Time `bson:"window_start"`
+	RemindedStart *time.Time `bson:"reminded_start"`
+}
+
+// SurveyWindow is a response window of a recurring survey. End is nil for the last window of a survey without an end date.
+type SurveyWindow struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+// Contains returns true if the window is open at the provided time
+func (w SurveyWindow) Contains(now time.Time) bool {
+	return !w.Start.After(now) && (w.End == nil || w.End.After(now))
+}
+
+// RecurrenceRule is a parsed survey recurrence rule
+type RecurrenceRule struct {
+	Frequency string
+	Interval  int
+	ByDay     []time.Weekday
+	Count     int
+	Until     *time.Time
+}
+
+// ParseRecurrenceRule parses an RRULE. Only the FREQ, INTERVAL, BYDAY, COUNT and UNTIL parts are supported.
+func ParseRecurrenceRule(rule string) (*RecurrenceRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	parsed := RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, errors.ErrorData(logutils.StatusInvalid, "recurrence rule part", logutils.StringArgs(part))
+		}
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			parsed.Frequency = strings.ToUpper(value)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, errors.ErrorData(logutils.StatusInvalid, "recurrence interval", logutils.StringArgs(value))
+			}
+			parsed.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(strings.ToUpper(value), ",") {
+				weekday, ok := recurrenceWeekdays[code]
+				if !ok {
+					return nil, errors.ErrorData(logutils.StatusInvalid, "recurrence weekday", logutils.StringArgs(code))
+				}
+				parsed.ByDay = append(parsed.ByDay, weekday)
+			}
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, errors.ErrorData(logutils.StatusInvalid, "recurrence count", logutils.StringArgs(value))
+			}
+			parsed.Count = count
+		case "UNTIL":
+			until, err := parseRecurrenceUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			parsed.Until = until
+		default:
+			return nil, errors.ErrorData(logutils.StatusInvalid, "recurrence rule part", logutils.StringArgs(name))
+		}
+	}
+
+	switch parsed.Frequency {
+	case RecurrenceDaily, RecurrenceMonthly:
+		if len(parsed.ByDay) > 0 {
+			return nil, errors.ErrorData(logutils.StatusInvalid, "recurrence rule part", &logutils.FieldArgs{"BYDAY": parsed.Frequency})
+		}
+	case RecurrenceWeekly:
+	default:
+		return nil, errors.ErrorData(logutils.StatusInvalid, "recurrence frequency", logutils.StringArgs(parsed.Frequency))
+	}
+	if parsed.Count > 0 && parsed.Until != nil {
+		return nil, errors.ErrorData(logutils.StatusInvalid, "recurrence rule", logutils.StringArgs("COUNT and UNTIL"))
+	}
+	return &parsed, nil
+}
+
+func parseRecurrenceUntil(value string) (*time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		until, err := time.Parse(layout, value)
+		if err == nil {
+			if layout == "20060102" {
+				// a date includes the whole day
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			return &until, nil
+		}
+	}
+	return nil, errors.ErrorData(logutils.StatusInvalid, "recurrence until", logutils.StringArgs(value))
+}
+
+// eachOccurrence calls yield with the occurrences of the rule starting at start, in order, until yield returns false or the rule ends
+func (r RecurrenceRule) eachOccurrence(start time.Time, yield func(time.Time) bool) {
+	year, month, day := start.Date()
+	hour, minute, second := start.Clock()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, minute, second, start.Nanosecond(), start.Location())
+	}
+
+	emitted := 0
+	emit := func(occurrence time.Time) bool {
+		if r.Until != nil && occurrence.After(*r.Until) {
+			return false
+		}
+		emitted++
+		return yield(occurrence) && (r.Count == 0 || emitted < r.Count)
+	}
+
+	for i := 0; i < maxRecurrenceOccurrences; i++ {
+		switch r.Frequency {
+		case RecurrenceDaily:
+			if !emit(at(year, month, day+i*r.Interval)) {
+				return
+			}
+		case RecurrenceWeekly:
+			byDay := r.ByDay
+			if len(byDay) == 0 {
+				byDay = []time.Weekday{start.Weekday()}
+			}
+			// weeks start on Monday
+			monday := day - (int(start.Weekday())+6)%7 + i*7*r.Interval
+			for offset := 0; offset < 7; offset++ {
+				occurrence := at(year, month, monday+offset)
+				if occurrence.Before(start) || !containsWeekday(byDay, occurrence.Weekday()) {
+					continue
+				}
+				if !emit(occurrence) {
+					return
+				}
+			}
+		case RecurrenceMonthly:
+			occurrence := at(year, month+time.Month(i*r.Interval), day)
+			// months without the start date day are skipped
+			if occurrence.Day() != day {
+				continue
+			}
+			if !emit(occurrence) {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func containsWeekday(weekdays []time.Weekday, weekday time.Weekday) bool {
+	for _, item := range weekdays {
+		if item == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the recurrence of a survey starting at the provided date is complete
+func (r SurveyRecurrence) Validate(startDate *time.Time) error {
+	if startDate == nil {
+		return errors.ErrorData(logutils.StatusMissing, "survey start date", logutils.StringArgs(TypeSurveyRecurrence))
+	}
+	if _, err := ParseRecurrenceRule(r.Rule); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(r.TimeZone); err != nil {
+		return errors.ErrorData(logutils.StatusInvalid, "recurrence time zone", logutils.StringArgs(r.TimeZone))
+	}
+	if r.Duration < 0 {
+		return errors.ErrorData(logutils.StatusInvalid, "recurrence duration", &logutils.FieldArgs{"duration": r.Duration})
+	}
+	if r.ReminderBefore != nil && *r.ReminderBefore < 1 {
+		return errors.ErrorData(logutils.StatusInvalid, "recurrence reminder", &logutils.FieldArgs{"reminder_before": *r.ReminderBefore})
+	}
+	return nil
+}
+
+// RecurrenceWindows returns the response window open at the provided time and the next window to open. Both are nil if the
+// survey does not recur, current is nil between windows and next is nil after the last window has opened. Windows are cut
+// at the survey end date.
+func (s Survey) RecurrenceWindows(now time.Time) (current *SurveyWindow, next *SurveyWindow) {
+	if s.Recurrence == nil || s.StartDate == nil {
+		return nil, nil
+	}
+	rule, err := ParseRecurrenceRule(s.Recurrence.Rule)
+	if err != nil {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(s.Recurrence.TimeZone)
+	if err != nil {
+		return nil, nil
+	}
+
+	var previous, following []time.Time
+	rule.eachOccurrence(s.StartDate.In(location), func(occurrence time.Time) bool {
+		if s.EndDate != nil && !occurrence.Before(*s.EndDate) {
+			return false
+		}
+		if occurrence.After(now) {
+			following = append(following, occurrence.UTC())
+			return len(following) < 2
+		}
+		previous = []time.Time{occurrence.UTC()}
+		return true
+	})
+
+	// windows stay open until the following occurrence unless they have a duration
+	window := func(start time.Time, followingStart *time.Time) *SurveyWindow {
+		end := followingStart
+		if s.Recurrence.Duration > 0 {
+			limit := start.Add(time.Duration(s.Recurrence.Duration) * time.Minute)
+			end = &limit
+		}
+		if s.EndDate != nil && (end == nil || end.After(*s.EndDate)) {
+			end = s.EndDate
+		}
+		return &SurveyWindow{Start: start, End: end}
+	}
+	occurrence := func(list []time.Time, index int) *time.Time {
+		if index < len(list) {
+			return &list[index]
+		}
+		return nil
+	}
+
+	if len(previous) > 0 {
+		current = window(previous[0], occurrence(following, 0))
+		if !current.Contains(now) {
+			current = nil
+		}
+	}
+	if len(following) > 0 {
+		next = window(following[0], occurrence(following, 1))
+	}
+	return current, next
+}
+
+// ResponseRecurrenceWindow returns the response window a response submitted at the provided time belongs to: the window
+// open at that time or the previous window during its grace period. It returns nil if the survey does not recur or between windows.
+func (s Survey) ResponseRecurrenceWindow(now time.Time) *SurveyWindow {
+	current, _ := s.RecurrenceWindows(now)
+	if current != nil || s.GracePeriod == nil || *s.GracePeriod <= 0 {
+		return current
+	}
+	grace := time.Duration(*s.GracePeriod) * time.Minute
+	previous, _ := s.RecurrenceWindows(now.Add(-grace))
+	if previous != nil && previous.End != nil && previous.End.Add(grace).After(now) {
+		return previous
+	}
+	return nil
+}
+
+// String returns a description of the window for logs
+func (w SurveyWindow) String() string {
+	if w.End == nil {
+		return fmt.Sprintf("[%s, -)", w.Start.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("[%s, %s)", w.Start.Format(time.RFC3339), w.End.Format(time.RFC3339))
+}
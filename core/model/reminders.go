@@ -0,0 +1,49 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	//TypeSurveyReminder survey reminder type
+	TypeSurveyReminder logutils.MessageDataType = "survey reminder"
+	//TypeSurveyReminderMute survey reminder mute type
+	TypeSurveyReminderMute logutils.MessageDataType = "survey reminder mute"
+)
+
+// SurveyReminder records a reminder sent to a user who had not responded to a survey. Each user is reminded once per offset.
+type SurveyReminder struct {
+	ID       string    `json:"id" bson:"_id"`
+	OrgID    string    `json:"org_id" bson:"org_id"`
+	AppID    string    `json:"app_id" bson:"app_id"`
+	SurveyID string    `json:"survey_id" bson:"survey_id"`
+	UserID   string    `json:"user_id" bson:"user_id"`
+	Offset   int       `json:"offset" bson:"offset"` // hours before the survey end date
+	DateSent time.Time `json:"date_sent" bson:"date_sent"`
+}
+
+// SurveyReminderMute stops the reminders of a survey, or of all surveys if SurveyID is empty, for a user
+type SurveyReminderMute struct {
+	ID          string    `json:"id" bson:"_id"`
+	OrgID       string    `json:"org_id" bson:"org_id"`
+	AppID       string    `json:"app_id" bson:"app_id"`
+	UserID      string    `json:"user_id" bson:"user_id"`
+	SurveyID    string    `json:"survey_id" bson:"survey_id"`
+	DateCreated time.Time `json:"date_created" bson:"date_created"`
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	//TypeSurveyResults survey results type
+	TypeSurveyResults logutils.MessageDataType = "survey results"
+
+	// SurveyDataStyleLikert is the style of multiple choice and numeric questions answered on a likert scale
+	SurveyDataStyleLikert string = "likert"
+
+	// SurveyResultsSuppressedKey is the key of the count of answer values given by fewer users than the minimum group size
+	SurveyResultsSuppressedKey string = "_suppressed"
+)
+
+// SurveyResults contains the aggregated answers to a survey. Groups of fewer responses than MinGroupSize are suppressed,
+// and Suppressed is set when the survey itself has too few responses for any breakdown.
+type SurveyResults struct {
+	SurveyID     string                           `json:"survey_id"`
+	Responses    int64                            `json:"responses"`
+	Questions    map[string]SurveyQuestionResults `json:"questions"`
+	Timeline     []SurveyResponseCount            `json:"timeline"`
+	Interval     string                           `json:"interval"`
+	MinGroupSize int                              `json:"min_group_size"`
+	Suppressed   bool                             `json:"suppressed,omitempty"`
+}
+
+// SurveyQuestionResults contains the aggregated answers to a single survey question. Counts are keyed by the answer value.
+// Numeric and likert answers also have a histogram sorted by value and their mean and median.
+type SurveyQuestionResults struct {
+	Type       string                  `json:"type"`
+	Style      *string                 `json:"style,omitempty"`
+	Responses  int64                   `json:"responses"`
+	Counts     map[string]int64        `json:"counts,omitempty"`
+	Histogram  []SurveyHistogramBucket `json:"histogram,omitempty"`
+	Mean       *float64                `json:"mean,omitempty"`
+	Median     *float64                `json:"median,omitempty"`
+	Suppressed bool                    `json:"suppressed,omitempty"`
+}
+
+// SurveyHistogramBucket is the number of numeric answers with a value
+type SurveyHistogramBucket struct {
+	Value float64 `json:"value"`
+	Count int64   `json:"count"`
+}
+
+// SurveyResponseCount is the number of survey responses created in the interval starting at Date
+type SurveyResponseCount struct {
+	Date  time.Time `json:"date" bson:"_id"`
+	Count int64     `json:"count" bson:"count"`
+}
+
+// SurveyResponseCursor is the position of a survey response in a stream of survey responses sorted by creation date and ID
+type SurveyResponseCursor struct {
+	DateCreated time.Time `json:"date_created"`
+	ID          string    `json:"id"`
+}
+
+// SurveyResponseAggregates contains the survey response counts computed by storage
+type SurveyResponseAggregates struct {
+	Responses int64
+	Answers   map[string]int64
+	Values    []SurveyResponseValueCount
+	Timeline  []SurveyResponseCount
+}
+
+// SurveyResponseValueCount is the number of answers to a survey question with a value
+type SurveyResponseValueCount struct {
+	Key   string
+	Value interface{}
+	Count int64
+}
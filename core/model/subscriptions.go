@@ -0,0 +1,56 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	//TypeEventSurveySubscription event survey subscription type
+	TypeEventSurveySubscription logutils.MessageDataType = "event survey subscription"
+
+	// EventSurveySubscriptionPending indicates a subscription waiting for its event to end
+	EventSurveySubscriptionPending string = "pending"
+	// EventSurveySubscriptionProcessing indicates a subscription whose follow-up survey is being created
+	EventSurveySubscriptionProcessing string = "processing"
+	// EventSurveySubscriptionCreated indicates a subscription whose follow-up survey has been created
+	EventSurveySubscriptionCreated string = "created"
+	// EventSurveySubscriptionFailed indicates a subscription whose follow-up survey could not be created
+	EventSurveySubscriptionFailed string = "failed"
+)
+
+// EventSurveySubscription creates the follow-up survey of a calendar event from a template when the event ends
+type EventSurveySubscription struct {
+	ID              string            `json:"id" bson:"_id"`
+	OrgID           string            `json:"org_id" bson:"org_id"`
+	AppID           string            `json:"app_id" bson:"app_id"`
+	CalendarEventID string            `json:"calendar_event_id" bson:"calendar_event_id"`
+	TemplateID      string            `json:"template_id" bson:"template_id"`
+	CreatorID       string            `json:"creator_id" bson:"creator_id"`
+	ExternalIDs     map[string]string `json:"-" bson:"external_ids"`
+	EventEndDate    time.Time         `json:"event_end_date" bson:"event_end_date"`
+	Title           *string           `json:"title" bson:"title"`
+	Duration        *int              `json:"duration" bson:"duration"` // hours the follow-up survey accepts responses, no end date if not set
+
+	Status      string     `json:"status" bson:"status"`
+	SurveyID    *string    `json:"survey_id" bson:"survey_id"`
+	Notified    int        `json:"notified" bson:"notified"` // number of attendees notified about the follow-up survey
+	Error       *string    `json:"error" bson:"error"`
+	DateCreated time.Time  `json:"date_created" bson:"date_created"`
+	DateUpdated *time.Time `json:"date_updated" bson:"date_updated"`
+}
@@ -15,6 +15,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/rokwire/logging-library-go/v2/logutils"
@@ -25,6 +26,82 @@ const (
 	TypeSurvey logutils.MessageDataType = "survey"
 	//TypeSurveyResponse example type
 	TypeSurveyResponse logutils.MessageDataType = "survey response"
+	//TypeSurveyEvaluation survey evaluation type
+	TypeSurveyEvaluation logutils.MessageDataType = "survey evaluation"
+	//TypeSurveyRevision survey revision type
+	TypeSurveyRevision logutils.MessageDataType = "survey revision"
+	//TypeSurveyResponseCursor survey response cursor type
+	TypeSurveyResponseCursor logutils.MessageDataType = "survey response cursor"
+
+	// SurveyDataTypeTrueFalse is the survey data type for true/false questions
+	SurveyDataTypeTrueFalse string = "survey_data.true_false"
+	// SurveyDataTypeMultipleChoice is the survey data type for multiple choice questions
+	SurveyDataTypeMultipleChoice string = "survey_data.multiple_choice"
+	// SurveyDataTypeDateTime is the survey data type for date/time questions
+	SurveyDataTypeDateTime string = "survey_data.date_time"
+	// SurveyDataTypeNumeric is the survey data type for numeric questions
+	SurveyDataTypeNumeric string = "survey_data.numeric"
+	// SurveyDataTypeText is the survey data type for free text questions
+	SurveyDataTypeText string = "survey_data.text"
+	// SurveyDataTypeEntry is the survey data type for data entry questions
+	SurveyDataTypeEntry string = "survey_data.entry"
+	// SurveyDataTypeResult is the survey data type for result pages
+	SurveyDataTypeResult string = "survey_data.result"
+	// SurveyDataTypePage is the survey data type for pages grouping other survey data
+	SurveyDataTypePage string = "survey_data.page"
+
+	// SurveyChangeAdded indicates a value that only exists in the newer survey definition
+	SurveyChangeAdded string = "added"
+	// SurveyChangeRemoved indicates a value that only exists in the older survey definition
+	SurveyChangeRemoved string = "removed"
+	// SurveyChangeModified indicates a value that is different in the two survey definitions
+	SurveyChangeModified string = "modified"
+
+	// SurveyStatusDraft is the status of surveys that are only visible to their creator and admins
+	SurveyStatusDraft string = "draft"
+	// SurveyStatusScheduled is the status of published surveys whose start date has not been reached
+	SurveyStatusScheduled string = "scheduled"
+	// SurveyStatusPublished is the status of surveys that are open for responses
+	SurveyStatusPublished string = "published"
+	// SurveyStatusClosed is the status of published surveys whose end date has passed
+	SurveyStatusClosed string = "closed"
+	// SurveyStatusArchived is the status of archived surveys
+	SurveyStatusArchived string = "archived"
+
+	// ResponseWindowOpen indicates that a survey accepts responses
+	ResponseWindowOpen string = "open"
+	// ResponseWindowNotOpen indicates that the survey start date has not been reached
+	ResponseWindowNotOpen string = "survey-not-open"
+	// ResponseWindowClosed indicates that the survey end date and grace period have passed or an admin closed the survey
+	ResponseWindowClosed string = "survey-closed"
+
+	// ResponsePolicyUnlimited allows any number of responses per user
+	ResponsePolicyUnlimited string = "unlimited"
+	// ResponsePolicySingle allows a single response per user that cannot be edited
+	ResponsePolicySingle string = "single"
+	// ResponsePolicySingleEditable allows a single response per user that can be edited until the survey closes
+	ResponsePolicySingleEditable string = "single_editable"
+	// ResponsePolicyPerPeriod allows up to Limit responses per user in each period
+	ResponsePolicyPerPeriod string = "per_period"
+
+	// ResponsePeriodDay limits the responses per calendar day (UTC)
+	ResponsePeriodDay string = "day"
+	// ResponsePeriodWeek limits the responses per calendar week starting on Monday (UTC)
+	ResponsePeriodWeek string = "week"
+	// ResponsePeriodMonth limits the responses per calendar month (UTC)
+	ResponsePeriodMonth string = "month"
+
+	// DeletedSurveyResponsesKeep keeps the responses to a deleted survey and marks their survey as deleted
+	DeletedSurveyResponsesKeep string = "keep"
+	// DeletedSurveyResponsesCascade deletes the responses together with the survey
+	DeletedSurveyResponsesCascade string = "cascade"
+	// DeletedSurveyResponsesArchive moves the responses to a deleted survey to the survey responses archive
+	DeletedSurveyResponsesArchive string = "archive"
+
+	// SurveyResponseStatusInProgress indicates a saved response that has not been finalized
+	SurveyResponseStatusInProgress string = "in_progress"
+	// SurveyResponseStatusCompleted indicates a finalized response. Responses created before drafts were supported have no status.
+	SurveyResponseStatusCompleted string = "completed"
 )
 
 // SurveyResponse wraps the entire survey response
@@ -36,6 +113,18 @@ type SurveyResponse struct {
 	Survey      Survey     `json:"survey" bson:"survey"`
 	DateCreated time.Time  `json:"date_created" bson:"date_created"`
 	DateUpdated *time.Time `json:"date_updated" bson:"date_updated"`
+
+	Status           string            `json:"status,omitempty" bson:"status,omitempty"`
+	SurveyRevision   int               `json:"survey_revision" bson:"survey_revision"`
+	IdempotencyKey   string            `json:"-" bson:"idempotency_key,omitempty"`
+	LimitKey         string            `json:"-" bson:"limit_key,omitempty"`
+	ClientStats      *SurveyStats      `json:"client_stats,omitempty" bson:"client_stats,omitempty"`
+	ValidationErrors []SurveyDataError `json:"validation_errors,omitempty" bson:"-"`
+}
+
+// InProgress returns true if the survey response is a saved draft that has not been finalized
+func (s SurveyResponse) InProgress() bool {
+	return s.Status == SurveyResponseStatusInProgress
 }
 
 // Survey wraps the entire record
@@ -68,6 +157,154 @@ type Survey struct {
 	Public                  *bool                  `json:"public" bson:"public"`
 	Archived                *bool                  `json:"archived" bson:"archived"`
 	EstimatedCompletionTime *int                   `json:"estimated_completion_time" bson:"estimated_completion_time"`
+	Revision                int                    `json:"revision" bson:"revision"`
+	Status                  string                 `json:"status" bson:"status"`
+	GracePeriod             *int                   `json:"grace_period" bson:"grace_period"`
+	ResponseWindowOverride  *bool                  `json:"response_window_override" bson:"response_window_override"`
+	ResponsePolicy          *SurveyResponsePolicy  `json:"response_policy" bson:"response_policy"`
+	Template                *SurveyTemplate        `json:"template,omitempty" bson:"template,omitempty"`
+	Recurrence              *SurveyRecurrence      `json:"recurrence" bson:"recurrence"`
+	ReminderOffsets         []int                  `json:"reminder_offsets" bson:"reminder_offsets"` // hours before the end date
+	Audience                *SurveyAudience        `json:"audience" bson:"audience"`
+	AlertRules              []SurveyAlertRule      `json:"alert_rules" bson:"alert_rules"`
+
+	// RecurrenceState tracks the response windows of recurring surveys handled by the scheduler
+	RecurrenceState *SurveyRecurrenceState `json:"-" bson:"recurrence_state,omitempty"`
+
+	// EncryptedData holds the encrypted Data of responses to sensitive surveys
+	EncryptedData *EncryptedData `json:"-" bson:"encrypted_data,omitempty"`
+
+	// DeletedAt is set when the survey is moved to the trash. Surveys in the trash are purged after the configured purge period.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	// DeletedCalendarEventID keeps the calendar event of a survey in the trash, so a new survey may be created for the event
+	DeletedCalendarEventID string `json:"-" bson:"deleted_calendar_event_id,omitempty"`
+	// DeletedResponses is the policy applied to the responses to a survey in the trash when the survey is purged
+	DeletedResponses string `json:"deleted_responses,omitempty" bson:"deleted_responses,omitempty"`
+}
+
+// SurveyResponsePolicy limits the number of responses each user may submit to a survey
+type SurveyResponsePolicy struct {
+	Mode   string `json:"mode" bson:"mode"`
+	Limit  int    `json:"limit,omitempty" bson:"limit,omitempty"`
+	Period string `json:"period,omitempty" bson:"period,omitempty"`
+}
+
+// RespondentView returns the survey without its audience and alert rules, which are only for the users managing the
+// survey. It is the copy of the survey shown to respondents and embedded in their responses.
+func (s Survey) RespondentView() Survey {
+	s.Audience = nil
+	s.AlertRules = nil
+	return s
+}
+
+// ResponsePolicyMode returns the response policy mode of the survey. Surveys without a policy allow unlimited responses.
+func (s Survey) ResponsePolicyMode() string {
+	if s.ResponsePolicy == nil || s.ResponsePolicy.Mode == "" {
+		return ResponsePolicyUnlimited
+	}
+	return s.ResponsePolicy.Mode
+}
+
+// CurrentStatus returns the lifecycle status of the survey. Surveys created before statuses were tracked are published.
+func (s Survey) CurrentStatus() string {
+	if s.Status != "" {
+		return s.Status
+	}
+	if s.Archived != nil && *s.Archived {
+		return SurveyStatusArchived
+	}
+	return SurveyStatusPublished
+}
+
+// DateStatus returns the status of a published survey at the provided time based on its start and end dates. Surveys
+// close once the grace period after the end date is over.
+func (s Survey) DateStatus(now time.Time) string {
+	if end := s.responseEndDate(); end != nil && !end.After(now) {
+		return SurveyStatusClosed
+	}
+	if s.StartDate != nil && s.StartDate.After(now) {
+		return SurveyStatusScheduled
+	}
+	return SurveyStatusPublished
+}
+
+// responseEndDate returns the end date of the survey extended by the grace period, or nil if the survey has no end date
+func (s Survey) responseEndDate() *time.Time {
+	if s.EndDate == nil {
+		return nil
+	}
+	end := *s.EndDate
+	if s.GracePeriod != nil && *s.GracePeriod > 0 {
+		end = end.Add(time.Duration(*s.GracePeriod) * time.Minute)
+	}
+	return &end
+}
+
+// ResponseWindow returns the status of the survey response window at the provided time: ResponseWindowOpen, ResponseWindowNotOpen
+// or ResponseWindowClosed. Responses are accepted for GracePeriod minutes after the end date. Recurring surveys only accept
+// responses during their recurrence windows, each followed by the grace period. An admin override forces the window open
+// (true) or closed (false) regardless of the dates.
+func (s Survey) ResponseWindow(now time.Time) string {
+	if s.ResponseWindowOverride != nil {
+		if *s.ResponseWindowOverride {
+			return ResponseWindowOpen
+		}
+		return ResponseWindowClosed
+	}
+	if s.StartDate != nil && s.StartDate.After(now) {
+		return ResponseWindowNotOpen
+	}
+	if end := s.responseEndDate(); end != nil && !end.After(now) {
+		return ResponseWindowClosed
+	}
+	if s.Recurrence != nil && s.ResponseRecurrenceWindow(now) == nil {
+		if _, next := s.RecurrenceWindows(now); next != nil {
+			return ResponseWindowNotOpen
+		}
+		return ResponseWindowClosed
+	}
+	return ResponseWindowOpen
+}
+
+// SurveyStatusError is returned when a survey cannot move from its current status to the requested one
+type SurveyStatusError struct {
+	Status string `json:"status"`
+	Target string `json:"target"`
+}
+
+// Error returns a description of the refused transition
+func (e *SurveyStatusError) Error() string {
+	return fmt.Sprintf("survey cannot move from %s to %s", e.Status, e.Target)
+}
+
+// SurveyRevision is an immutable snapshot of a survey definition. A new revision is created every time a survey is updated.
+// Revision 0 holds the definition of surveys created before revisions were tracked.
+type SurveyRevision struct {
+	ID           string    `json:"id" bson:"_id"`
+	SurveyID     string    `json:"survey_id" bson:"survey_id"`
+	OrgID        string    `json:"org_id" bson:"org_id"`
+	AppID        string    `json:"app_id" bson:"app_id"`
+	Revision     int       `json:"revision" bson:"revision"`
+	Survey       Survey    `json:"survey" bson:"survey"`
+	UserID       string    `json:"user_id" bson:"user_id"`
+	RestoredFrom *int      `json:"restored_from,omitempty" bson:"restored_from,omitempty"`
+	DateCreated  time.Time `json:"date_created" bson:"date_created"`
+}
+
+// SurveyRevisionDiff lists the differences between two revisions of a survey
+type SurveyRevisionDiff struct {
+	SurveyID string         `json:"survey_id"`
+	From     int            `json:"from"`
+	To       int            `json:"to"`
+	Changes  []SurveyChange `json:"changes"`
+}
+
+// SurveyChange is a difference between two survey definitions, or two versions of an audited entity, at the provided path (eg. data.question1.text)
+type SurveyChange struct {
+	Path string      `json:"path" bson:"path"`
+	Type string      `json:"type" bson:"type"`
+	Old  interface{} `json:"old,omitempty" bson:"old,omitempty"`
+	New  interface{} `json:"new,omitempty" bson:"new,omitempty"`
 }
 
 // SurveyResponseAnonymous represents an anonymized survey response
@@ -81,6 +318,12 @@ type SurveyResponseAnonymous struct {
 	SurveyStats *SurveyStats `json:"stats"`
 	DateCreated time.Time    `json:"date_created"`
 	DateUpdated *time.Time   `json:"date_updated,omitempty"`
+
+	SurveyRevision int `json:"survey_revision"`
+
+	RespondentID  string                 `json:"respondent_id,omitempty"`
+	DateResponded *time.Time             `json:"date_responded,omitempty"`
+	Answers       map[string]interface{} `json:"answers,omitempty"`
 }
 
 // SurveyStats are stats of a Survey
@@ -110,6 +353,8 @@ type SurveyData struct {
 
 	Type string `json:"type" bson:"type"`
 
+	ExcludeFromAnalytics bool `json:"exclude_from_analytics,omitempty" bson:"exclude_from_analytics,omitempty"`
+
 	// Shared
 	CorrectAnswer  interface{}   `json:"correct_answer,omitempty" bson:"correct_answer,omitempty"`
 	CorrectAnswers []interface{} `json:"correct_answers,omitempty" bson:"correct_answers,omitempty"`
@@ -202,6 +447,18 @@ type SurveyRequest struct {
 	Public                  *bool                  `json:"public" bson:"public"`
 	Archived                *bool                  `json:"archived" bson:"archived"`
 	EstimatedCompletionTime *int                   `json:"estimated_completion_time" bson:"estimated_completion_time"`
+	Status                  *string                `json:"status" bson:"status"`
+	GracePeriod             *int                   `json:"grace_period" bson:"grace_period"`
+	ResponsePolicy          *SurveyResponsePolicy  `json:"response_policy" bson:"response_policy"`
+	Recurrence              *SurveyRecurrence      `json:"recurrence" bson:"recurrence"`
+	ReminderOffsets         []int                  `json:"reminder_offsets" bson:"reminder_offsets"`
+	Audience                *SurveyAudience        `json:"audience" bson:"audience"`
+	AlertRules              []SurveyAlertRule      `json:"alert_rules" bson:"alert_rules"`
+}
+
+// SurveyResponseWindowOverrideRequest wraps the admin override of the survey response window
+type SurveyResponseWindowOverrideRequest struct {
+	Override *bool `json:"override"`
 }
 
 // SurveyTimeFilter wraps the time filter for surveys
@@ -243,6 +500,11 @@ type SurveysResponseData struct {
 	Archived                *bool                  `json:"archived"`
 	EstimatedCompletionTime *int                   `json:"estimated_completion_time"`
 	Completed               *bool                  `json:"completed"`
+	InProgress              *bool                  `json:"in_progress"`
+	Status                  string                 `json:"status"`
+	Recurrence              *SurveyRecurrence      `json:"recurrence,omitempty"`
+	// Window is the current response window of a recurring survey, or the next one between windows
+	Window *SurveyWindow `json:"window,omitempty"`
 }
 
 // SurveyTimeFilterRequest wraps the time filter for surveys
@@ -258,3 +520,23 @@ type UserData struct {
 	SurveyUserData         *[]Survey         `json:"survey"`
 	SurveyResponseUserData *[]SurveyResponse `json:"survey_responses"`
 }
+
+// SurveyRuleAction is an action produced by the survey rules that is not handled by the rules themselves (eg. alerts)
+type SurveyRuleAction struct {
+	Action  string      `json:"action"`
+	Data    interface{} `json:"data"`
+	DataKey string      `json:"data_key,omitempty"`
+}
+
+// SurveyEvaluation is the result of evaluating the rules of a survey against a survey response
+type SurveyEvaluation struct {
+	Data             map[string]SurveyData `json:"data"`
+	Path             []string              `json:"path"`
+	PathComplete     bool                  `json:"path_complete"`
+	SurveyStats      SurveyStats           `json:"stats"`
+	Result           interface{}           `json:"result"`
+	ResultJSON       string                `json:"result_json"`
+	Actions          []SurveyRuleAction    `json:"actions"`
+	ValidationErrors []SurveyDataError     `json:"validation_errors"`
+	RuleErrors       []string              `json:"rule_errors"`
+}
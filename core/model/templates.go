@@ -0,0 +1,54 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	//TypeSurveyTemplate survey template type
+	TypeSurveyTemplate logutils.MessageDataType = "survey template"
+)
+
+// SurveyTemplate marks a survey as a template which new surveys can be cloned from
+type SurveyTemplate struct {
+	Name        string  `json:"name" bson:"name"`
+	Description *string `json:"description" bson:"description"`
+	Category    *string `json:"category" bson:"category"`
+	// InCatalog lists the template in the template catalog of the app/org. Only admins curate the catalog.
+	InCatalog   bool      `json:"in_catalog" bson:"in_catalog"`
+	DateCreated time.Time `json:"date_created" bson:"date_created"`
+}
+
+// SurveyTemplateFilter wraps the filter for survey templates
+type SurveyTemplateFilter struct {
+	InCatalog *bool
+	CreatorID *string
+	Category  *string
+	Limit     *int
+	Offset    *int
+}
+
+// SurveyCloneOverrides wraps the fields of a cloned survey which are not copied from the template
+type SurveyCloneOverrides struct {
+	Title           *string    `json:"title"`
+	CalendarEventID *string    `json:"calendar_event_id"`
+	StartDate       *time.Time `json:"start_date"`
+	EndDate         *time.Time `json:"end_date"`
+	Status          *string    `json:"status"`
+}
@@ -0,0 +1,195 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// ValidationStatusInvalidSurveyResponse is the error status returned when a survey response fails validation
+	ValidationStatusInvalidSurveyResponse string = "invalid-survey-response"
+
+	// ValidationCodeMissing indicates that a required question was not answered
+	ValidationCodeMissing string = "missing"
+	// ValidationCodeUnknownKey indicates that an answer was provided for a question that does not exist in the survey
+	ValidationCodeUnknownKey string = "unknown_key"
+	// ValidationCodeInvalidType indicates that the answer has the wrong type for the question
+	ValidationCodeInvalidType string = "invalid_type"
+	// ValidationCodeInvalidOption indicates that the answer is not one of the question options
+	ValidationCodeInvalidOption string = "invalid_option"
+	// ValidationCodeMultipleNotAllowed indicates that multiple answers were provided for a single answer question
+	ValidationCodeMultipleNotAllowed string = "multiple_not_allowed"
+	// ValidationCodeOutOfRange indicates that the answer is outside of the allowed range
+	ValidationCodeOutOfRange string = "out_of_range"
+	// ValidationCodeNotWholeNumber indicates that the answer must be a whole number
+	ValidationCodeNotWholeNumber string = "not_whole_number"
+	// ValidationCodeInvalidLength indicates that the answer is too short or too long
+	ValidationCodeInvalidLength string = "invalid_length"
+	// ValidationCodeInvalidFormat indicates that the answer does not match the expected data format
+	ValidationCodeInvalidFormat string = "invalid_format"
+	// ValidationCodeNotOnPath indicates that an answer was provided for a question the follow-up rules skip
+	ValidationCodeNotOnPath string = "not_on_path"
+)
+
+// SurveyDataError describes a validation failure for the answer to a single survey question
+type SurveyDataError struct {
+	Key     string `json:"key"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// SurveyResponseValidationError is returned when the answers in a survey response do not match the survey definition
+type SurveyResponseValidationError struct {
+	Errors []SurveyDataError `json:"errors"`
+}
+
+// Error returns a summary of the validation failures
+func (e *SurveyResponseValidationError) Error() string {
+	return fmt.Sprintf("survey response failed validation for %d question(s)", len(e.Errors))
+}
+
+const (
+	// ValidationStatusInvalidSurvey is the error status returned when a survey definition fails linting
+	ValidationStatusInvalidSurvey string = "invalid-survey"
+
+	// LintSeverityError indicates a survey definition problem that breaks the survey
+	LintSeverityError string = "error"
+	// LintSeverityWarning indicates a survey definition problem that is likely a mistake
+	LintSeverityWarning string = "warning"
+
+	// LintCodeDanglingReference indicates a reference to survey data, a sub rule, a constant or a string that does not exist
+	LintCodeDanglingReference string = "dangling_reference"
+	// LintCodeInvalidRule indicates a rule that cannot be parsed
+	LintCodeInvalidRule string = "invalid_rule"
+	// LintCodeCycle indicates a cycle in the follow-up graph
+	LintCodeCycle string = "cycle"
+	// LintCodeUnreachable indicates survey data that cannot be reached from the default data key
+	LintCodeUnreachable string = "unreachable"
+	// LintCodeUnused indicates a string, constant or sub rule that is never used
+	LintCodeUnused string = "unused"
+	// LintCodeMissingOptions indicates a multiple choice question without options
+	LintCodeMissingOptions string = "missing_options"
+	// LintCodeDuplicateOption indicates options of a question sharing the same value
+	LintCodeDuplicateOption string = "duplicate_option"
+	// LintCodeInvalidCorrectAnswer indicates a correct answer that is not one of the question options
+	LintCodeInvalidCorrectAnswer string = "invalid_correct_answer"
+	// LintCodeInvalidScore indicates scoring that is inconsistent with the question or survey definition
+	LintCodeInvalidScore string = "invalid_score"
+	// LintCodeInvalidRange indicates a minimum greater than the maximum
+	LintCodeInvalidRange string = "invalid_range"
+)
+
+// SurveyLintIssue describes a problem found in a survey definition
+type SurveyLintIssue struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// SurveyLint is the result of the static analysis of a survey definition
+type SurveyLint struct {
+	Valid    bool              `json:"valid"`
+	Errors   []SurveyLintIssue `json:"errors"`
+	Warnings []SurveyLintIssue `json:"warnings"`
+}
+
+// SurveyLintError is returned when saving a survey definition that has lint errors is refused
+type SurveyLintError struct {
+	Errors []SurveyLintIssue `json:"errors"`
+}
+
+// Error returns a summary of the lint errors
+func (e *SurveyLintError) Error() string {
+	return fmt.Sprintf("survey definition has %d error(s)", len(e.Errors))
+}
+
+// SurveyResponseWindowError is returned when a survey response is submitted outside of the survey response window
+type SurveyResponseWindowError struct {
+	Code      string     `json:"code"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+}
+
+// Error returns a description of the response window failure
+func (e *SurveyResponseWindowError) Error() string {
+	if e.Code == ResponseWindowNotOpen {
+		return "survey is not open for responses yet"
+	}
+	return "survey is closed for responses"
+}
+
+const (
+	// ResponseLimitReached indicates that the user has submitted the maximum number of responses allowed by the survey response policy
+	ResponseLimitReached string = "response-limit-reached"
+	// ResponseNotEditable indicates that the survey response policy does not allow editing responses
+	ResponseNotEditable string = "response-not-editable"
+)
+
+// SurveyResponseLimitError is returned when a survey response is refused by the survey response policy
+type SurveyResponseLimitError struct {
+	Code   string               `json:"code"`
+	Policy SurveyResponsePolicy `json:"policy"`
+}
+
+// Error returns a description of the response policy failure
+func (e *SurveyResponseLimitError) Error() string {
+	if e.Code == ResponseNotEditable {
+		return fmt.Sprintf("survey responses cannot be edited with the %s response policy", e.Policy.Mode)
+	}
+	return fmt.Sprintf("survey response limit reached for the %s response policy", e.Policy.Mode)
+}
+
+// SurveyPrivacyError is returned when survey responses are refused because too few users responded to keep them anonymous
+type SurveyPrivacyError struct {
+	MinGroupSize int `json:"min_group_size"`
+}
+
+// Error returns a description of the privacy failure
+func (e *SurveyPrivacyError) Error() string {
+	return fmt.Sprintf("survey responses are not available for anonymous surveys with fewer than %d responses", e.MinGroupSize)
+}
+
+// SurveyAudienceError is returned when a user responds to a survey whose audience does not include the user
+type SurveyAudienceError struct {
+	SurveyID string `json:"survey_id"`
+}
+
+// Error returns a description of the audience failure
+func (e *SurveyAudienceError) Error() string {
+	return fmt.Sprintf("user is not in the audience of survey %s", e.SurveyID)
+}
+
+// InvalidCursorError is returned when a stream is resumed from a cursor that was not issued by the service
+type InvalidCursorError struct {
+	Reason string
+}
+
+// Error returns a description of the cursor failure
+func (e *InvalidCursorError) Error() string {
+	return fmt.Sprintf("invalid cursor: %s", e.Reason)
+}
+
+// DuplicateKeyError is returned by the storage when an item conflicts with an existing item on a unique key
+type DuplicateKeyError struct {
+	Type string
+}
+
+// Error returns a description of the conflict
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate %s", e.Type)
+}
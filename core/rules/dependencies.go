@@ -0,0 +1,132 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"application/utils"
+	"strings"
+)
+
+// Dependencies lists what a rule depends on, so survey definitions can be checked without evaluating their rules
+type Dependencies struct {
+	// RuleKeys are the keys of the referenced sub rules
+	RuleKeys []string
+	// References are the referenced values (eg. "data.question1.response", "constants.threshold")
+	References []string
+	// Values are the string literals produced by actions (eg. the data keys returned by follow-up rules)
+	Values []string
+}
+
+// DataKeys returns the survey data keys used by the references
+func (d Dependencies) DataKeys() []string {
+	return d.referencedKeys(referenceData)
+}
+
+// ConstantKeys returns the survey constant keys used by the references
+func (d Dependencies) ConstantKeys() []string {
+	return d.referencedKeys(referenceConstants)
+}
+
+// StringKeys returns the survey string keys used by the references
+func (d Dependencies) StringKeys() []string {
+	return d.referencedKeys(referenceStrings)
+}
+
+func (d Dependencies) referencedKeys(prefix string) []string {
+	keys := make([]string, 0)
+	for _, reference := range d.References {
+		referencePrefix, path, _ := strings.Cut(reference, ".")
+		if referencePrefix != prefix {
+			continue
+		}
+		key, _, _ := strings.Cut(path, ".")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// GetDependencies returns the dependencies of the provided rule result. Sub rules are not followed.
+func GetDependencies(result Result) Dependencies {
+	var dependencies Dependencies
+	dependencies.addResult(result)
+	return dependencies
+}
+
+func (d *Dependencies) addResult(result Result) {
+	switch typed := result.(type) {
+	case Rule:
+		d.addRule(typed)
+	case Cases:
+		for _, rule := range typed.Cases {
+			d.addRule(rule)
+		}
+	case Reference:
+		d.RuleKeys = append(d.RuleKeys, typed.RuleKey)
+	case Action:
+		d.addValue(typed.Data, true)
+	case ActionList:
+		for _, action := range typed.Actions {
+			d.addValue(action.Data, true)
+		}
+	case List:
+		for _, item := range typed.Results {
+			d.addResult(item)
+		}
+	}
+}
+
+func (d *Dependencies) addRule(rule Rule) {
+	d.addCondition(rule.Condition)
+	if rule.TrueResult != nil {
+		d.addResult(rule.TrueResult)
+	}
+	if rule.FalseResult != nil {
+		d.addResult(rule.FalseResult)
+	}
+}
+
+func (d *Dependencies) addCondition(condition Condition) {
+	switch typed := condition.(type) {
+	case Comparison:
+		d.addValue(typed.DataKey, false)
+		d.addValue(typed.CompareTo, false)
+	case Logic:
+		for _, item := range typed.Conditions {
+			d.addCondition(item)
+		}
+	}
+}
+
+// addValue adds the references and nested rules in value. String literals are kept if isResult is true.
+func (d *Dependencies) addValue(value interface{}, isResult bool) {
+	switch typed := utils.NormalizeValue(value).(type) {
+	case string:
+		if IsReference(typed) {
+			d.References = append(d.References, typed)
+		} else if isResult {
+			d.Values = append(d.Values, typed)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			d.addValue(item, isResult)
+		}
+	case map[string]interface{}:
+		if isResultData(typed) {
+			if result, err := parseResult(typed); err == nil {
+				d.addResult(result)
+			}
+		}
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"application/core/model"
+	"application/utils"
+	"fmt"
+	"strings"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	// TypeSurveyDataPath survey data path type
+	TypeSurveyDataPath logutils.MessageDataType = "survey data path"
+
+	referenceData      = "data"
+	referenceStats     = "stats"
+	referenceConstants = "constants"
+	referenceStrings   = "strings"
+)
+
+// ActionResult is an action found while evaluating rules that must be executed by the caller (eg. alerts, notifications)
+type ActionResult = model.SurveyRuleAction
+
+// Engine evaluates the rules of a survey against the answers of a survey response
+type Engine struct {
+	survey    model.Survey
+	responses map[string]interface{}
+	scores    map[string]float64
+	stats     *model.SurveyStats
+
+	subRules map[string]Result
+
+	result    interface{}
+	resultSet bool
+	actions   []ActionResult
+}
+
+// NewEngine creates a new rules engine for the provided survey definition and answers
+func NewEngine(survey model.Survey, data map[string]model.SurveyData) *Engine {
+	responses := make(map[string]interface{}, len(data))
+	for key, answer := range data {
+		if answer.Response != nil {
+			responses[key] = utils.NormalizeValue(answer.Response)
+		}
+	}
+	return &Engine{survey: survey, responses: responses, scores: map[string]float64{}, subRules: map[string]Result{}}
+}
+
+// SetResponse sets the response to the survey data with the provided key
+func (e *Engine) SetResponse(key string, response interface{}) {
+	e.responses[key] = utils.NormalizeValue(response)
+}
+
+// SetScore sets the computed score of the survey data with the provided key
+func (e *Engine) SetScore(key string, score float64) {
+	e.scores[key] = score
+}
+
+// SetStats sets the computed stats of the survey response
+func (e *Engine) SetStats(stats model.SurveyStats) {
+	e.stats = &stats
+}
+
+// Evaluate parses and evaluates the provided rule JSON
+func (e *Engine) Evaluate(rule string) (interface{}, error) {
+	result, err := Parse(rule)
+	if err != nil {
+		return nil, err
+	}
+	return result.evaluate(e, 0)
+}
+
+// DefaultDataKey returns the key of the first survey data, evaluating the default data key rule if there is one
+func (e *Engine) DefaultDataKey() (string, error) {
+	if e.survey.DefaultDataKeyRule != nil && *e.survey.DefaultDataKeyRule != "" {
+		value, err := e.Evaluate(*e.survey.DefaultDataKeyRule)
+		if err != nil {
+			return "", errors.WrapErrorAction(logutils.ActionApply, TypeRule, logutils.StringArgs("default_data_key_rule"), err)
+		}
+		if key, ok := value.(string); ok && key != "" {
+			return key, nil
+		}
+	}
+	return utils.GetString(e.survey.DefaultDataKey), nil
+}
+
+// FollowUpKey returns the key of the survey data that follows the survey data with the provided key,
+// evaluating its follow-up rule if there is one
+func (e *Engine) FollowUpKey(key string) (string, error) {
+	question, ok := e.survey.Data[key]
+	if !ok {
+		return "", errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"data_key": key})
+	}
+	if question.FollowUpRule != nil && *question.FollowUpRule != "" {
+		value, err := e.Evaluate(*question.FollowUpRule)
+		if err != nil {
+			return "", errors.WrapErrorAction(logutils.ActionApply, TypeRule, &logutils.FieldArgs{"follow_up_rule": key}, err)
+		}
+		if next, ok := value.(string); ok && next != "" {
+			return next, nil
+		}
+	}
+	return utils.GetString(question.DefaultFollowUpKey), nil
+}
+
+// Path returns the keys of the survey data a respondent goes through for the current answers, in order.
+// The survey data in a page are part of the path after the page. If the path cannot be determined completely,
+// the keys found so far are returned with the error.
+func (e *Engine) Path() ([]string, error) {
+	path := make([]string, 0)
+	visited := map[string]bool{}
+
+	key, err := e.DefaultDataKey()
+	if err != nil {
+		return path, err
+	}
+	for key != "" {
+		if visited[key] {
+			return path, errors.ErrorData(logutils.StatusInvalid, TypeSurveyDataPath, &logutils.FieldArgs{"cycle": key})
+		}
+		question, ok := e.survey.Data[key]
+		if !ok {
+			return path, errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, &logutils.FieldArgs{"data_key": key})
+		}
+
+		visited[key] = true
+		path = append(path, key)
+		if question.Type == model.SurveyDataTypePage {
+			for _, dataKey := range question.DataKeys {
+				if !visited[dataKey] {
+					visited[dataKey] = true
+					path = append(path, dataKey)
+				}
+			}
+		}
+
+		key, err = e.FollowUpKey(key)
+		if err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+// Score evaluates the score rule of the survey data with the provided key. ok is false if there is no score rule.
+func (e *Engine) Score(key string) (score float64, ok bool, err error) {
+	question := e.survey.Data[key]
+	if question.ScoreRule == nil || *question.ScoreRule == "" {
+		return 0, false, nil
+	}
+	value, err := e.Evaluate(*question.ScoreRule)
+	if err != nil {
+		return 0, false, errors.WrapErrorAction(logutils.ActionApply, TypeRule, &logutils.FieldArgs{"score_rule": key}, err)
+	}
+	if value == nil {
+		return 0, true, nil
+	}
+	score, ok = utils.ToFloat64(value)
+	if !ok {
+		return 0, false, errors.ErrorData(logutils.StatusInvalid, TypeRule, &logutils.FieldArgs{"score_rule": key, "value": value})
+	}
+	return score, true, nil
+}
+
+// DefaultResponse evaluates the default response rule of the survey data with the provided key
+func (e *Engine) DefaultResponse(key string) (interface{}, error) {
+	question := e.survey.Data[key]
+	if question.DefaultResponseRule == nil || *question.DefaultResponseRule == "" {
+		return nil, nil
+	}
+	value, err := e.Evaluate(*question.DefaultResponseRule)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionApply, TypeRule, &logutils.FieldArgs{"default_response_rule": key}, err)
+	}
+	return value, nil
+}
+
+// Results evaluates the result rules of the survey. The result is the data set by "set_result" actions, or the value
+// the result rules evaluate to if there are none. The actions the caller is expected to execute are returned as well.
+func (e *Engine) Results() (interface{}, []ActionResult, error) {
+	e.result = nil
+	e.resultSet = false
+	e.actions = nil
+	if e.survey.ResultRules == "" {
+		return nil, nil, nil
+	}
+
+	value, err := e.Evaluate(e.survey.ResultRules)
+	if err != nil {
+		return nil, nil, errors.WrapErrorAction(logutils.ActionApply, TypeRule, logutils.StringArgs("result_rules"), err)
+	}
+	if e.resultSet {
+		return e.result, e.actions, nil
+	}
+	return value, e.actions, nil
+}
+
+func (e *Engine) setResult(dataKey string, data interface{}) {
+	if dataKey == "" {
+		e.result = data
+	} else {
+		result, ok := e.result.(map[string]interface{})
+		if !ok {
+			result = map[string]interface{}{}
+		}
+		result[dataKey] = data
+		e.result = result
+	}
+	e.resultSet = true
+}
+
+func (e *Engine) subRule(key string) (Result, error) {
+	if rule, ok := e.subRules[key]; ok {
+		return rule, nil
+	}
+	data, ok := e.survey.SubRules[key]
+	if !ok {
+		return nil, errors.ErrorData(logutils.StatusMissing, TypeRule, &logutils.FieldArgs{"rule_key": key})
+	}
+	rule, err := ParseData(data)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionParse, TypeRule, &logutils.FieldArgs{"rule_key": key}, err)
+	}
+	e.subRules[key] = rule
+	return rule, nil
+}
+
+// resolve replaces references and nested rules in the provided value with the values they evaluate to
+func (e *Engine) resolve(value interface{}, depth int) (interface{}, error) {
+	if depth > maxEvaluationDepth {
+		return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, logutils.StringArgs("maximum depth exceeded"))
+	}
+
+	value = utils.NormalizeValue(value)
+	switch typed := value.(type) {
+	case string:
+		if IsReference(typed) {
+			return e.reference(typed), nil
+		}
+	case []interface{}:
+		resolved := make([]interface{}, len(typed))
+		for i, item := range typed {
+			itemValue, err := e.resolve(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = itemValue
+		}
+		return resolved, nil
+	case map[string]interface{}:
+		if isResultData(typed) {
+			result, err := parseResult(typed)
+			if err != nil {
+				return nil, err
+			}
+			return result.evaluate(e, depth+1)
+		}
+	}
+	return value, nil
+}
+
+// reference returns the value of the provided reference, or nil if it does not exist
+func (e *Engine) reference(key string) interface{} {
+	prefix, path, _ := strings.Cut(key, ".")
+	switch prefix {
+	case referenceData:
+		dataKey, field, _ := strings.Cut(path, ".")
+		if _, ok := e.survey.Data[dataKey]; !ok {
+			return nil
+		}
+		entry := map[string]interface{}{"response": e.responses[dataKey]}
+		if score, ok := e.scores[dataKey]; ok {
+			entry["score"] = score
+		}
+		if field == "" {
+			field = "response"
+		}
+		return lookup(entry, field)
+	case referenceStats:
+		if e.stats == nil {
+			return nil
+		}
+		stats := map[string]interface{}{"total": e.stats.Total, "complete": e.stats.Complete, "scored": e.stats.Scored,
+			"scores": utils.NormalizeValue(e.stats.Scores), "maximum_scores": utils.NormalizeValue(e.stats.MaximumScores),
+			"response_data": utils.NormalizeValue(e.stats.ResponseData)}
+		return lookup(stats, path)
+	case referenceConstants:
+		return lookup(utils.NormalizeValue(e.survey.Constants), path)
+	case referenceStrings:
+		return lookup(utils.NormalizeValue(e.survey.Strings), path)
+	}
+	return nil
+}
+
+// IsReference returns true if the provided value references survey data, stats, constants or strings
+func IsReference(value string) bool {
+	prefix, path, found := strings.Cut(value, ".")
+	if !found || path == "" {
+		return false
+	}
+	switch prefix {
+	case referenceData, referenceStats, referenceConstants, referenceStrings:
+		return true
+	}
+	return false
+}
+
+// lookup returns the value at the provided dot separated path within value
+func lookup(value interface{}, path string) interface{} {
+	if path == "" {
+		return value
+	}
+	for _, part := range strings.Split(path, ".") {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			value = typed[part]
+		case []interface{}:
+			var index int
+			if _, err := fmt.Sscanf(part, "%d", &index); err != nil || index < 0 || index >= len(typed) {
+				return nil
+			}
+			value = typed[index]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func isResultData(data map[string]interface{}) bool {
+	for _, key := range []string{"condition", "cases", "actions", "rule_key", "action"} {
+		if _, ok := data[key]; ok {
+			return true
+		}
+	}
+	return false
+}
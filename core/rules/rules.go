@@ -0,0 +1,480 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rules parses and evaluates the rules stored in surveys (result rules, sub rules, follow-up rules, score rules
+// and default response rules) so the service can interpret them the same way the client applications do.
+//
+// A rule result is one of:
+//   - a rule: {"condition": <condition>, "true_result": <result>, "false_result": <result>}
+//   - cases: {"cases": [<rule>, ...]}, evaluating to the true result of the first rule whose condition holds
+//   - an action list: {"actions": [<action>, ...]}
+//   - a reference: {"rule_key": "<sub rule key>"}
+//   - an action: {"action": "return" | "sum" | "set_result" | ..., "data": <value>, "data_key": "<key>"}
+//   - a list of rule results, which are evaluated in order
+//
+// A condition is either a comparison {"operator": "==", "data_key": "<reference>", "compare_to": <value>, "default_result": false}
+// or logic {"operator": "and" | "or", "conditions": [<condition>, ...]}.
+//
+// Values may reference survey data using the prefixes "data.", "stats.", "constants." and "strings."
+// (eg. "data.question1.response", "stats.scores.section1" or "constants.threshold").
+package rules
+
+import (
+	"application/utils"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	// TypeRule rule type
+	TypeRule logutils.MessageDataType = "rule"
+
+	// ActionReturn returns the evaluated data
+	ActionReturn string = "return"
+	// ActionSum returns the sum of the evaluated data
+	ActionSum string = "sum"
+	// ActionSetResult sets the evaluated data as the result of the survey
+	ActionSetResult string = "set_result"
+
+	// OperatorAnd is the logic operator requiring all conditions to hold
+	OperatorAnd string = "and"
+	// OperatorOr is the logic operator requiring any condition to hold
+	OperatorOr string = "or"
+
+	maxEvaluationDepth = 32
+)
+
+// Result is a rule element that evaluates to a value
+type Result interface {
+	evaluate(e *Engine, depth int) (interface{}, error)
+}
+
+// Condition is a rule element that evaluates to a boolean
+type Condition interface {
+	check(e *Engine, depth int) (bool, error)
+}
+
+// Rule evaluates one of two results depending on a condition
+type Rule struct {
+	Condition   Condition
+	TrueResult  Result
+	FalseResult Result
+}
+
+// Cases evaluates the true result of the first rule whose condition holds
+type Cases struct {
+	Cases []Rule
+}
+
+// Reference evaluates the sub rule with the provided key
+type Reference struct {
+	RuleKey string
+}
+
+// Action is a rule action
+type Action struct {
+	Action  string
+	Data    interface{}
+	DataKey string
+}
+
+// ActionList evaluates a list of actions
+type ActionList struct {
+	Actions []Action
+}
+
+// List evaluates a list of rule results
+type List struct {
+	Results []Result
+}
+
+// Comparison compares a referenced value to another value
+type Comparison struct {
+	Operator      string
+	DataKey       string
+	CompareTo     interface{}
+	DefaultResult bool
+}
+
+// Logic combines conditions
+type Logic struct {
+	Operator   string
+	Conditions []Condition
+}
+
+// Parse parses the provided rule JSON string
+func Parse(rule string) (Result, error) {
+	var data interface{}
+	err := json.Unmarshal([]byte(rule), &data)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionUnmarshal, TypeRule, nil, err)
+	}
+	return ParseData(data)
+}
+
+// ParseData parses a rule result from decoded rule JSON. Rules stored as JSON strings are parsed as well.
+func ParseData(data interface{}) (Result, error) {
+	data = utils.NormalizeValue(data)
+	switch value := data.(type) {
+	case string:
+		return Parse(value)
+	case []interface{}:
+		results := make([]Result, len(value))
+		for i, item := range value {
+			result, err := ParseData(item)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = result
+		}
+		return List{Results: results}, nil
+	case map[string]interface{}:
+		return parseResult(value)
+	}
+	return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, &logutils.FieldArgs{"type": fmt.Sprintf("%T", data)})
+}
+
+func parseResult(data map[string]interface{}) (Result, error) {
+	if _, ok := data["condition"]; ok {
+		rule, err := parseRule(data)
+		if err != nil {
+			return nil, err
+		}
+		return *rule, nil
+	}
+	if cases, ok := data["cases"].([]interface{}); ok {
+		rules := make([]Rule, len(cases))
+		for i, item := range cases {
+			caseData, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, &logutils.FieldArgs{"cases": i})
+			}
+			rule, err := parseRule(caseData)
+			if err != nil {
+				return nil, err
+			}
+			rules[i] = *rule
+		}
+		return Cases{Cases: rules}, nil
+	}
+	if actions, ok := data["actions"].([]interface{}); ok {
+		list := ActionList{Actions: make([]Action, len(actions))}
+		for i, item := range actions {
+			actionData, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, &logutils.FieldArgs{"actions": i})
+			}
+			action, err := parseAction(actionData)
+			if err != nil {
+				return nil, err
+			}
+			list.Actions[i] = *action
+		}
+		return list, nil
+	}
+	if ruleKey, ok := data["rule_key"].(string); ok {
+		return Reference{RuleKey: ruleKey}, nil
+	}
+	if _, ok := data["action"]; ok {
+		action, err := parseAction(data)
+		if err != nil {
+			return nil, err
+		}
+		return *action, nil
+	}
+	return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, logutils.StringArgs("unknown rule result"))
+}
+
+func parseRule(data map[string]interface{}) (*Rule, error) {
+	conditionData, ok := data["condition"].(map[string]interface{})
+	if !ok {
+		return nil, errors.ErrorData(logutils.StatusMissing, TypeRule, logutils.StringArgs("condition"))
+	}
+	condition, err := parseCondition(conditionData)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := Rule{Condition: condition}
+	if trueResult, ok := data["true_result"]; ok && trueResult != nil {
+		rule.TrueResult, err = ParseData(trueResult)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if falseResult, ok := data["false_result"]; ok && falseResult != nil {
+		rule.FalseResult, err = ParseData(falseResult)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &rule, nil
+}
+
+func parseCondition(data map[string]interface{}) (Condition, error) {
+	operator, _ := data["operator"].(string)
+	if conditions, ok := data["conditions"].([]interface{}); ok {
+		if operator != OperatorAnd && operator != OperatorOr {
+			return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, &logutils.FieldArgs{"operator": operator})
+		}
+		logic := Logic{Operator: operator, Conditions: make([]Condition, len(conditions))}
+		for i, item := range conditions {
+			conditionData, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, &logutils.FieldArgs{"conditions": i})
+			}
+			condition, err := parseCondition(conditionData)
+			if err != nil {
+				return nil, err
+			}
+			logic.Conditions[i] = condition
+		}
+		return logic, nil
+	}
+
+	if !isComparisonOperator(operator) {
+		return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, &logutils.FieldArgs{"operator": operator})
+	}
+	dataKey, ok := data["data_key"].(string)
+	if !ok {
+		return nil, errors.ErrorData(logutils.StatusMissing, TypeRule, logutils.StringArgs("data_key"))
+	}
+	defaultResult, _ := data["default_result"].(bool)
+	return Comparison{Operator: operator, DataKey: dataKey, CompareTo: data["compare_to"], DefaultResult: defaultResult}, nil
+}
+
+func parseAction(data map[string]interface{}) (*Action, error) {
+	action, ok := data["action"].(string)
+	if !ok || action == "" {
+		return nil, errors.ErrorData(logutils.StatusMissing, TypeRule, logutils.StringArgs("action"))
+	}
+	dataKey, _ := data["data_key"].(string)
+	return &Action{Action: action, Data: data["data"], DataKey: dataKey}, nil
+}
+
+func (r Rule) evaluate(e *Engine, depth int) (interface{}, error) {
+	if depth > maxEvaluationDepth {
+		return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, logutils.StringArgs("maximum depth exceeded"))
+	}
+	result, err := r.Condition.check(e, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	if result && r.TrueResult != nil {
+		return r.TrueResult.evaluate(e, depth+1)
+	}
+	if !result && r.FalseResult != nil {
+		return r.FalseResult.evaluate(e, depth+1)
+	}
+	return nil, nil
+}
+
+func (c Cases) evaluate(e *Engine, depth int) (interface{}, error) {
+	for _, rule := range c.Cases {
+		result, err := rule.Condition.check(e, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if result {
+			if rule.TrueResult == nil {
+				return nil, nil
+			}
+			return rule.TrueResult.evaluate(e, depth+1)
+		}
+	}
+	return nil, nil
+}
+
+func (r Reference) evaluate(e *Engine, depth int) (interface{}, error) {
+	if depth > maxEvaluationDepth {
+		return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, &logutils.FieldArgs{"rule_key": r.RuleKey, "depth": depth})
+	}
+	subRule, err := e.subRule(r.RuleKey)
+	if err != nil {
+		return nil, err
+	}
+	return subRule.evaluate(e, depth+1)
+}
+
+func (a Action) evaluate(e *Engine, depth int) (interface{}, error) {
+	data, err := e.resolve(a.Data, depth+1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch a.Action {
+	case ActionReturn:
+		return data, nil
+	case ActionSum:
+		sum := 0.0
+		for _, item := range listValues(data) {
+			number, ok := utils.ToFloat64(item)
+			if !ok {
+				return nil, errors.ErrorData(logutils.StatusInvalid, TypeRule, &logutils.FieldArgs{"action": a.Action, "value": item})
+			}
+			sum += number
+		}
+		return sum, nil
+	case ActionSetResult:
+		e.setResult(a.DataKey, data)
+		return data, nil
+	default:
+		// actions with side effects on the client (eg. alerts, notifications) are recorded but not executed
+		e.actions = append(e.actions, ActionResult{Action: a.Action, Data: data, DataKey: a.DataKey})
+		return nil, nil
+	}
+}
+
+func (l ActionList) evaluate(e *Engine, depth int) (interface{}, error) {
+	var last interface{}
+	for _, action := range l.Actions {
+		value, err := action.evaluate(e, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if action.Action == ActionReturn {
+			return value, nil
+		}
+		if value != nil {
+			last = value
+		}
+	}
+	return last, nil
+}
+
+func (l List) evaluate(e *Engine, depth int) (interface{}, error) {
+	var last interface{}
+	for _, result := range l.Results {
+		value, err := result.evaluate(e, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			last = value
+		}
+	}
+	return last, nil
+}
+
+func (c Comparison) check(e *Engine, depth int) (bool, error) {
+	value, err := e.resolve(c.DataKey, depth+1)
+	if err != nil {
+		return false, err
+	}
+	compareTo, err := e.resolve(c.CompareTo, depth+1)
+	if err != nil {
+		return false, err
+	}
+	if value == nil && c.Operator != "==" && c.Operator != "!=" {
+		return c.DefaultResult, nil
+	}
+
+	switch c.Operator {
+	case "==":
+		return utils.ValuesEqual(value, compareTo), nil
+	case "!=":
+		return !utils.ValuesEqual(value, compareTo), nil
+	case "<", "<=", ">", ">=":
+		a, aOk := utils.ToFloat64(value)
+		b, bOk := utils.ToFloat64(compareTo)
+		if !aOk || !bOk {
+			return c.DefaultResult, nil
+		}
+		switch c.Operator {
+		case "<":
+			return a < b, nil
+		case "<=":
+			return a <= b, nil
+		case ">":
+			return a > b, nil
+		default:
+			return a >= b, nil
+		}
+	case "in":
+		return containsValue(listValues(compareTo), value), nil
+	case "not_in":
+		return !containsValue(listValues(compareTo), value), nil
+	case "contains":
+		if text, ok := value.(string); ok {
+			substring, ok := compareTo.(string)
+			return ok && strings.Contains(text, substring), nil
+		}
+		return containsValue(listValues(value), compareTo), nil
+	case "any":
+		for _, item := range listValues(compareTo) {
+			if containsValue(listValues(value), item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "all":
+		for _, item := range listValues(compareTo) {
+			if !containsValue(listValues(value), item) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return c.DefaultResult, nil
+}
+
+func (l Logic) check(e *Engine, depth int) (bool, error) {
+	if depth > maxEvaluationDepth {
+		return false, errors.ErrorData(logutils.StatusInvalid, TypeRule, logutils.StringArgs("maximum depth exceeded"))
+	}
+	for _, condition := range l.Conditions {
+		result, err := condition.check(e, depth+1)
+		if err != nil {
+			return false, err
+		}
+		if l.Operator == OperatorOr && result {
+			return true, nil
+		}
+		if l.Operator == OperatorAnd && !result {
+			return false, nil
+		}
+	}
+	return l.Operator == OperatorAnd, nil
+}
+
+func isComparisonOperator(operator string) bool {
+	switch operator {
+	case "==", "!=", "<", "<=", ">", ">=", "in", "not_in", "contains", "any", "all":
+		return true
+	}
+	return false
+}
+
+func listValues(value interface{}) []interface{} {
+	if value == nil {
+		return nil
+	}
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{value}
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if utils.ValuesEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
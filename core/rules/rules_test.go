@@ -0,0 +1,120 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules_test
+
+import (
+	"application/core/model"
+	"application/core/rules"
+	"reflect"
+	"testing"
+)
+
+func TestEngine_Evaluate(t *testing.T) {
+	survey := model.Survey{
+		Data: map[string]model.SurveyData{
+			"mood":  {Type: model.SurveyDataTypeMultipleChoice},
+			"sleep": {Type: model.SurveyDataTypeNumeric},
+		},
+		Constants: map[string]interface{}{"threshold": 7},
+		SubRules: map[string]interface{}{
+			"well_rested": map[string]interface{}{
+				"condition":    map[string]interface{}{"operator": ">=", "data_key": "data.sleep", "compare_to": "constants.threshold"},
+				"true_result":  map[string]interface{}{"action": "return", "data": "yes"},
+				"false_result": map[string]interface{}{"action": "return", "data": "no"},
+			},
+			"loop": map[string]interface{}{"rule_key": "loop"},
+		},
+	}
+	data := map[string]model.SurveyData{"mood": {Response: []interface{}{"happy", "calm"}}, "sleep": {Response: 8.0}}
+
+	tests := []struct {
+		name    string
+		rule    string
+		want    interface{}
+		wantErr bool
+	}{
+		{"return literal", `{"action": "return", "data": "done"}`, "done", false},
+		{"return reference", `{"action": "return", "data": "data.sleep"}`, 8.0, false},
+		{"sum", `{"action": "sum", "data": ["data.sleep", 2]}`, 10.0, false},
+		{"sub rule", `{"rule_key": "well_rested"}`, "yes", false},
+		{"contains", `{"condition": {"operator": "contains", "data_key": "data.mood", "compare_to": "calm"}, "true_result": {"action": "return", "data": 1}}`, 1.0, false},
+		{"logic", `{"condition": {"operator": "and", "conditions": [{"operator": "<", "data_key": "data.sleep", "compare_to": 9}, {"operator": "in", "data_key": "data.sleep", "compare_to": [7, 8]}]}, "true_result": {"action": "return", "data": true}, "false_result": {"action": "return", "data": false}}`, true, false},
+		{"cases", `{"cases": [{"condition": {"operator": "<", "data_key": "data.sleep", "compare_to": 6}, "true_result": {"action": "return", "data": "low"}}, {"condition": {"operator": ">=", "data_key": "data.sleep", "compare_to": 6}, "true_result": {"action": "return", "data": "ok"}}]}`, "ok", false},
+		{"missing value default", `{"condition": {"operator": ">", "data_key": "data.other", "compare_to": 1, "default_result": true}, "true_result": {"action": "return", "data": "default"}}`, "default", false},
+		{"unknown sub rule", `{"rule_key": "unknown"}`, nil, true},
+		{"recursive sub rule", `{"rule_key": "loop"}`, nil, true},
+		{"invalid json", `{"action": `, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rules.NewEngine(survey, data).Evaluate(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Engine.Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Engine.Evaluate() = %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Path(t *testing.T) {
+	first, second, third := "q1", "q2", "q3"
+	followUpRule := `{"condition": {"operator": "==", "data_key": "data.q1", "compare_to": true}, "true_result": {"action": "return", "data": "q3"}}`
+	survey := model.Survey{DefaultDataKey: &first, Data: map[string]model.SurveyData{
+		first:  {Type: model.SurveyDataTypeTrueFalse, FollowUpRule: &followUpRule, DefaultFollowUpKey: &second},
+		second: {Type: model.SurveyDataTypeText, DefaultFollowUpKey: &third},
+		third:  {Type: model.SurveyDataTypeText},
+	}}
+
+	tests := []struct {
+		name string
+		data map[string]model.SurveyData
+		want []string
+	}{
+		{"follow-up rule", map[string]model.SurveyData{first: {Response: true}}, []string{first, third}},
+		{"default follow-up", map[string]model.SurveyData{first: {Response: false}}, []string{first, second, third}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rules.NewEngine(survey, tt.data).Path()
+			if err != nil {
+				t.Fatalf("Engine.Path() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Engine.Path() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Results(t *testing.T) {
+	survey := model.Survey{
+		Data:        map[string]model.SurveyData{"score": {Type: model.SurveyDataTypeNumeric}},
+		ResultRules: `[{"action": "set_result", "data_key": "level", "data": {"condition": {"operator": ">", "data_key": "data.score", "compare_to": 5}, "true_result": {"action": "return", "data": "high"}, "false_result": {"action": "return", "data": "low"}}}, {"action": "alert", "data": "data.score"}]`,
+	}
+	engine := rules.NewEngine(survey, map[string]model.SurveyData{"score": {Response: 9.0}})
+
+	result, actions, err := engine.Results()
+	if err != nil {
+		t.Fatalf("Engine.Results() error = %v", err)
+	}
+	if want := map[string]interface{}{"level": "high"}; !reflect.DeepEqual(result, want) {
+		t.Errorf("Engine.Results() result = %v, want %v", result, want)
+	}
+	if want := []rules.ActionResult{{Action: "alert", Data: 9.0}}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("Engine.Results() actions = %v, want %v", actions, want)
+	}
+}
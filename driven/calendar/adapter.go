@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/rokwire/core-auth-library-go/v3/authservice"
 	"github.com/rokwire/logging-library-go/v2/errors"
@@ -19,6 +20,8 @@ const (
 
 	// TypeCalendarUser calendar.User type
 	TypeCalendarUser logutils.MessageDataType = "calendar user"
+	// TypeCalendarEvent calendar.Event type
+	TypeCalendarEvent logutils.MessageDataType = "calendar event"
 )
 
 // Adapter implements the Calendar interface
@@ -47,6 +50,13 @@ type User struct {
 	ExternalID string `json:"external_id"`
 }
 
+// Event is defined from Calendar API
+type Event struct {
+	ID        string     `json:"id"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+}
+
 // NewCalendarAdapter creates a new Calendar BB adapter instance
 func NewCalendarAdapter(notificationHost string, serviceAccountManager *authservice.ServiceAccountManager, logger *logs.Logger) (*Adapter, error) {
 	return &Adapter{baseURL: notificationHost, serviceAccountManager: serviceAccountManager, logger: logger}, nil
@@ -61,6 +71,51 @@ func (a *Adapter) GetEventUsers(orgID string, appID string, eventID string, user
 	return a.getEventUsers(orgID, appID, eventID, users, registered, role, attended)
 }
 
+// GetEvent gets the event through Calendar BB. It returns nil if the event does not exist.
+func (a *Adapter) GetEvent(orgID string, appID string, eventID string) (*Event, error) {
+	if a.serviceAccountManager == nil {
+		return nil, errors.Newf("service account manager is nil")
+	}
+
+	return a.getEvent(orgID, appID, eventID)
+}
+
+// gets the event through Calendar BB
+func (a *Adapter) getEvent(orgID string, appID string, eventID string) (*Event, error) {
+	url := fmt.Sprintf("%s/api/bbs/event/%s", a.baseURL, eventID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionCreate, logutils.TypeRequest, nil, err)
+	}
+
+	resp, err := a.serviceAccountManager.MakeRequest(req, appID, orgID)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionSend, logutils.TypeRequest, nil, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Newf("request error response code (%d)", resp.Status)
+	}
+	if resp.StatusCode != 200 {
+		return nil, errors.Newf("request error response code (%d): %s", resp.Status, respBytes)
+	}
+
+	var event Event
+	err = json.Unmarshal(respBytes, &event)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionUnmarshal, logutils.TypeResponseBody, nil, err)
+	}
+
+	return &event, nil
+}
+
 // gets the event users through Calendar BB
 func (a *Adapter) getEventUsers(orgID string, appID string, eventID string, users []User, registered *bool, role string, attended *bool) ([]EventPerson, error) {
 	url := fmt.Sprintf("%s/api/bbs/event/%s/users", a.baseURL, eventID)
@@ -39,7 +39,7 @@ func (a *Adapter) SendNotification(notification model.NotificationMessage) {
 
 // SendNotification sends notification to a user
 func (a *Adapter) sendNotification(message model.NotificationMessage) error {
-	if len(message.Recipients) == 0 && len(message.RecipientsCriteriaList) == 0 && len(message.RecipientAccountCriteria) == 0 {
+	if len(message.Recipients) == 0 && len(message.RecipientsCriteriaList) == 0 && len(message.RecipientAccountCriteria) == 0 && message.Topic == nil {
 		return nil
 	}
 	url := fmt.Sprintf("%s/api/bbs/message", a.baseURL)
@@ -0,0 +1,82 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// requestTimeout is how long the SMS provider has to respond
+const requestTimeout = 10 * time.Second
+
+// Adapter implements the SMS interface. Messages are posted as JSON (`to`, `from`, `body`) to the provider URL with the
+// provider token as bearer token.
+type Adapter struct {
+	providerURL string
+	token       string
+	fromPhone   string
+
+	client *http.Client
+}
+
+// NewSMSAdapter creates a new SMS provider adapter instance. Text messages cannot be sent if the provider URL is empty.
+func NewSMSAdapter(providerURL string, token string, fromPhone string) *Adapter {
+	return &Adapter{providerURL: providerURL, token: token, fromPhone: fromPhone, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// SendSMS sends a text message to the phone number through the SMS provider
+func (a *Adapter) SendSMS(toPhone string, body string) error {
+	if a.providerURL == "" {
+		return errors.Newf("sms provider is not configured")
+	}
+
+	bodyData := map[string]string{
+		"to":   toPhone,
+		"from": a.fromPhone,
+		"body": body,
+	}
+	bodyBytes, err := json.Marshal(bodyData)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionMarshal, logutils.TypeRequestBody, nil, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.providerURL, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionCreate, logutils.TypeRequest, nil, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionSend, logutils.TypeRequest, nil, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Newf("sms provider error response code (%d): %s", resp.StatusCode, respBytes)
+	}
+	return nil
+}
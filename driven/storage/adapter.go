@@ -18,6 +18,7 @@ import (
 	"application/core/interfaces"
 	"application/core/model"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"strconv"
 	"strings"
@@ -42,6 +43,8 @@ type Adapter struct {
 
 	cachedConfigs *syncmap.Map
 	configsLock   *sync.RWMutex
+
+	keyring *dataKeyring
 }
 
 // Start starts the storage
@@ -71,7 +74,7 @@ func (a *Adapter) RegisterStorageListener(listener interfaces.StorageListener) {
 
 // Creates a new Adapter with provided context
 func (a *Adapter) withContext(context mongo.SessionContext) *Adapter {
-	return &Adapter{db: a.db, context: context, cachedConfigs: a.cachedConfigs, configsLock: a.configsLock}
+	return &Adapter{db: a.db, context: context, cachedConfigs: a.cachedConfigs, configsLock: a.configsLock, keyring: a.keyring}
 }
 
 // cacheConfigs caches the configs from the DB
@@ -99,6 +102,8 @@ func (a *Adapter) setCachedConfigs(configs []model.Config) {
 		switch config.Type {
 		case model.ConfigTypeEnv:
 			err = parseConfigsData[model.EnvConfigData](&config)
+		case model.ConfigTypePrivacy:
+			err = parseConfigsData[model.PrivacyConfigData](&config)
 		default:
 			err = parseConfigsData[map[string]interface{}](&config)
 		}
@@ -321,7 +326,8 @@ func filterArgs(filter bson.M) *logutils.FieldArgs {
 }
 
 // NewStorageAdapter creates a new storage adapter instance
-func NewStorageAdapter(mongoDBAuth string, mongoDBName string, mongoTimeout string, logger *logs.Logger) *Adapter {
+// The data of responses to sensitive surveys is encrypted if a base64 encoded 256-bit master key is provided.
+func NewStorageAdapter(mongoDBAuth string, mongoDBName string, mongoTimeout string, masterKey string, logger *logs.Logger) *Adapter {
 	timeout, err := strconv.Atoi(mongoTimeout)
 	if err != nil {
 		logger.Infof("Set default timeout - 2000")
@@ -331,6 +337,16 @@ func NewStorageAdapter(mongoDBAuth string, mongoDBName string, mongoTimeout stri
 	cachedConfigs := &syncmap.Map{}
 	configsLock := &sync.RWMutex{}
 
+	keyring := &dataKeyring{keys: &syncmap.Map{}}
+	if masterKey != "" {
+		keyring.masterKey, err = base64.StdEncoding.DecodeString(masterKey)
+		if err != nil || len(keyring.masterKey) != 32 {
+			logger.Fatalf("Invalid data encryption master key, a base64 encoded 256-bit key is required")
+		}
+	} else {
+		logger.Warn("No data encryption master key, responses to sensitive surveys are stored unencrypted")
+	}
+
 	db := &database{mongoDBAuth: mongoDBAuth, mongoDBName: mongoDBName, mongoTimeout: time.Millisecond * time.Duration(timeout), logger: logger}
-	return &Adapter{db: db, cachedConfigs: cachedConfigs, configsLock: configsLock}
+	return &Adapter{db: db, cachedConfigs: cachedConfigs, configsLock: configsLock, keyring: keyring}
 }
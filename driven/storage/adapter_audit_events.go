@@ -0,0 +1,72 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"application/core/model"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetAuditEvents retrieves the audit events of an app/org matching the provided filter, newest first
+func (a *Adapter) GetAuditEvents(orgID string, appID string, auditFilter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID}
+	if auditFilter.Entity != nil {
+		filter["entity"] = *auditFilter.Entity
+	}
+	if auditFilter.EntityID != nil {
+		filter["entity_id"] = *auditFilter.EntityID
+	}
+	if auditFilter.ActorID != nil {
+		filter["actor.account_id"] = *auditFilter.ActorID
+	}
+	if auditFilter.StartDate != nil || auditFilter.EndDate != nil {
+		dateFilter := bson.M{}
+		if auditFilter.StartDate != nil {
+			dateFilter["$gte"] = auditFilter.StartDate
+		}
+		if auditFilter.EndDate != nil {
+			dateFilter["$lt"] = auditFilter.EndDate
+		}
+		filter["date_created"] = dateFilter
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date_created", Value: -1}, {Key: "_id", Value: 1}})
+	if auditFilter.Limit != nil {
+		opts.SetLimit(int64(*auditFilter.Limit))
+	}
+	if auditFilter.Offset != nil {
+		opts.SetSkip(int64(*auditFilter.Offset))
+	}
+
+	results := make([]model.AuditEvent, 0)
+	err := a.db.auditEvents.Find(a.context, filter, &results, opts)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeAuditEvent, filterArgs(filter), err)
+	}
+	return results, nil
+}
+
+// InsertAuditEvent inserts an audit event
+func (a *Adapter) InsertAuditEvent(auditEvent model.AuditEvent) error {
+	_, err := a.db.auditEvents.InsertOne(a.context, auditEvent)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionInsert, model.TypeAuditEvent, nil, err)
+	}
+	return nil
+}
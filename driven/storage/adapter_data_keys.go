@@ -0,0 +1,436 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"application/core/model"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/syncmap"
+)
+
+// dataKeyring encrypts the data of responses to sensitive surveys with per-org data keys. The data keys are stored
+// wrapped by the master key, which is never stored.
+type dataKeyring struct {
+	masterKey []byte // nil if encryption is disabled
+
+	// keys caches the unwrapped data keys by ID
+	keys *syncmap.Map
+}
+
+func (k *dataKeyring) enabled() bool {
+	return k != nil && k.masterKey != nil
+}
+
+// GetDataKeys gets the data keys of an org
+func (a *Adapter) GetDataKeys(orgID string) ([]model.DataKey, error) {
+	filter := bson.M{"org_id": orgID}
+	var keys []model.DataKey
+	err := a.db.dataKeys.Find(a.context, filter, &keys, options.Find().SetSort(bson.M{"version": 1}))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeDataKey, filterArgs(filter), err)
+	}
+	return keys, nil
+}
+
+// RotateDataKey creates a new active data key for an org. Data encrypted with previous keys can still be decrypted.
+func (a *Adapter) RotateDataKey(orgID string) (*model.DataKey, error) {
+	if !a.keyring.enabled() {
+		return nil, errors.ErrorData(logutils.StatusMissing, "master key", nil)
+	}
+
+	current, err := a.findActiveDataKey(orgID)
+	if err != nil {
+		return nil, err
+	}
+	version := 1
+	if current != nil {
+		version = current.Version + 1
+	}
+
+	key, err := a.createDataKey(orgID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"org_id": orgID, "_id": bson.M{"$ne": key.ID}}
+	_, err = a.db.dataKeys.UpdateMany(a.context, filter, bson.M{"$set": bson.M{"active": false}}, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeDataKey, filterArgs(filter), err)
+	}
+	return key, nil
+}
+
+// GetDataKeysToReencrypt gets the active data keys the responses of their org have not all been re-encrypted with yet
+func (a *Adapter) GetDataKeysToReencrypt() ([]model.DataKey, error) {
+	filter := bson.M{"active": true, "reencrypted_at": nil}
+	var keys []model.DataKey
+	err := a.db.dataKeys.Find(a.context, filter, &keys, options.Find().SetSort(bson.M{"date_created": 1}))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeDataKey, filterArgs(filter), err)
+	}
+	return keys, nil
+}
+
+// SetDataKeyReencrypted records that the responses of the org of a data key have all been re-encrypted with it
+func (a *Adapter) SetDataKeyReencrypted(id string, date time.Time) error {
+	filter := bson.M{"_id": id}
+	_, err := a.db.dataKeys.UpdateOne(a.context, filter, bson.M{"$set": bson.M{"reencrypted_at": date}}, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeDataKey, filterArgs(filter), err)
+	}
+	return nil
+}
+
+// CountSurveyResponsesToReencrypt counts the responses of an org to sensitive surveys that are not encrypted with the
+// active data key of the org
+func (a *Adapter) CountSurveyResponsesToReencrypt(orgID string) (int64, error) {
+	if !a.keyring.enabled() {
+		return 0, errors.ErrorData(logutils.StatusMissing, "master key", nil)
+	}
+	key, err := a.activeDataKey(orgID)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := reencryptionFilter(orgID, key.ID)
+	count, err := a.db.surveyResponses.CountDocuments(a.context, filter)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionCount, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return count, nil
+}
+
+// ReencryptSurveyResponses encrypts up to limit responses of an org to sensitive surveys with the active data key of
+// the org and returns the number of responses processed. Responses stored in plaintext before encryption was enabled
+// are encrypted as well. Encrypted responses are not matched again, so the re-encryption can be resumed at any time.
+func (a *Adapter) ReencryptSurveyResponses(orgID string, limit int) (int64, error) {
+	if !a.keyring.enabled() {
+		return 0, errors.ErrorData(logutils.StatusMissing, "master key", nil)
+	}
+	key, err := a.activeDataKey(orgID)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := reencryptionFilter(orgID, key.ID)
+	var ids []string
+	opts := options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(int64(limit))
+	err = a.db.surveyResponses.FindEach(a.context, filter, opts, func(cur *mongo.Cursor) error {
+		var entry struct {
+			ID string `bson:"_id"`
+		}
+		err := cur.Decode(&entry)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionDecode, model.TypeSurveyResponse, nil, err)
+		}
+		ids = append(ids, entry.ID)
+		return nil
+	})
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+
+	for _, id := range ids {
+		_, err := a.reencryptSurveyResponse(id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(ids)), nil
+}
+
+// reencryptionFilter matches the responses of an org to sensitive surveys that are not encrypted with the data key or
+// still hold sensitive fields in plaintext
+func reencryptionFilter(orgID string, keyID string) bson.M {
+	return bson.M{"org_id": orgID, "survey.sensitive": true, "$or": bson.A{
+		bson.M{"survey.encrypted_data.key_id": bson.M{"$ne": keyID}},
+		bson.M{"survey.stats": bson.M{"$ne": nil}},
+		bson.M{"survey.result_json": bson.M{"$nin": bson.A{nil, ""}}},
+		bson.M{"client_stats": bson.M{"$ne": nil}},
+	}}
+}
+
+// reencryptSurveyResponse encrypts a survey response with the active data key of its org. The response is only updated
+// if it has not changed since it was read.
+func (a *Adapter) reencryptSurveyResponse(id string) (bool, error) {
+	filter := bson.M{"_id": id}
+	var results []model.SurveyResponse
+	err := a.db.surveyResponses.Find(a.context, filter, &results, options.Find().SetLimit(1))
+	if err != nil {
+		return false, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	if len(results) == 0 {
+		return false, nil
+	}
+
+	surveyResponse := results[0]
+	filter["date_updated"] = surveyResponse.DateUpdated
+	if surveyResponse.Survey.EncryptedData != nil {
+		filter["survey.encrypted_data.key_id"] = surveyResponse.Survey.EncryptedData.KeyID
+	} else {
+		filter["survey.encrypted_data"] = nil
+	}
+	err = a.decryptSurveyResponse(&surveyResponse)
+	if err != nil {
+		return false, err
+	}
+	err = a.encryptSurveyResponse(&surveyResponse)
+	if err != nil {
+		return false, err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"survey.data":           surveyResponse.Survey.Data,
+		"survey.stats":          surveyResponse.Survey.SurveyStats,
+		"survey.result_json":    surveyResponse.Survey.ResultJSON,
+		"survey.encrypted_data": surveyResponse.Survey.EncryptedData,
+	}, "$unset": bson.M{"client_stats": ""}}
+	res, err := a.db.surveyResponses.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		return false, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// encryptSurveyResponse replaces the data, stats, result and client stats of a response to a sensitive survey with
+// the same fields encrypted with the active data key of the org
+func (a *Adapter) encryptSurveyResponse(surveyResponse *model.SurveyResponse) error {
+	if !a.keyring.enabled() || !surveyResponse.Survey.Sensitive {
+		return nil
+	}
+
+	key, err := a.activeDataKey(surveyResponse.OrgID)
+	if err != nil {
+		return err
+	}
+	dataKey, err := a.unwrapDataKey(*key)
+	if err != nil {
+		return err
+	}
+	return sealSurveyResponse(surveyResponse, key.ID, dataKey)
+}
+
+// decryptSurveyResponse restores the fields of a survey response encrypted by encryptSurveyResponse
+func (a *Adapter) decryptSurveyResponse(surveyResponse *model.SurveyResponse) error {
+	encrypted := surveyResponse.Survey.EncryptedData
+	if encrypted == nil {
+		return nil
+	}
+	if !a.keyring.enabled() {
+		return errors.ErrorData(logutils.StatusMissing, "master key", nil)
+	}
+
+	key, err := a.findDataKey(encrypted.KeyID)
+	if err != nil {
+		return err
+	}
+	dataKey, err := a.unwrapDataKey(*key)
+	if err != nil {
+		return err
+	}
+	return openSurveyResponse(surveyResponse, dataKey)
+}
+
+func (a *Adapter) decryptSurveyResponses(surveyResponses []model.SurveyResponse) error {
+	for i := range surveyResponses {
+		err := a.decryptSurveyResponse(&surveyResponses[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encryptedSurveyData is the document encrypted in place of the sensitive fields of a survey response
+type encryptedSurveyData struct {
+	Data        map[string]model.SurveyData `bson:"data"`
+	Stats       *model.SurveyStats          `bson:"stats,omitempty"`
+	ResultJSON  string                      `bson:"result_json,omitempty"`
+	ClientStats *model.SurveyStats          `bson:"client_stats,omitempty"`
+}
+
+// sealSurveyResponse encrypts the sensitive fields of a survey response with a data key. The response ID is
+// authenticated with the fields, so encrypted fields cannot be moved to another response.
+func sealSurveyResponse(surveyResponse *model.SurveyResponse, keyID string, dataKey []byte) error {
+	plaintext, err := bson.Marshal(encryptedSurveyData{Data: surveyResponse.Survey.Data, Stats: surveyResponse.Survey.SurveyStats,
+		ResultJSON: surveyResponse.Survey.ResultJSON, ClientStats: surveyResponse.ClientStats})
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionMarshal, model.TypeSurveyResponse, nil, err)
+	}
+	ciphertext, err := seal(dataKey, plaintext, []byte(surveyResponse.ID))
+	if err != nil {
+		return errors.WrapErrorAction("encrypting", model.TypeSurveyResponse, nil, err)
+	}
+
+	surveyResponse.Survey.Data = nil
+	surveyResponse.Survey.SurveyStats = nil
+	surveyResponse.Survey.ResultJSON = ""
+	surveyResponse.ClientStats = nil
+	surveyResponse.Survey.EncryptedData = &model.EncryptedData{KeyID: keyID, Ciphertext: ciphertext}
+	return nil
+}
+
+// openSurveyResponse restores the sensitive fields of a survey response sealed with a data key. Fields stored in
+// plaintext by earlier versions are kept if they are not part of the encrypted document.
+func openSurveyResponse(surveyResponse *model.SurveyResponse, dataKey []byte) error {
+	plaintext, err := open(dataKey, surveyResponse.Survey.EncryptedData.Ciphertext, []byte(surveyResponse.ID))
+	if err != nil {
+		return errors.WrapErrorAction("decrypting", model.TypeSurveyResponse, &logutils.FieldArgs{"id": surveyResponse.ID}, err)
+	}
+	var data encryptedSurveyData
+	err = bson.Unmarshal(plaintext, &data)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUnmarshal, model.TypeSurveyResponse, nil, err)
+	}
+
+	surveyResponse.Survey.Data = data.Data
+	if data.Stats != nil {
+		surveyResponse.Survey.SurveyStats = data.Stats
+	}
+	if data.ResultJSON != "" {
+		surveyResponse.Survey.ResultJSON = data.ResultJSON
+	}
+	if data.ClientStats != nil {
+		surveyResponse.ClientStats = data.ClientStats
+	}
+	surveyResponse.Survey.EncryptedData = nil
+	return nil
+}
+
+// activeDataKey returns the active data key of an org, creating the first data key of the org if needed
+func (a *Adapter) activeDataKey(orgID string) (*model.DataKey, error) {
+	key, err := a.findActiveDataKey(orgID)
+	if err != nil || key != nil {
+		return key, err
+	}
+
+	key, err = a.createDataKey(orgID, 1)
+	if _, ok := err.(*model.DuplicateKeyError); ok {
+		// created by a concurrent request
+		return a.findActiveDataKey(orgID)
+	}
+	return key, err
+}
+
+func (a *Adapter) findActiveDataKey(orgID string) (*model.DataKey, error) {
+	filter := bson.M{"org_id": orgID, "active": true}
+	var keys []model.DataKey
+	err := a.db.dataKeys.Find(a.context, filter, &keys, options.Find().SetSort(bson.M{"version": -1}).SetLimit(1))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeDataKey, filterArgs(filter), err)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return &keys[0], nil
+}
+
+func (a *Adapter) findDataKey(id string) (*model.DataKey, error) {
+	filter := bson.M{"_id": id}
+	var keys []model.DataKey
+	err := a.db.dataKeys.Find(a.context, filter, &keys, options.Find().SetLimit(1))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeDataKey, filterArgs(filter), err)
+	}
+	if len(keys) == 0 {
+		return nil, errors.ErrorData(logutils.StatusMissing, model.TypeDataKey, filterArgs(filter))
+	}
+	return &keys[0], nil
+}
+
+// createDataKey generates a new active data key for an org and stores it wrapped by the master key
+func (a *Adapter) createDataKey(orgID string, version int) (*model.DataKey, error) {
+	dataKey := make([]byte, 32)
+	_, err := rand.Read(dataKey)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionCreate, model.TypeDataKey, nil, err)
+	}
+	id := uuid.NewString()
+	wrapped, err := seal(a.keyring.masterKey, dataKey, []byte(id))
+	if err != nil {
+		return nil, errors.WrapErrorAction("wrapping", model.TypeDataKey, nil, err)
+	}
+
+	key := model.DataKey{ID: id, OrgID: orgID, Version: version, Active: true, WrappedKey: wrapped, DateCreated: time.Now().UTC()}
+	_, err = a.db.dataKeys.InsertOne(a.context, key)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, &model.DuplicateKeyError{Type: string(model.TypeDataKey)}
+		}
+		return nil, errors.WrapErrorAction(logutils.ActionInsert, model.TypeDataKey, nil, err)
+	}
+	a.keyring.keys.Store(id, dataKey)
+	return &key, nil
+}
+
+func (a *Adapter) unwrapDataKey(key model.DataKey) ([]byte, error) {
+	if cached, ok := a.keyring.keys.Load(key.ID); ok {
+		if dataKey, ok := cached.([]byte); ok {
+			return dataKey, nil
+		}
+	}
+	dataKey, err := open(a.keyring.masterKey, key.WrappedKey, []byte(key.ID))
+	if err != nil {
+		return nil, errors.WrapErrorAction("unwrapping", model.TypeDataKey, &logutils.FieldArgs{"id": key.ID}, err)
+	}
+	a.keyring.keys.Store(key.ID, dataKey)
+	return dataKey, nil
+}
+
+// seal encrypts plaintext with AES-256-GCM and returns the nonce followed by the ciphertext
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts data encrypted by seal
+func open(key []byte, sealed []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"application/core/model"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	sealed, err := seal(key, []byte("secret"), []byte("response"))
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Errorf("seal() = %x, want ciphertext", sealed)
+	}
+
+	opened, err := open(key, sealed, []byte("response"))
+	if err != nil || string(opened) != "secret" {
+		t.Errorf("open() = %s, %v, want secret", opened, err)
+	}
+	if _, err = open(key, sealed, []byte("other")); err == nil {
+		t.Error("open() with other additional data error = nil, want error")
+	}
+	if _, err = open(bytes.Repeat([]byte{2}, 32), sealed, []byte("response")); err == nil {
+		t.Error("open() with other key error = nil, want error")
+	}
+	if _, err = open(key, sealed[:4], []byte("response")); err == nil {
+		t.Error("open() of truncated ciphertext error = nil, want error")
+	}
+}
+
+func TestSealOpenSurveyResponse(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	stats := model.SurveyStats{Total: 1, ResponseData: map[string]interface{}{"mood": "low"}}
+	clientStats := model.SurveyStats{Total: 2}
+	original := model.SurveyResponse{ID: "response", OrgID: "org", ClientStats: &clientStats, Survey: model.Survey{Sensitive: true,
+		ResultJSON: `{"risk":"high"}`, SurveyStats: &stats, Data: map[string]model.SurveyData{"mood": {Response: "low"}}}}
+
+	surveyResponse := original
+	err := sealSurveyResponse(&surveyResponse, "key", key)
+	if err != nil {
+		t.Fatalf("sealSurveyResponse() error = %v", err)
+	}
+	if surveyResponse.Survey.Data != nil || surveyResponse.Survey.SurveyStats != nil || surveyResponse.Survey.ResultJSON != "" || surveyResponse.ClientStats != nil {
+		t.Errorf("sealSurveyResponse() left plaintext fields in %+v", surveyResponse)
+	}
+	if surveyResponse.Survey.EncryptedData == nil || surveyResponse.Survey.EncryptedData.KeyID != "key" {
+		t.Fatalf("sealSurveyResponse() encrypted data = %+v, want data encrypted with key", surveyResponse.Survey.EncryptedData)
+	}
+
+	// encrypted fields cannot be moved to another response
+	moved := surveyResponse
+	moved.ID = "other"
+	if err = openSurveyResponse(&moved, key); err == nil {
+		t.Error("openSurveyResponse() of moved fields error = nil, want error")
+	}
+
+	err = openSurveyResponse(&surveyResponse, key)
+	if err != nil {
+		t.Fatalf("openSurveyResponse() error = %v", err)
+	}
+	if surveyResponse.Survey.EncryptedData != nil || surveyResponse.Survey.ResultJSON != original.Survey.ResultJSON ||
+		!reflect.DeepEqual(surveyResponse.Survey.SurveyStats, original.Survey.SurveyStats) || !reflect.DeepEqual(surveyResponse.ClientStats, original.ClientStats) ||
+		surveyResponse.Survey.Data["mood"].Response != "low" {
+		t.Errorf("openSurveyResponse() = %+v, want %+v", surveyResponse, original)
+	}
+}
+
+func TestAdapter_EncryptSurveyResponse(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	surveyResponse := model.SurveyResponse{ID: "response", Survey: model.Survey{Sensitive: true, Data: map[string]model.SurveyData{"mood": {Response: "low"}}}}
+
+	// responses are stored as they are without a master key
+	adapter := &Adapter{}
+	stored := surveyResponse
+	if err := adapter.encryptSurveyResponse(&stored); err != nil || stored.Survey.EncryptedData != nil {
+		t.Errorf("Adapter.encryptSurveyResponse() without master key = %+v, %v, want plaintext", stored.Survey, err)
+	}
+
+	// encrypted responses cannot be read without the master key
+	if err := sealSurveyResponse(&stored, "key", key); err != nil {
+		t.Fatalf("sealSurveyResponse() error = %v", err)
+	}
+	if err := adapter.decryptSurveyResponse(&stored); err == nil {
+		t.Error("Adapter.decryptSurveyResponse() without master key error = nil, want error")
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"application/core/model"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetEventSurveySubscription retrieves the subscription of a calendar event, or nil if the event has none
+func (a *Adapter) GetEventSurveySubscription(calendarEventID string, orgID string, appID string) (*model.EventSurveySubscription, error) {
+	filter := bson.M{"calendar_event_id": calendarEventID, "org_id": orgID, "app_id": appID}
+	var results []model.EventSurveySubscription
+	err := a.db.eventSurveySubscriptions.Find(a.context, filter, &results, options.Find().SetLimit(1))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeEventSurveySubscription, filterArgs(filter), err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &results[0], nil
+}
+
+// GetEventSurveySubscriptions retrieves the subscriptions of an app/org, sorted by event end date
+func (a *Adapter) GetEventSurveySubscriptions(orgID string, appID string, status *string, limit *int, offset *int) ([]model.EventSurveySubscription, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID}
+	if status != nil {
+		filter["status"] = *status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "event_end_date", Value: 1}, {Key: "_id", Value: 1}})
+	if limit != nil {
+		opts.SetLimit(int64(*limit))
+	}
+	if offset != nil {
+		opts.SetSkip(int64(*offset))
+	}
+
+	var results []model.EventSurveySubscription
+	err := a.db.eventSurveySubscriptions.Find(a.context, filter, &results, opts)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeEventSurveySubscription, filterArgs(filter), err)
+	}
+	return results, nil
+}
+
+// InsertEventSurveySubscription inserts a new subscription. Each calendar event has at most one subscription.
+func (a *Adapter) InsertEventSurveySubscription(subscription model.EventSurveySubscription) error {
+	_, err := a.db.eventSurveySubscriptions.InsertOne(a.context, subscription)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return &model.DuplicateKeyError{Type: string(model.TypeEventSurveySubscription)}
+		}
+		return errors.WrapErrorAction(logutils.ActionInsert, model.TypeEventSurveySubscription, nil, err)
+	}
+	return nil
+}
+
+// UpdateEventSurveySubscription replaces a subscription whose follow-up survey has not been created
+func (a *Adapter) UpdateEventSurveySubscription(subscription model.EventSurveySubscription) error {
+	filter := bson.M{"_id": subscription.ID, "org_id": subscription.OrgID, "app_id": subscription.AppID,
+		"status": bson.M{"$in": []string{model.EventSurveySubscriptionPending, model.EventSurveySubscriptionFailed}}}
+	res, err := a.db.eventSurveySubscriptions.UpdateOne(a.context, filter, bson.M{"$set": subscription}, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeEventSurveySubscription, filterArgs(filter), err)
+	}
+	if res.MatchedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeEventSurveySubscription, filterArgs(filter))
+	}
+	return nil
+}
+
+// DeleteEventSurveySubscription deletes the subscription of a calendar event unless its follow-up survey is being created
+func (a *Adapter) DeleteEventSurveySubscription(calendarEventID string, orgID string, appID string) error {
+	filter := bson.M{"calendar_event_id": calendarEventID, "org_id": orgID, "app_id": appID, "status": bson.M{"$ne": model.EventSurveySubscriptionProcessing}}
+	res, err := a.db.eventSurveySubscriptions.DeleteOne(a.context, filter, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionDelete, model.TypeEventSurveySubscription, filterArgs(filter), err)
+	}
+	if res.DeletedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeEventSurveySubscription, filterArgs(filter))
+	}
+	return nil
+}
+
+// ClaimEventSurveySubscription marks a pending subscription whose event ended before now as processing and returns it.
+// Subscriptions left processing since before staleBefore are claimed again. It returns nil if there is nothing to claim.
+func (a *Adapter) ClaimEventSurveySubscription(now time.Time, staleBefore time.Time) (*model.EventSurveySubscription, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": model.EventSurveySubscriptionPending, "event_end_date": bson.M{"$lte": now}},
+		bson.M{"status": model.EventSurveySubscriptionProcessing, "date_updated": bson.M{"$lt": staleBefore}},
+	}}
+	update := bson.M{"$set": bson.M{"status": model.EventSurveySubscriptionProcessing, "date_updated": now}}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "event_end_date", Value: 1}}).SetReturnDocument(options.After)
+
+	var subscription model.EventSurveySubscription
+	err := a.db.eventSurveySubscriptions.FindOneAndUpdate(a.context, filter, update, &subscription, opts)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeEventSurveySubscription, filterArgs(filter), err)
+	}
+	return &subscription, nil
+}
+
+// RescheduleEventSurveySubscription moves a claimed subscription back to pending until the new end of its event
+func (a *Adapter) RescheduleEventSurveySubscription(id string, eventEndDate time.Time) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"status": model.EventSurveySubscriptionPending, "event_end_date": eventEndDate, "date_updated": time.Now().UTC()}}
+	_, err := a.db.eventSurveySubscriptions.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeEventSurveySubscription, filterArgs(filter), err)
+	}
+	return nil
+}
+
+// UpdateEventSurveySubscriptionResult records the result of processing a subscription
+func (a *Adapter) UpdateEventSurveySubscriptionResult(id string, status string, surveyID *string, notified int, message *string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"status": status, "survey_id": surveyID, "notified": notified, "error": message, "date_updated": time.Now().UTC()}}
+	_, err := a.db.eventSurveySubscriptions.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeEventSurveySubscription, filterArgs(filter), err)
+	}
+	return nil
+}
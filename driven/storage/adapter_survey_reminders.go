@@ -0,0 +1,133 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"application/core/model"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetSurveysForReminders gets the published surveys with reminder offsets that end after now in all orgs and apps.
+// Recurring surveys are not included, they are reminded per response window.
+func (a *Adapter) GetSurveysForReminders(now time.Time) ([]model.Survey, error) {
+	filter := bson.M{
+		"reminder_offsets.0": bson.M{"$exists": true},
+		"end_date":           bson.M{"$gt": now},
+		"status":             model.SurveyStatusPublished,
+		"recurrence":         nil,
+		"deleted_at":         nil,
+	}
+	var results []model.Survey
+	err := a.db.surveys.Find(a.context, filter, &results, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurvey, filterArgs(filter), err)
+	}
+	return results, nil
+}
+
+// GetSurveyReminders retrieves the reminders sent for a survey, newest first
+func (a *Adapter) GetSurveyReminders(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyReminder, error) {
+	filter := bson.M{"survey_id": surveyID, "org_id": orgID, "app_id": appID}
+	opts := options.Find().SetSort(bson.D{{Key: "date_sent", Value: -1}, {Key: "_id", Value: 1}})
+	if limit != nil {
+		opts.SetLimit(int64(*limit))
+	}
+	if offset != nil {
+		opts.SetSkip(int64(*offset))
+	}
+
+	var results []model.SurveyReminder
+	err := a.db.surveyReminders.Find(a.context, filter, &results, opts)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyReminder, filterArgs(filter), err)
+	}
+	return results, nil
+}
+
+// InsertSurveyReminder records a sent reminder. It returns a DuplicateKeyError if the user has already been reminded at the same offset.
+func (a *Adapter) InsertSurveyReminder(reminder model.SurveyReminder) error {
+	_, err := a.db.surveyReminders.InsertOne(a.context, reminder)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return &model.DuplicateKeyError{Type: string(model.TypeSurveyReminder)}
+		}
+		return errors.WrapErrorAction(logutils.ActionInsert, model.TypeSurveyReminder, nil, err)
+	}
+	return nil
+}
+
+// GetSurveyReminderMutes retrieves the reminder mutes of the provided users (all users if empty). If surveyID is provided,
+// only the mutes of that survey and of all surveys are returned.
+func (a *Adapter) GetSurveyReminderMutes(orgID string, appID string, userIDs []string, surveyID *string) ([]model.SurveyReminderMute, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID}
+	if len(userIDs) > 0 {
+		filter["user_id"] = bson.M{"$in": userIDs}
+	}
+	if surveyID != nil {
+		filter["survey_id"] = bson.M{"$in": bson.A{*surveyID, ""}}
+	}
+
+	var results []model.SurveyReminderMute
+	err := a.db.surveyReminderMutes.Find(a.context, filter, &results, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyReminderMute, filterArgs(filter), err)
+	}
+	return results, nil
+}
+
+// InsertSurveyReminderMute inserts a reminder mute. It returns a DuplicateKeyError if the user has already muted the same reminders.
+func (a *Adapter) InsertSurveyReminderMute(mute model.SurveyReminderMute) error {
+	_, err := a.db.surveyReminderMutes.InsertOne(a.context, mute)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return &model.DuplicateKeyError{Type: string(model.TypeSurveyReminderMute)}
+		}
+		return errors.WrapErrorAction(logutils.ActionInsert, model.TypeSurveyReminderMute, nil, err)
+	}
+	return nil
+}
+
+// DeleteSurveyReminderMute deletes the reminder mute of a user for a survey, or for all surveys if surveyID is empty
+func (a *Adapter) DeleteSurveyReminderMute(orgID string, appID string, userID string, surveyID string) error {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "user_id": userID, "survey_id": surveyID}
+	res, err := a.db.surveyReminderMutes.DeleteOne(a.context, filter, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurveyReminderMute, filterArgs(filter), err)
+	}
+	if res.DeletedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurveyReminderMute, filterArgs(filter))
+	}
+	return nil
+}
+
+// DeleteSurveyRemindersWithIDs deletes the reminders and reminder mutes of the provided accounts
+func (a *Adapter) DeleteSurveyRemindersWithIDs(orgID string, appID string, accountsIDs []string) error {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "user_id": bson.M{"$in": accountsIDs}}
+	_, err := a.db.surveyReminders.DeleteMany(a.context, filter, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurveyReminder, nil, err)
+	}
+	_, err = a.db.surveyReminderMutes.DeleteMany(a.context, filter, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurveyReminderMute, nil, err)
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/rokwire/logging-library-go/v2/errors"
 	"github.com/rokwire/logging-library-go/v2/logutils"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -32,12 +33,16 @@ func (a *Adapter) GetSurveyResponse(id string, orgID string, appID string, userI
 	if err != nil {
 		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
 	}
+	err = a.decryptSurveyResponse(&entry)
+	if err != nil {
+		return nil, err
+	}
 	return &entry, nil
 }
 
 // GetSurveyResponses gets matching surveys for a user
 func (a *Adapter) GetSurveyResponses(orgID *string, appID *string, userID *string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time, limit *int, offset *int) ([]model.SurveyResponse, error) {
-	filter := bson.M{}
+	filter := bson.M{"status": bson.M{"$ne": model.SurveyResponseStatusInProgress}}
 	if userID != nil {
 		filter["user_id"] = userID
 	}
@@ -77,22 +82,277 @@ func (a *Adapter) GetSurveyResponses(orgID *string, appID *string, userID *strin
 	if err != nil {
 		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
 	}
+	err = a.decryptSurveyResponses(results)
+	if err != nil {
+		return nil, err
+	}
 	return results, nil
 }
 
 // CreateSurveyResponse creates a new survey response
 func (a *Adapter) CreateSurveyResponse(surveyResponse model.SurveyResponse) (*model.SurveyResponse, error) {
-	_, err := a.db.surveyResponses.InsertOne(a.context, surveyResponse)
+	stored := surveyResponse
+	err := a.encryptSurveyResponse(&stored)
+	if err != nil {
+		return nil, err
+	}
+	_, err = a.db.surveyResponses.InsertOne(a.context, stored)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, &model.DuplicateKeyError{Type: string(model.TypeSurveyResponse)}
+		}
 		return nil, errors.WrapErrorAction(logutils.ActionCreate, model.TypeSurveyResponse, nil, err)
 	}
 	return &surveyResponse, nil
 }
 
+// GetSurveyResponseByIdempotencyKey gets the survey response created by the user for the survey with the provided idempotency key
+func (a *Adapter) GetSurveyResponseByIdempotencyKey(orgID string, appID string, userID string, surveyID string, key string) (*model.SurveyResponse, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "user_id": userID, "survey._id": surveyID, "idempotency_key": key}
+	var results []model.SurveyResponse
+	err := a.db.surveyResponses.Find(a.context, filter, &results, options.Find().SetLimit(1))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	err = a.decryptSurveyResponse(&results[0])
+	if err != nil {
+		return nil, err
+	}
+	return &results[0], nil
+}
+
+// CountSurveyResponses counts the responses of a user to a survey, optionally only those created since the provided time
+func (a *Adapter) CountSurveyResponses(orgID string, appID string, userID string, surveyID string, since *time.Time) (int64, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "user_id": userID, "survey._id": surveyID, "status": bson.M{"$ne": model.SurveyResponseStatusInProgress}}
+	if since != nil {
+		filter["date_created"] = bson.M{"$gte": since}
+	}
+	count, err := a.db.surveyResponses.CountDocuments(a.context, filter)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionCount, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return count, nil
+}
+
+// GetSurveyRespondents gets the IDs of the users with a completed response to a survey created since the provided time
+func (a *Adapter) GetSurveyRespondents(orgID string, appID string, surveyID string, since *time.Time) ([]string, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "survey._id": surveyID, "status": bson.M{"$ne": model.SurveyResponseStatusInProgress}}
+	if since != nil {
+		filter["date_created"] = bson.M{"$gte": since}
+	}
+	pipeline := bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$group": bson.M{"_id": "$user_id"}},
+	}
+
+	var results []struct {
+		UserID string `bson:"_id"`
+	}
+	err := a.db.surveyResponses.Aggregate(pipeline, &results, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+
+	userIDs := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.UserID != "" {
+			userIDs = append(userIDs, result.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+// CountSurveyResponsesBySurvey counts the completed responses to a survey
+func (a *Adapter) CountSurveyResponsesBySurvey(orgID string, appID string, surveyID string) (int64, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "survey._id": surveyID, "status": bson.M{"$ne": model.SurveyResponseStatusInProgress}}
+	count, err := a.db.surveyResponses.CountDocuments(a.context, filter)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionCount, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return count, nil
+}
+
+// StreamSurveyResponses calls handle for each completed response to a survey, oldest first
+func (a *Adapter) StreamSurveyResponses(orgID string, appID string, surveyID string, startDate *time.Time, endDate *time.Time, handle func(surveyResponse model.SurveyResponse) error) error {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "survey._id": surveyID, "status": bson.M{"$ne": model.SurveyResponseStatusInProgress}}
+	if startDate != nil || endDate != nil {
+		dateFilter := bson.M{}
+		if startDate != nil {
+			dateFilter["$gte"] = startDate
+		}
+		if endDate != nil {
+			dateFilter["$lt"] = endDate
+		}
+		filter["date_created"] = dateFilter
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date_created", Value: 1}, {Key: "_id", Value: 1}})
+	err := a.db.surveyResponses.FindEach(a.context, filter, opts, func(cur *mongo.Cursor) error {
+		var surveyResponse model.SurveyResponse
+		err := cur.Decode(&surveyResponse)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionDecode, model.TypeSurveyResponse, nil, err)
+		}
+		err = a.decryptSurveyResponse(&surveyResponse)
+		if err != nil {
+			return err
+		}
+		return handle(surveyResponse)
+	})
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return nil
+}
+
+// StreamSurveyResponsesByType calls handle for each completed response to surveys of the provided types, sorted by
+// creation date and ID. Only the fields of anonymous survey responses are loaded.
+func (a *Adapter) StreamSurveyResponsesByType(surveyTypes []string, startDate *time.Time, endDate *time.Time, after *model.SurveyResponseCursor,
+	handle func(surveyResponse model.SurveyResponse) error) error {
+	filter := bson.M{"status": bson.M{"$ne": model.SurveyResponseStatusInProgress}}
+	if len(surveyTypes) > 0 {
+		filter["survey.type"] = bson.M{"$in": surveyTypes}
+	}
+	if startDate != nil || endDate != nil {
+		dateFilter := bson.M{}
+		if startDate != nil {
+			dateFilter["$gte"] = startDate
+		}
+		if endDate != nil {
+			dateFilter["$lt"] = endDate
+		}
+		filter["date_created"] = dateFilter
+	}
+	if after != nil {
+		// resume after the last streamed response
+		filter["$or"] = bson.A{
+			bson.M{"date_created": bson.M{"$gt": after.DateCreated}},
+			bson.M{"date_created": after.DateCreated, "_id": bson.M{"$gt": after.ID}},
+		}
+	}
+
+	projection := bson.M{"_id": 1, "user_id": 1, "date_created": 1, "survey_revision": 1, "survey._id": 1, "survey.creator_id": 1, "survey.org_id": 1,
+		"survey.app_id": 1, "survey.title": 1, "survey.type": 1, "survey.anonymous": 1, "survey.sensitive": 1, "survey.stats": 1, "survey.data": 1,
+		"survey.encrypted_data": 1, "survey.date_created": 1,
+		"survey.date_updated": 1}
+	opts := options.Find().SetSort(bson.D{{Key: "date_created", Value: 1}, {Key: "_id", Value: 1}}).SetProjection(projection)
+	err := a.db.surveyResponses.FindEach(a.context, filter, opts, func(cur *mongo.Cursor) error {
+		var surveyResponse model.SurveyResponse
+		err := cur.Decode(&surveyResponse)
+		if err != nil {
+			return errors.WrapErrorAction(logutils.ActionDecode, model.TypeSurveyResponse, nil, err)
+		}
+		err = a.decryptSurveyResponse(&surveyResponse)
+		if err != nil {
+			return err
+		}
+		return handle(surveyResponse)
+	})
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return nil
+}
+
+// GetSurveyResponseAggregates counts the completed responses to a survey, the answers to each survey question, the answers
+// with each value for the questions with the provided keys and the responses created in each interval (day, week or month)
+func (a *Adapter) GetSurveyResponseAggregates(orgID string, appID string, surveyID string, valueKeys []string, startDate *time.Time, endDate *time.Time,
+	interval string) (*model.SurveyResponseAggregates, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "survey._id": surveyID, "status": bson.M{"$ne": model.SurveyResponseStatusInProgress}}
+	if startDate != nil || endDate != nil {
+		dateFilter := bson.M{}
+		if startDate != nil {
+			dateFilter["$gte"] = startDate
+		}
+		if endDate != nil {
+			dateFilter["$lt"] = endDate
+		}
+		filter["date_created"] = dateFilter
+	}
+
+	// one document per answered question
+	answerStages := bson.A{
+		bson.M{"$project": bson.M{"data": bson.M{"$objectToArray": "$survey.data"}}},
+		bson.M{"$unwind": "$data"},
+		bson.M{"$match": bson.M{"data.v.response": bson.M{"$nin": bson.A{nil, "", bson.A{}}}}},
+	}
+	valueStages := append(bson.A{}, answerStages...)
+	valueStages = append(valueStages,
+		bson.M{"$match": bson.M{"data.k": bson.M{"$in": valueKeys}}},
+		// multiple choice answers may contain several values
+		bson.M{"$unwind": "$data.v.response"},
+		bson.M{"$group": bson.M{"_id": bson.M{"key": "$data.k", "value": "$data.v.response"}, "count": bson.M{"$sum": 1}}},
+	)
+
+	pipeline := bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$facet": bson.M{
+			"total": bson.A{bson.M{"$count": "count"}},
+			"answers": append(answerStages,
+				bson.M{"$group": bson.M{"_id": "$data.k", "count": bson.M{"$sum": 1}}},
+			),
+			"values": valueStages,
+			"timeline": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$dateTrunc": bson.M{"date": "$date_created", "unit": interval, "startOfWeek": "monday"}},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+		}},
+	}
+
+	var results []struct {
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Answers []struct {
+			Key   string `bson:"_id"`
+			Count int64  `bson:"count"`
+		} `bson:"answers"`
+		Values []struct {
+			ID struct {
+				Key   string      `bson:"key"`
+				Value interface{} `bson:"value"`
+			} `bson:"_id"`
+			Count int64 `bson:"count"`
+		} `bson:"values"`
+		Timeline []model.SurveyResponseCount `bson:"timeline"`
+	}
+	err := a.db.surveyResponses.Aggregate(pipeline, &results, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+
+	aggregates := model.SurveyResponseAggregates{Answers: make(map[string]int64), Values: make([]model.SurveyResponseValueCount, 0), Timeline: make([]model.SurveyResponseCount, 0)}
+	if len(results) == 0 {
+		return &aggregates, nil
+	}
+	result := results[0]
+	if len(result.Total) > 0 {
+		aggregates.Responses = result.Total[0].Count
+	}
+	for _, answer := range result.Answers {
+		aggregates.Answers[answer.Key] = answer.Count
+	}
+	for _, value := range result.Values {
+		aggregates.Values = append(aggregates.Values, model.SurveyResponseValueCount{Key: value.ID.Key, Value: value.ID.Value, Count: value.Count})
+	}
+	aggregates.Timeline = append(aggregates.Timeline, result.Timeline...)
+	return &aggregates, nil
+}
+
 // UpdateSurveyResponse updates an existing service response
 func (a *Adapter) UpdateSurveyResponse(surveyResponse model.SurveyResponse) error {
 	now := time.Now().UTC()
 	filter := bson.M{"_id": surveyResponse.ID, "user_id": surveyResponse.UserID, "org_id": surveyResponse.OrgID, "app_id": surveyResponse.AppID}
+	err := a.encryptSurveyResponse(&surveyResponse)
+	if err != nil {
+		return err
+	}
 	update := bson.M{"$set": bson.M{
 		"survey":       surveyResponse.Survey,
 		"date_updated": now,
@@ -108,8 +368,96 @@ func (a *Adapter) UpdateSurveyResponse(surveyResponse model.SurveyResponse) erro
 	return nil
 }
 
+// GetSurveyResponseDraft gets the in progress response of a user to a survey
+func (a *Adapter) GetSurveyResponseDraft(orgID string, appID string, userID string, surveyID string) (*model.SurveyResponse, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "user_id": userID, "survey._id": surveyID, "status": model.SurveyResponseStatusInProgress}
+	var results []model.SurveyResponse
+	err := a.db.surveyResponses.Find(a.context, filter, &results, options.Find().SetLimit(1))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	err = a.decryptSurveyResponse(&results[0])
+	if err != nil {
+		return nil, err
+	}
+	return &results[0], nil
+}
+
+// UpdateSurveyResponseDraft saves the answers of an in progress survey response
+func (a *Adapter) UpdateSurveyResponseDraft(surveyResponse model.SurveyResponse) error {
+	now := time.Now().UTC()
+	filter := bson.M{"_id": surveyResponse.ID, "user_id": surveyResponse.UserID, "org_id": surveyResponse.OrgID, "app_id": surveyResponse.AppID,
+		"status": model.SurveyResponseStatusInProgress}
+	err := a.encryptSurveyResponse(&surveyResponse)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$set": bson.M{
+		"survey":       surveyResponse.Survey,
+		"date_updated": now,
+	}}
+
+	res, err := a.db.surveyResponses.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	if res.MatchedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurveyResponse, filterArgs(filter))
+	}
+	return nil
+}
+
+// CompleteSurveyResponseDraft finalizes an in progress survey response
+func (a *Adapter) CompleteSurveyResponseDraft(surveyResponse model.SurveyResponse) error {
+	filter := bson.M{"_id": surveyResponse.ID, "user_id": surveyResponse.UserID, "org_id": surveyResponse.OrgID, "app_id": surveyResponse.AppID,
+		"status": model.SurveyResponseStatusInProgress}
+	err := a.encryptSurveyResponse(&surveyResponse)
+	if err != nil {
+		return err
+	}
+	set := bson.M{
+		"survey":          surveyResponse.Survey,
+		"status":          model.SurveyResponseStatusCompleted,
+		"survey_revision": surveyResponse.SurveyRevision,
+		"client_stats":    surveyResponse.ClientStats,
+		"date_created":    surveyResponse.DateCreated,
+		"date_updated":    surveyResponse.DateUpdated,
+	}
+	if surveyResponse.LimitKey != "" {
+		set["limit_key"] = surveyResponse.LimitKey
+	}
+
+	res, err := a.db.surveyResponses.UpdateOne(a.context, filter, bson.M{"$set": set}, nil)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return &model.DuplicateKeyError{Type: string(model.TypeSurveyResponse)}
+		}
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	if res.MatchedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurveyResponse, filterArgs(filter))
+	}
+	return nil
+}
+
+// DeleteStaleSurveyResponseDrafts deletes the in progress survey responses that have not been saved since the provided time
+func (a *Adapter) DeleteStaleSurveyResponseDrafts(before time.Time) (int64, error) {
+	filter := bson.M{"status": model.SurveyResponseStatusInProgress, "$or": bson.A{
+		bson.M{"date_updated": bson.M{"$lt": before}},
+		bson.M{"date_updated": nil, "date_created": bson.M{"$lt": before}},
+	}}
+	res, err := a.db.surveyResponses.DeleteMany(a.context, filter, nil)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return res.DeletedCount, nil
+}
+
 // DeleteSurveyResponse deletes a survey response
-func (a *Adapter) DeleteSurveyResponse(orgID string, appID string, userID string, id string) error {
+func (a *Adapter) DeleteSurveyResponse(id string, orgID string, appID string, userID string) error {
 	filter := bson.M{"_id": id, "user_id": userID, "org_id": orgID, "app_id": appID}
 	res, err := a.db.surveyResponses.DeleteOne(a.context, filter, nil)
 	if err != nil {
@@ -121,6 +469,82 @@ func (a *Adapter) DeleteSurveyResponse(orgID string, appID string, userID string
 	return nil
 }
 
+// SetSurveyResponsesSurveyDeleted sets the deleted date of the survey in all its responses, or clears it if deletedAt is nil
+func (a *Adapter) SetSurveyResponsesSurveyDeleted(surveyID string, orgID string, appID string, deletedAt *time.Time) (int64, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "survey._id": surveyID}
+	update := bson.M{"$unset": bson.M{"survey.deleted_at": ""}}
+	if deletedAt != nil {
+		update = bson.M{"$set": bson.M{"survey.deleted_at": deletedAt}}
+	}
+
+	res, err := a.db.surveyResponses.UpdateMany(a.context, filter, update, nil)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return res.ModifiedCount, nil
+}
+
+// DeleteSurveyResponsesForSurvey deletes up to limit responses to a deleted survey and returns the number of responses
+// deleted. Only the responses marked as deleted with the survey are deleted, so the responses of a restored survey are kept.
+func (a *Adapter) DeleteSurveyResponsesForSurvey(surveyID string, orgID string, appID string, limit int) (int64, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "survey._id": surveyID, "survey.deleted_at": bson.M{"$ne": nil}}
+	var documents []struct {
+		ID string `bson:"_id"`
+	}
+	err := a.db.surveyResponses.Find(a.context, filter, &documents, options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(int64(limit)))
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	if len(documents) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, len(documents))
+	for i, document := range documents {
+		ids[i] = document.ID
+	}
+	filter["_id"] = bson.M{"$in": ids}
+	res, err := a.db.surveyResponses.DeleteMany(a.context, filter, nil)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return res.DeletedCount, nil
+}
+
+// ArchiveSurveyResponses moves up to limit responses to a deleted survey to the archive collection and returns the
+// number of responses moved. The documents are copied as stored, so encrypted responses stay encrypted. Responses
+// copied by an interrupted call are not copied again.
+func (a *Adapter) ArchiveSurveyResponses(surveyID string, orgID string, appID string, archivedAt time.Time, limit int) (int64, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "survey._id": surveyID, "survey.deleted_at": bson.M{"$ne": nil}}
+	var documents []bson.M
+	err := a.db.surveyResponses.Find(a.context, filter, &documents, options.Find().SetLimit(int64(limit)))
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	if len(documents) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]interface{}, len(documents))
+	batch := make([]interface{}, len(documents))
+	for i, document := range documents {
+		ids[i] = document["_id"]
+		document["archived_at"] = archivedAt
+		batch[i] = document
+	}
+	_, err = a.db.archive.InsertMany(a.context, batch, options.InsertMany().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return 0, errors.WrapErrorAction(logutils.ActionInsert, model.TypeSurveyResponse, &logutils.FieldArgs{"survey_id": surveyID, "archive": true}, err)
+	}
+
+	filter["_id"] = bson.M{"$in": ids}
+	res, err := a.db.surveyResponses.DeleteMany(a.context, filter, nil)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurveyResponse, filterArgs(filter), err)
+	}
+	return res.DeletedCount, nil
+}
+
 // DeleteSurveyResponses deletes matching surveys
 func (a *Adapter) DeleteSurveyResponses(orgID string, appID string, userID string, surveyIDs []string, surveyTypes []string, startDate *time.Time, endDate *time.Time) error {
 	filter := bson.M{"user_id": userID, "org_id": orgID, "app_id": appID}
@@ -0,0 +1,80 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"application/core/model"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetSurveyRevisions retrieves the revisions of a survey, newest first
+func (a *Adapter) GetSurveyRevisions(surveyID string, orgID string, appID string, limit *int, offset *int) ([]model.SurveyRevision, error) {
+	filter := bson.M{"survey_id": surveyID, "org_id": orgID, "app_id": appID}
+
+	opts := options.Find().SetSort(bson.D{{Key: "revision", Value: -1}})
+	if limit != nil {
+		opts.SetLimit(int64(*limit))
+	}
+	if offset != nil {
+		opts.SetSkip(int64(*offset))
+	}
+
+	var results []model.SurveyRevision
+	err := a.db.surveyRevisions.Find(a.context, filter, &results, opts)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyRevision, filterArgs(filter), err)
+	}
+	return results, nil
+}
+
+// GetSurveyRevision retrieves a single revision of a survey
+func (a *Adapter) GetSurveyRevision(surveyID string, orgID string, appID string, revision int) (*model.SurveyRevision, error) {
+	filter := bson.M{"survey_id": surveyID, "org_id": orgID, "app_id": appID, "revision": revision}
+	var entry model.SurveyRevision
+	err := a.db.surveyRevisions.FindOne(a.context, filter, &entry, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyRevision, filterArgs(filter), err)
+	}
+	return &entry, nil
+}
+
+// InsertSurveyRevision inserts a survey revision
+func (a *Adapter) InsertSurveyRevision(surveyRevision model.SurveyRevision) error {
+	_, err := a.db.surveyRevisions.InsertOne(a.context, surveyRevision)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionInsert, model.TypeSurveyRevision, nil, err)
+	}
+	return nil
+}
+
+// DeleteSurveyRevisionsWithIDs deletes the revisions of the surveys created by the provided accounts
+func (a *Adapter) DeleteSurveyRevisionsWithIDs(orgID string, appID string, accountsIDs []string) error {
+	filter := bson.D{
+		primitive.E{Key: "app_id", Value: appID},
+		primitive.E{Key: "org_id", Value: orgID},
+		primitive.E{Key: "survey.creator_id", Value: bson.M{"$in": accountsIDs}},
+	}
+
+	_, err := a.db.surveyRevisions.DeleteMany(a.context, filter, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurveyRevision, nil, err)
+	}
+	return nil
+}
@@ -28,7 +28,7 @@ import (
 
 // GetSurvey retrieves a single survey
 func (a *Adapter) GetSurvey(id string, orgID string, appID string) (*model.Survey, error) {
-	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID}
+	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID, "deleted_at": nil}
 	var entry model.Survey
 	err := a.db.surveys.FindOne(a.context, filter, &entry, nil)
 	if err != nil {
@@ -42,6 +42,7 @@ func (a *Adapter) GetSurveys(orgID string, appID string, creatorID *string, surv
 	filter := bson.D{
 		{Key: "org_id", Value: orgID},
 		{Key: "app_id", Value: appID},
+		{Key: "deleted_at", Value: nil},
 	}
 
 	if creatorID != nil {
@@ -129,6 +130,7 @@ func (a *Adapter) GetSurveysLight(orgID string, appID string, creatorID *string)
 	filter := bson.D{
 		{Key: "org_id", Value: orgID},
 		{Key: "app_id", Value: appID},
+		{Key: "deleted_at", Value: nil},
 	}
 
 	if creatorID != nil {
@@ -148,6 +150,9 @@ func (a *Adapter) GetSurveysLight(orgID string, appID string, creatorID *string)
 func (a *Adapter) CreateSurvey(survey model.Survey) (*model.Survey, error) {
 	_, err := a.db.surveys.InsertOne(a.context, survey)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, &model.DuplicateKeyError{Type: string(model.TypeSurvey)}
+		}
 		return nil, errors.WrapErrorAction(logutils.ActionCreate, model.TypeSurvey, nil, err)
 	}
 
@@ -158,7 +163,7 @@ func (a *Adapter) CreateSurvey(survey model.Survey) (*model.Survey, error) {
 func (a *Adapter) UpdateSurvey(survey model.Survey, admin bool) error {
 	if len(survey.ID) > 0 {
 		now := time.Now().UTC()
-		filter := bson.M{"_id": survey.ID, "org_id": survey.OrgID, "app_id": survey.AppID}
+		filter := bson.M{"_id": survey.ID, "org_id": survey.OrgID, "app_id": survey.AppID, "deleted_at": nil}
 		if !admin {
 			filter["creator_id"] = survey.CreatorID
 		}
@@ -180,6 +185,14 @@ func (a *Adapter) UpdateSurvey(survey model.Survey, admin bool) error {
 			"public":                    survey.Public,
 			"archived":                  survey.Archived,
 			"estimated_completion_time": survey.EstimatedCompletionTime,
+			"revision":                  survey.Revision,
+			"status":                    survey.Status,
+			"grace_period":              survey.GracePeriod,
+			"response_policy":           survey.ResponsePolicy,
+			"recurrence":                survey.Recurrence,
+			"reminder_offsets":          survey.ReminderOffsets,
+			"audience":                  survey.Audience,
+			"alert_rules":               survey.AlertRules,
 			"date_updated":              now,
 		}}
 
@@ -195,29 +208,323 @@ func (a *Adapter) UpdateSurvey(survey model.Survey, admin bool) error {
 	return nil
 }
 
-// DeleteSurvey deletes a survey
-func (a *Adapter) DeleteSurvey(id string, orgID string, appID string, creatorID string, admin bool) error {
-	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID}
+// UpdateSurveyStatus moves a survey from the provided status to the target status
+func (a *Adapter) UpdateSurveyStatus(id string, orgID string, appID string, status string, target string) error {
+	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID, "status": status, "deleted_at": nil}
+	if status == model.SurveyStatusPublished || status == model.SurveyStatusArchived {
+		// surveys created before statuses were tracked have no status
+		filter["status"] = bson.M{"$in": bson.A{status, "", nil}}
+	}
+	update := bson.M{"$set": bson.M{
+		"status":       target,
+		"archived":     target == model.SurveyStatusArchived,
+		"date_updated": time.Now().UTC(),
+	}}
+
+	res, err := a.db.surveys.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, filterArgs(filter), err)
+	}
+	if res.ModifiedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, filterArgs(filter))
+	}
+	return nil
+}
+
+// UpdateSurveyResponseWindowOverride sets or clears the admin override of the survey response window
+func (a *Adapter) UpdateSurveyResponseWindowOverride(id string, orgID string, appID string, override *bool) error {
+	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID, "deleted_at": nil}
+	update := bson.M{"$set": bson.M{
+		"response_window_override": override,
+		"date_updated":             time.Now().UTC(),
+	}}
+
+	res, err := a.db.surveys.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, filterArgs(filter), err)
+	}
+	if res.MatchedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, filterArgs(filter))
+	}
+	return nil
+}
+
+// GetRecurringSurveys gets the scheduled and published surveys with a recurrence in all orgs and apps
+func (a *Adapter) GetRecurringSurveys() ([]model.Survey, error) {
+	filter := bson.M{
+		"recurrence": bson.M{"$type": "object"},
+		"status":     bson.M{"$in": bson.A{model.SurveyStatusScheduled, model.SurveyStatusPublished}},
+		"deleted_at": nil,
+	}
+	var results []model.Survey
+	err := a.db.surveys.Find(a.context, filter, &results, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurvey, filterArgs(filter), err)
+	}
+	return results, nil
+}
+
+// UpdateSurveyRecurrenceWindow moves the current response window of a recurring survey from windowStart to target (nil
+// between windows). It returns false if the window has been moved in the meantime.
+func (a *Adapter) UpdateSurveyRecurrenceWindow(id string, windowStart *time.Time, target *time.Time) (bool, error) {
+	filter := bson.M{"_id": id, "recurrence_state.window_start": windowStart, "deleted_at": nil}
+	update := bson.M{"$set": bson.M{"recurrence_state.window_start": target}}
+
+	res, err := a.db.surveys.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		return false, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, filterArgs(filter), err)
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// UpdateSurveyRecurrenceReminder records that the reminder of the response window starting at windowStart has been sent.
+// It returns false if the reminder has already been recorded.
+func (a *Adapter) UpdateSurveyRecurrenceReminder(id string, windowStart time.Time) (bool, error) {
+	filter := bson.M{"_id": id, "recurrence_state.reminded_start": bson.M{"$ne": windowStart}, "deleted_at": nil}
+	update := bson.M{"$set": bson.M{"recurrence_state.reminded_start": windowStart}}
+
+	res, err := a.db.surveys.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		return false, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, filterArgs(filter), err)
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// UpdateSurveyStatuses moves scheduled and published surveys to the status matching their start and end dates. Surveys
+// are closed once the grace period after their end date is over.
+func (a *Adapter) UpdateSurveyStatuses(now time.Time) (int64, error) {
+	gracePeriod := bson.M{"$multiply": bson.A{bson.M{"$max": bson.A{bson.M{"$ifNull": bson.A{"$grace_period", 0}}, 0}}, time.Minute.Milliseconds()}}
+	closeFilter := bson.M{
+		"status":     bson.M{"$in": bson.A{model.SurveyStatusScheduled, model.SurveyStatusPublished}},
+		"end_date":   bson.M{"$ne": nil, "$lte": now},
+		"$expr":      bson.M{"$lte": bson.A{bson.M{"$add": bson.A{"$end_date", gracePeriod}}, now}},
+		"deleted_at": nil,
+	}
+	closed, err := a.db.surveys.UpdateMany(a.context, closeFilter, bson.M{"$set": bson.M{"status": model.SurveyStatusClosed}}, nil)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, filterArgs(closeFilter), err)
+	}
+
+	publishFilter := bson.M{
+		"status":     model.SurveyStatusScheduled,
+		"start_date": bson.M{"$lte": now},
+		"deleted_at": nil,
+	}
+	published, err := a.db.surveys.UpdateMany(a.context, publishFilter, bson.M{"$set": bson.M{"status": model.SurveyStatusPublished}}, nil)
+	if err != nil {
+		return 0, errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, filterArgs(publishFilter), err)
+	}
+
+	return closed.ModifiedCount + published.ModifiedCount, nil
+}
+
+// DeleteSurvey moves a survey to the trash. The calendar event of the survey is released, so the event may get a new survey.
+func (a *Adapter) DeleteSurvey(id string, orgID string, appID string, creatorID string, admin bool, responsesPolicy string) error {
+	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID, "deleted_at": nil}
 	if !admin {
 		filter["creator_id"] = creatorID
 	}
-	res, err := a.db.surveys.DeleteOne(a.context, filter, nil)
+	update := bson.A{
+		bson.M{"$set": bson.M{"deleted_at": time.Now().UTC(), "deleted_calendar_event_id": "$calendar_event_id", "deleted_responses": responsesPolicy}},
+		bson.M{"$unset": "calendar_event_id"},
+	}
+	res, err := a.db.surveys.UpdateOne(a.context, filter, update, nil)
 	if err != nil {
 		return errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurvey, filterArgs(filter), err)
 	}
-	if res.DeletedCount != 1 {
+	if res.ModifiedCount != 1 {
 		return errors.WrapErrorData(logutils.StatusMissing, model.TypeSurvey, filterArgs(filter), err)
 	}
 
 	return nil
 }
 
-// GetSurveysAndSurveyResponses gets surveys and matching survey responses
-func (a *Adapter) GetSurveysAndSurveyResponses(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, public *bool, archived *bool, limit *int, offset *int, userID *string, timeFilter *model.SurveyTimeFilter) ([]model.Survey, []model.SurveyResponse, error) {
+// GetDeletedSurveys retrieves the surveys in the trash, most recently deleted first
+func (a *Adapter) GetDeletedSurveys(orgID string, appID string, limit *int, offset *int) ([]model.Survey, error) {
+	filter := bson.M{"org_id": orgID, "app_id": appID, "deleted_at": bson.M{"$ne": nil}}
+
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}})
+	if limit != nil {
+		opts.SetLimit(int64(*limit))
+	}
+	if offset != nil {
+		opts.SetSkip(int64(*offset))
+	}
+
+	results := make([]model.Survey, 0)
+	err := a.db.surveys.Find(a.context, filter, &results, opts)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurvey, filterArgs(filter), err)
+	}
+	for i := range results {
+		results[i].CalendarEventID = results[i].DeletedCalendarEventID
+	}
+	return results, nil
+}
+
+// GetDeletedSurvey retrieves a single survey in the trash
+func (a *Adapter) GetDeletedSurvey(id string, orgID string, appID string) (*model.Survey, error) {
+	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID, "deleted_at": bson.M{"$ne": nil}}
+	var entry model.Survey
+	err := a.db.surveys.FindOne(a.context, filter, &entry, nil)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurvey, filterArgs(filter), err)
+	}
+	entry.CalendarEventID = entry.DeletedCalendarEventID
+	return &entry, nil
+}
+
+// RestoreSurvey moves a survey out of the trash. It fails with a DuplicateKeyError if the calendar event of the survey has a new survey.
+func (a *Adapter) RestoreSurvey(id string, orgID string, appID string) error {
+	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID, "deleted_at": bson.M{"$ne": nil}}
+	update := bson.A{
+		bson.M{"$set": bson.M{"calendar_event_id": "$deleted_calendar_event_id", "date_updated": time.Now().UTC()}},
+		bson.M{"$unset": bson.A{"deleted_at", "deleted_calendar_event_id", "deleted_responses"}},
+	}
+	res, err := a.db.surveys.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return &model.DuplicateKeyError{Type: string(model.TypeSurvey)}
+		}
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurvey, filterArgs(filter), err)
+	}
+	if res.ModifiedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, filterArgs(filter))
+	}
+	return nil
+}
+
+// GetSurveyTemplates retrieves the surveys marked as templates, sorted by category and name
+func (a *Adapter) GetSurveyTemplates(orgID string, appID string, filter model.SurveyTemplateFilter) ([]model.Survey, error) {
+	query := bson.M{"org_id": orgID, "app_id": appID, "template": bson.M{"$exists": true}, "deleted_at": nil}
+	if filter.InCatalog != nil {
+		query["template.in_catalog"] = *filter.InCatalog
+	}
+	if filter.CreatorID != nil {
+		query["creator_id"] = *filter.CreatorID
+	}
+	if filter.Category != nil {
+		query["template.category"] = *filter.Category
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "template.category", Value: 1}, {Key: "template.name", Value: 1}, {Key: "_id", Value: 1}})
+	if filter.Limit != nil {
+		opts.SetLimit(int64(*filter.Limit))
+	}
+	if filter.Offset != nil {
+		opts.SetSkip(int64(*filter.Offset))
+	}
+
+	var results []model.Survey
+	err := a.db.surveys.Find(a.context, query, &results, opts)
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyTemplate, filterArgs(query), err)
+	}
+	return results, nil
+}
+
+// UpdateSurveyTemplate sets the template of a survey, or removes it if template is nil
+func (a *Adapter) UpdateSurveyTemplate(id string, orgID string, appID string, template *model.SurveyTemplate) error {
+	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID, "deleted_at": nil}
+	update := bson.M{"$unset": bson.M{"template": ""}}
+	if template != nil {
+		update = bson.M{"$set": bson.M{"template": template}}
+	}
+
+	res, err := a.db.surveys.UpdateOne(a.context, filter, update, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionUpdate, model.TypeSurveyTemplate, filterArgs(filter), err)
+	}
+	if res.MatchedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, filterArgs(filter))
+	}
+	return nil
+}
+
+// GetPurgeableSurveys retrieves the surveys moved to the trash before the provided time
+func (a *Adapter) GetPurgeableSurveys(before time.Time) ([]model.Survey, error) {
+	filter := bson.M{"deleted_at": bson.M{"$ne": nil, "$lte": before}}
+	projection := bson.M{"_id": 1, "org_id": 1, "app_id": 1, "deleted_at": 1, "deleted_responses": 1}
+	var surveys []model.Survey
+	err := a.db.surveys.Find(a.context, filter, &surveys, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurvey, filterArgs(filter), err)
+	}
+	return surveys, nil
+}
+
+// PurgeDeletedSurvey deletes a survey in the trash together with its revisions
+func (a *Adapter) PurgeDeletedSurvey(id string, orgID string, appID string) error {
+	revisionsFilter := bson.M{"survey_id": id, "org_id": orgID, "app_id": appID}
+	_, err := a.db.surveyRevisions.DeleteMany(a.context, revisionsFilter, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurveyRevision, filterArgs(revisionsFilter), err)
+	}
+
+	filter := bson.M{"_id": id, "org_id": orgID, "app_id": appID, "deleted_at": bson.M{"$ne": nil}}
+	res, err := a.db.surveys.DeleteOne(a.context, filter, nil)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionDelete, model.TypeSurvey, filterArgs(filter), err)
+	}
+	if res.DeletedCount != 1 {
+		return errors.ErrorData(logutils.StatusMissing, model.TypeSurvey, filterArgs(filter))
+	}
+	return nil
+}
+
+// surveyAudienceFilter returns the conditions, any of which makes a survey visible to the user
+func surveyAudienceFilter(user model.SurveyAudienceUser) bson.A {
+	conditions := bson.A{
+		bson.M{"audience": nil},
+		bson.M{"creator_id": user.AccountID},
+		bson.M{"audience.account_ids": user.AccountID},
+	}
+	if len(user.Permissions) > 0 {
+		conditions = append(conditions, bson.M{"audience.permissions": bson.M{"$in": user.Permissions}})
+	}
+	if len(user.SurveyIDs) > 0 {
+		conditions = append(conditions, bson.M{"_id": bson.M{"$in": user.SurveyIDs}})
+	}
+	return conditions
+}
+
+// GetSurveyAudiences gets the audience of the matching surveys whose audience has external ID patterns or is for the
+// registrants of their calendar event
+func (a *Adapter) GetSurveyAudiences(orgID string, appID string, surveyIDs []string, calendarEventID string) ([]model.Survey, error) {
+	filter := bson.D{
+		{Key: "org_id", Value: orgID},
+		{Key: "app_id", Value: appID},
+		{Key: "deleted_at", Value: nil},
+		{Key: "$or", Value: bson.A{
+			bson.M{"audience.external_id_patterns": bson.M{"$ne": nil}},
+			bson.M{"audience.event_registration": true},
+		}},
+	}
+	if len(surveyIDs) > 0 {
+		filter = append(filter, bson.E{Key: "_id", Value: bson.M{"$in": surveyIDs}})
+	}
+	if calendarEventID != "" {
+		filter = append(filter, bson.E{Key: "calendar_event_id", Value: calendarEventID})
+	}
+
+	projection := bson.M{"_id": 1, "org_id": 1, "app_id": 1, "creator_id": 1, "calendar_event_id": 1, "audience": 1}
+	var surveys []model.Survey
+	err := a.db.surveys.Find(a.context, filter, &surveys, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, errors.WrapErrorAction(logutils.ActionFind, model.TypeSurveyAudience, &logutils.FieldArgs{"org_id": orgID, "app_id": appID, "calendar_event_id": calendarEventID}, err)
+	}
+	return surveys, nil
+}
+
+// GetSurveysAndSurveyResponses gets surveys and matching survey responses. If audience is provided, only the surveys
+// without an audience, created by the user or whose audience includes the user are returned. The surveys matching the
+// external ID patterns or event registration of the user must be resolved in the audience by the caller.
+func (a *Adapter) GetSurveysAndSurveyResponses(orgID string, appID string, creatorID *string, surveyIDs []string, surveyTypes []string, calendarEventID string, public *bool, archived *bool, limit *int, offset *int, userID *string, audience *model.SurveyAudienceUser, timeFilter *model.SurveyTimeFilter, includeDrafts bool) ([]model.Survey, []model.SurveyResponse, error) {
 	// Construct the survey filter
 	surveyFilter := bson.D{
 		{Key: "org_id", Value: orgID},
 		{Key: "app_id", Value: appID},
+		{Key: "deleted_at", Value: nil},
 	}
 
 	if creatorID != nil {
@@ -280,6 +587,18 @@ func (a *Adapter) GetSurveysAndSurveyResponses(orgID string, appID string, creat
 		}
 	}
 
+	if !includeDrafts {
+		// drafts are only visible to their creator
+		draftFilter := bson.M{"status": model.SurveyStatusDraft}
+		if userID != nil {
+			draftFilter["creator_id"] = bson.M{"$ne": *userID}
+		}
+		surveyFilter = append(surveyFilter, bson.E{Key: "$nor", Value: bson.A{draftFilter}})
+	}
+	if audience != nil {
+		surveyFilter = append(surveyFilter, bson.E{Key: "$and", Value: bson.A{bson.M{"$or": surveyAudienceFilter(*audience)}}})
+	}
+
 	// Create the aggregation pipeline
 	pipeline := mongo.Pipeline{
 		// Match stage to filter surveys
@@ -307,6 +626,7 @@ func (a *Adapter) GetSurveysAndSurveyResponses(orgID string, appID string, creat
 						{Key: "user_id", Value: 1},
 						{Key: "date_created", Value: 1},
 						{Key: "survey", Value: 1},
+						{Key: "status", Value: 1},
 					}}},
 				}},
 				{Key: "as", Value: "responses"},
@@ -342,6 +662,9 @@ func (a *Adapter) GetSurveysAndSurveyResponses(orgID string, appID string, creat
 			{Key: "public", Value: 1},
 			{Key: "archived", Value: 1},
 			{Key: "estimated_completion_time", Value: 1},
+			{Key: "status", Value: 1},
+			{Key: "recurrence", Value: 1},
+			{Key: "audience", Value: 1},
 			{Key: "responses", Value: "$responses"},
 		}}},
 		// Sort stage if needed
@@ -383,5 +706,9 @@ func (a *Adapter) GetSurveysAndSurveyResponses(orgID string, appID string, creat
 		surveyResponses = append(surveyResponses, responses...)
 	}
 
+	err = a.decryptSurveyResponses(surveyResponses)
+	if err != nil {
+		return nil, nil, err
+	}
 	return surveys, surveyResponses, nil
 }
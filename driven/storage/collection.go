@@ -54,6 +54,31 @@ func (collWrapper *collectionWrapper) Find(ctx context.Context, filter interface
 	return err
 }
 
+// FindEach calls handle with the cursor positioned on each document matching the filter. The documents are not loaded
+// into memory at once and the query is not limited by the mongo timeout, so large result sets can be streamed.
+func (collWrapper *collectionWrapper) FindEach(ctx context.Context, filter interface{}, findOptions *options.FindOptions, handle func(cur *mongo.Cursor) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	cur, err := collWrapper.coll.Find(ctx, filter, findOptions)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		err = handle(cur)
+		if err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
 func (collWrapper *collectionWrapper) FindOne(ctx context.Context, filter interface{}, result interface{}, findOptions *options.FindOneOptions) error {
 	if ctx == nil {
 		ctx = context.Background()
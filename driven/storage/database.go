@@ -16,6 +16,7 @@ package storage
 
 import (
 	"application/core/interfaces"
+	"application/core/model"
 	"context"
 	"time"
 
@@ -40,7 +41,15 @@ type database struct {
 	configs         *collectionWrapper
 	surveys         *collectionWrapper
 	surveyResponses *collectionWrapper
+	archive         *collectionWrapper
+	surveyRevisions *collectionWrapper
 	alertContacts   *collectionWrapper
+	dataKeys        *collectionWrapper
+	auditEvents     *collectionWrapper
+
+	eventSurveySubscriptions *collectionWrapper
+	surveyReminders          *collectionWrapper
+	surveyReminderMutes      *collectionWrapper
 
 	listeners []interfaces.StorageListener
 }
@@ -87,12 +96,54 @@ func (d *database) start() error {
 		return err
 	}
 
+	archive := &collectionWrapper{database: d, coll: db.Collection("survey_responses_archive")}
+	err = d.applyArchiveChecks(archive)
+	if err != nil {
+		return err
+	}
+
+	surveyRevisions := &collectionWrapper{database: d, coll: db.Collection("survey_revisions")}
+	err = d.applySurveyRevisionsChecks(surveyRevisions)
+	if err != nil {
+		return err
+	}
+
 	alertContacts := &collectionWrapper{database: d, coll: db.Collection("alert_contacts")}
 	err = d.applyAlertContactsChecks(alertContacts)
 	if err != nil {
 		return err
 	}
 
+	dataKeys := &collectionWrapper{database: d, coll: db.Collection("data_keys")}
+	err = d.applyDataKeysChecks(dataKeys)
+	if err != nil {
+		return err
+	}
+
+	auditEvents := &collectionWrapper{database: d, coll: db.Collection("audit_events")}
+	err = d.applyAuditEventsChecks(auditEvents)
+	if err != nil {
+		return err
+	}
+
+	eventSurveySubscriptions := &collectionWrapper{database: d, coll: db.Collection("event_survey_subscriptions")}
+	err = d.applyEventSurveySubscriptionsChecks(eventSurveySubscriptions)
+	if err != nil {
+		return err
+	}
+
+	surveyReminders := &collectionWrapper{database: d, coll: db.Collection("survey_reminders")}
+	err = d.applySurveyRemindersChecks(surveyReminders)
+	if err != nil {
+		return err
+	}
+
+	surveyReminderMutes := &collectionWrapper{database: d, coll: db.Collection("survey_reminder_mutes")}
+	err = d.applySurveyReminderMutesChecks(surveyReminderMutes)
+	if err != nil {
+		return err
+	}
+
 	//assign the db, db client and the collections
 	d.db = db
 	d.dbClient = client
@@ -100,7 +151,14 @@ func (d *database) start() error {
 	d.configs = configs
 	d.surveys = surveys
 	d.surveyResponses = surveyResponses
+	d.archive = archive
+	d.surveyRevisions = surveyRevisions
 	d.alertContacts = alertContacts
+	d.dataKeys = dataKeys
+	d.auditEvents = auditEvents
+	d.eventSurveySubscriptions = eventSurveySubscriptions
+	d.surveyReminders = surveyReminders
+	d.surveyReminderMutes = surveyReminderMutes
 
 	go d.configs.Watch(nil, d.logger)
 
@@ -132,6 +190,22 @@ func (d *database) applySurveysChecks(surveys *collectionWrapper) error {
 		return err
 	}
 
+	err = surveys.AddIndex(nil, bson.D{primitive.E{Key: "status", Value: 1}, primitive.E{Key: "start_date", Value: 1}, primitive.E{Key: "end_date", Value: 1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	err = surveys.AddIndex(nil, bson.D{primitive.E{Key: "org_id", Value: 1}, primitive.E{Key: "app_id", Value: 1}, primitive.E{Key: "template.in_catalog", Value: 1}}, false,
+		bson.D{primitive.E{Key: "template", Value: bson.M{"$exists": true}}})
+	if err != nil {
+		return err
+	}
+
+	err = surveys.AddIndex(nil, bson.D{primitive.E{Key: "status", Value: 1}}, false, bson.D{primitive.E{Key: "recurrence", Value: bson.M{"$type": "object"}}})
+	if err != nil {
+		return err
+	}
+
 	d.logger.Info("surveys passed")
 	return nil
 }
@@ -149,10 +223,84 @@ func (d *database) applySurveyResponsesChecks(surveyResponses *collectionWrapper
 		return err
 	}
 
+	// enforces the survey response policies
+	err = surveyResponses.AddIndex(nil, bson.D{primitive.E{Key: "limit_key", Value: 1}}, true, bson.D{primitive.E{Key: "limit_key", Value: bson.M{"$exists": true}}})
+	if err != nil {
+		return err
+	}
+
+	// idempotency keys are scoped to the survey, drop the index from when they were unique per user
+	indexes, err := surveyResponses.ListIndexes(nil, d.logger)
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if index["name"] == "org_id_1_app_id_1_user_id_1_idempotency_key_1" {
+			err = surveyResponses.DropIndex(nil, "org_id_1_app_id_1_user_id_1_idempotency_key_1")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = surveyResponses.AddIndex(nil, bson.D{primitive.E{Key: "org_id", Value: 1}, primitive.E{Key: "app_id", Value: 1}, primitive.E{Key: "user_id", Value: 1}, primitive.E{Key: "survey._id", Value: 1}, primitive.E{Key: "idempotency_key", Value: 1}},
+		true, bson.D{primitive.E{Key: "idempotency_key", Value: bson.M{"$exists": true}}})
+	if err != nil {
+		return err
+	}
+
+	// streams responses in a stable order
+	err = surveyResponses.AddIndex(nil, bson.D{primitive.E{Key: "date_created", Value: 1}, primitive.E{Key: "_id", Value: 1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	// a user has at most one in progress response per survey
+	err = surveyResponses.AddIndex(nil, bson.D{primitive.E{Key: "survey._id", Value: 1}, primitive.E{Key: "user_id", Value: 1}}, true,
+		bson.D{primitive.E{Key: "status", Value: model.SurveyResponseStatusInProgress}})
+	if err != nil {
+		return err
+	}
+
+	err = surveyResponses.AddIndex(nil, bson.D{primitive.E{Key: "status", Value: 1}, primitive.E{Key: "date_updated", Value: 1}}, false,
+		bson.D{primitive.E{Key: "status", Value: model.SurveyResponseStatusInProgress}})
+	if err != nil {
+		return err
+	}
+
 	d.logger.Info("survey responses passed")
 	return nil
 }
 
+func (d *database) applyArchiveChecks(archive *collectionWrapper) error {
+	d.logger.Info("apply survey responses archive checks.....")
+
+	err := archive.AddIndex(nil, bson.D{primitive.E{Key: "org_id", Value: 1}, primitive.E{Key: "app_id", Value: 1}, primitive.E{Key: "survey._id", Value: 1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("survey responses archive passed")
+	return nil
+}
+
+func (d *database) applySurveyRevisionsChecks(surveyRevisions *collectionWrapper) error {
+	d.logger.Info("apply survey revisions checks.....")
+
+	err := surveyRevisions.AddIndex(nil, bson.D{primitive.E{Key: "survey_id", Value: 1}, primitive.E{Key: "revision", Value: 1}}, true, nil)
+	if err != nil {
+		return err
+	}
+
+	err = surveyRevisions.AddIndex(nil, bson.D{primitive.E{Key: "org_id", Value: 1}, primitive.E{Key: "app_id", Value: 1}, primitive.E{Key: "survey.creator_id", Value: 1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("survey revisions passed")
+	return nil
+}
+
 func (d *database) applyAlertContactsChecks(alertContacts *collectionWrapper) error {
 	d.logger.Info("apply alert contacts checks.....")
 
@@ -165,6 +313,91 @@ func (d *database) applyAlertContactsChecks(alertContacts *collectionWrapper) er
 	return nil
 }
 
+func (d *database) applyDataKeysChecks(dataKeys *collectionWrapper) error {
+	d.logger.Info("apply data keys checks.....")
+
+	err := dataKeys.AddIndex(nil, bson.D{primitive.E{Key: "org_id", Value: 1}, primitive.E{Key: "version", Value: 1}}, true, nil)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("data keys passed")
+	return nil
+}
+
+func (d *database) applyEventSurveySubscriptionsChecks(eventSurveySubscriptions *collectionWrapper) error {
+	d.logger.Info("apply event survey subscriptions checks.....")
+
+	err := eventSurveySubscriptions.AddIndex(nil, bson.D{primitive.E{Key: "calendar_event_id", Value: 1}}, true, nil)
+	if err != nil {
+		return err
+	}
+
+	err = eventSurveySubscriptions.AddIndex(nil, bson.D{primitive.E{Key: "status", Value: 1}, primitive.E{Key: "event_end_date", Value: 1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	err = eventSurveySubscriptions.AddIndex(nil, bson.D{primitive.E{Key: "org_id", Value: 1}, primitive.E{Key: "app_id", Value: 1}, primitive.E{Key: "event_end_date", Value: 1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("event survey subscriptions passed")
+	return nil
+}
+
+func (d *database) applySurveyRemindersChecks(surveyReminders *collectionWrapper) error {
+	d.logger.Info("apply survey reminders checks.....")
+
+	err := surveyReminders.AddIndex(nil, bson.D{primitive.E{Key: "survey_id", Value: 1}, primitive.E{Key: "user_id", Value: 1}, primitive.E{Key: "offset", Value: 1}}, true, nil)
+	if err != nil {
+		return err
+	}
+
+	err = surveyReminders.AddIndex(nil, bson.D{primitive.E{Key: "org_id", Value: 1}, primitive.E{Key: "app_id", Value: 1}, primitive.E{Key: "user_id", Value: 1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("survey reminders passed")
+	return nil
+}
+
+func (d *database) applySurveyReminderMutesChecks(surveyReminderMutes *collectionWrapper) error {
+	d.logger.Info("apply survey reminder mutes checks.....")
+
+	err := surveyReminderMutes.AddIndex(nil, bson.D{primitive.E{Key: "org_id", Value: 1}, primitive.E{Key: "app_id", Value: 1}, primitive.E{Key: "user_id", Value: 1}, primitive.E{Key: "survey_id", Value: 1}}, true, nil)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("survey reminder mutes passed")
+	return nil
+}
+
+func (d *database) applyAuditEventsChecks(auditEvents *collectionWrapper) error {
+	d.logger.Info("apply audit events checks.....")
+
+	err := auditEvents.AddIndex(nil, bson.D{primitive.E{Key: "org_id", Value: 1}, primitive.E{Key: "app_id", Value: 1}, primitive.E{Key: "date_created", Value: -1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	err = auditEvents.AddIndex(nil, bson.D{primitive.E{Key: "entity", Value: 1}, primitive.E{Key: "entity_id", Value: 1}, primitive.E{Key: "date_created", Value: -1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	err = auditEvents.AddIndex(nil, bson.D{primitive.E{Key: "actor.account_id", Value: 1}, primitive.E{Key: "date_created", Value: -1}}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("audit events passed")
+	return nil
+}
+
 func (d *database) onDataChanged(changeDoc map[string]interface{}) {
 	if changeDoc == nil {
 		return
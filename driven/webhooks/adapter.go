@@ -0,0 +1,62 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rokwire/logging-library-go/v2/errors"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+// requestTimeout is how long a webhook endpoint has to respond
+const requestTimeout = 10 * time.Second
+
+// Adapter implements the Webhooks interface
+type Adapter struct {
+	client *http.Client
+}
+
+// NewWebhooksAdapter creates a new webhooks adapter instance
+func NewWebhooksAdapter() *Adapter {
+	return &Adapter{client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Post posts the JSON body to the webhook endpoint. Responses other than 2xx are returned as errors.
+func (a *Adapter) Post(url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionCreate, logutils.TypeRequest, nil, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return errors.WrapErrorAction(logutils.ActionSend, logutils.TypeRequest, nil, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Newf("webhook error response code (%d): %s", resp.StatusCode, respBytes)
+	}
+	return nil
+}
@@ -16,10 +16,13 @@ package web
 
 import (
 	"application/core"
+	"application/core/model"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -59,6 +62,9 @@ type Adapter struct {
 
 type handlerFunc = func(*logs.Log, *http.Request, *tokenauth.Claims) logs.HTTPResponse
 
+// streamHandlerFunc writes the response body to the response writer itself. It returns a response only if nothing has been written.
+type streamHandlerFunc = func(*logs.Log, *http.Request, *tokenauth.Claims, http.ResponseWriter) *logs.HTTPResponse
+
 // Start starts the module
 func (a Adapter) Start() {
 
@@ -76,9 +82,28 @@ func (a Adapter) Start() {
 	mainRouter.HandleFunc("/surveys", a.wrapFunc(a.clientAPIsHandler.getSurveys, a.auth.client.User)).Methods("GET")
 	mainRouter.HandleFunc("/surveys/{id}", a.wrapFunc(a.clientAPIsHandler.getSurvey, a.auth.client.User)).Methods("GET")
 	mainRouter.HandleFunc("/surveys", a.wrapFunc(a.clientAPIsHandler.createSurvey, a.auth.client.User)).Methods("POST")
+	mainRouter.HandleFunc("/surveys/validate", a.wrapFunc(a.clientAPIsHandler.validateSurvey, a.auth.client.User)).Methods("POST")
 	mainRouter.HandleFunc("/surveys/{id}", a.wrapFunc(a.clientAPIsHandler.updateSurvey, a.auth.client.User)).Methods("PUT")
 	mainRouter.HandleFunc("/surveys/{id}", a.wrapFunc(a.clientAPIsHandler.deleteSurvey, a.auth.client.User)).Methods("DELETE")
+	mainRouter.HandleFunc("/surveys/{id}/publish", a.wrapFunc(a.clientAPIsHandler.publishSurvey, a.auth.client.User)).Methods("POST")
+	mainRouter.HandleFunc("/surveys/{id}/unpublish", a.wrapFunc(a.clientAPIsHandler.unpublishSurvey, a.auth.client.User)).Methods("POST")
+	mainRouter.HandleFunc("/surveys/{id}/template", a.wrapFunc(a.clientAPIsHandler.setSurveyTemplate, a.auth.client.User)).Methods("PUT")
+	mainRouter.HandleFunc("/surveys/{id}/template", a.wrapFunc(a.clientAPIsHandler.removeSurveyTemplate, a.auth.client.User)).Methods("DELETE")
+	mainRouter.HandleFunc("/surveys/{id}/clone", a.wrapFunc(a.clientAPIsHandler.cloneSurvey, a.auth.client.User)).Methods("POST")
+	mainRouter.HandleFunc("/survey-templates", a.wrapFunc(a.clientAPIsHandler.getSurveyTemplates, a.auth.client.User)).Methods("GET")
+	mainRouter.HandleFunc("/events/{event_id}/survey-subscription", a.wrapFunc(a.clientAPIsHandler.getEventSurveySubscription, a.auth.client.User)).Methods("GET")
+	mainRouter.HandleFunc("/events/{event_id}/survey-subscription", a.wrapFunc(a.clientAPIsHandler.subscribeEventSurvey, a.auth.client.User)).Methods("PUT")
+	mainRouter.HandleFunc("/events/{event_id}/survey-subscription", a.wrapFunc(a.clientAPIsHandler.unsubscribeEventSurvey, a.auth.client.User)).Methods("DELETE")
+	mainRouter.HandleFunc("/survey-reminder-mutes", a.wrapFunc(a.clientAPIsHandler.getSurveyReminderMutes, a.auth.client.User)).Methods("GET")
+	mainRouter.HandleFunc("/survey-reminder-mutes", a.wrapFunc(a.clientAPIsHandler.muteSurveyReminders, a.auth.client.User)).Methods("PUT")
+	mainRouter.HandleFunc("/survey-reminder-mutes", a.wrapFunc(a.clientAPIsHandler.unmuteSurveyReminders, a.auth.client.User)).Methods("DELETE")
+	mainRouter.HandleFunc("/surveys/{id}/responses", a.wrapStreamFunc(a.clientAPIsHandler.exportSurveyResponses, a.auth.client.User)).Methods("GET").Queries("format", "{format:csv|xlsx}")
 	mainRouter.HandleFunc("/surveys/{id}/responses", a.wrapFunc(a.clientAPIsHandler.getAllSurveyResponses, a.auth.client.User)).Methods("GET")
+	mainRouter.HandleFunc("/surveys/{id}/results", a.wrapFunc(a.clientAPIsHandler.getSurveyResults, a.auth.client.User)).Methods("GET")
+	mainRouter.HandleFunc("/surveys/{id}/evaluate", a.wrapFunc(a.clientAPIsHandler.evaluateSurvey, a.auth.client.User)).Methods("POST")
+	mainRouter.HandleFunc("/surveys/{id}/draft-response", a.wrapFunc(a.clientAPIsHandler.getSurveyResponseDraft, a.auth.client.User)).Methods("GET")
+	mainRouter.HandleFunc("/surveys/{id}/draft-response/finalize", a.wrapFunc(a.clientAPIsHandler.finalizeSurveyResponseDraft, a.auth.client.User)).Methods("POST")
+	mainRouter.HandleFunc("/surveys/{id}/draft-response/{key}", a.wrapFunc(a.clientAPIsHandler.saveSurveyResponseDraft, a.auth.client.User)).Methods("PUT")
 	mainRouter.HandleFunc("/survey-responses/{id}", a.wrapFunc(a.clientAPIsHandler.getSurveyResponse, a.auth.client.User)).Methods("GET")
 	mainRouter.HandleFunc("/survey-responses", a.wrapFunc(a.clientAPIsHandler.getUserSurveyResponses, a.auth.client.User)).Methods("GET")
 	mainRouter.HandleFunc("/survey-responses", a.wrapFunc(a.clientAPIsHandler.createSurveyResponse, a.auth.client.User)).Methods("POST")
@@ -98,11 +123,29 @@ func (a Adapter) Start() {
 	adminRouter.HandleFunc("/configs/{id}", a.wrapFunc(a.adminAPIsHandler.deleteConfig, a.auth.admin.Permissions)).Methods("DELETE")
 
 	adminRouter.HandleFunc("/surveys", a.wrapFunc(a.adminAPIsHandler.getSurveys, a.auth.admin.Permissions)).Methods("GET")
+	adminRouter.HandleFunc("/surveys/trash", a.wrapFunc(a.adminAPIsHandler.getDeletedSurveys, a.auth.admin.Permissions)).Methods("GET")
 	adminRouter.HandleFunc("/surveys/{id}", a.wrapFunc(a.adminAPIsHandler.getSurvey, a.auth.admin.Permissions)).Methods("GET")
 	adminRouter.HandleFunc("/surveys", a.wrapFunc(a.adminAPIsHandler.createSurvey, a.auth.admin.Permissions)).Methods("POST")
+	adminRouter.HandleFunc("/surveys/validate", a.wrapFunc(a.adminAPIsHandler.validateSurvey, a.auth.admin.Permissions)).Methods("POST")
 	adminRouter.HandleFunc("/surveys/{id}", a.wrapFunc(a.adminAPIsHandler.updateSurvey, a.auth.admin.Permissions)).Methods("PUT")
 	adminRouter.HandleFunc("/surveys/{id}", a.wrapFunc(a.adminAPIsHandler.deleteSurvey, a.auth.admin.Permissions)).Methods("DELETE")
+	adminRouter.HandleFunc("/surveys/{id}/restore", a.wrapFunc(a.adminAPIsHandler.restoreSurvey, a.auth.admin.Permissions)).Methods("POST")
+	adminRouter.HandleFunc("/surveys/{id}/publish", a.wrapFunc(a.adminAPIsHandler.publishSurvey, a.auth.admin.Permissions)).Methods("POST")
+	adminRouter.HandleFunc("/surveys/{id}/unpublish", a.wrapFunc(a.adminAPIsHandler.unpublishSurvey, a.auth.admin.Permissions)).Methods("POST")
+	adminRouter.HandleFunc("/surveys/{id}/template", a.wrapFunc(a.adminAPIsHandler.setSurveyTemplate, a.auth.admin.Permissions)).Methods("PUT")
+	adminRouter.HandleFunc("/surveys/{id}/template", a.wrapFunc(a.adminAPIsHandler.removeSurveyTemplate, a.auth.admin.Permissions)).Methods("DELETE")
+	adminRouter.HandleFunc("/surveys/{id}/clone", a.wrapFunc(a.adminAPIsHandler.cloneSurvey, a.auth.admin.Permissions)).Methods("POST")
+	adminRouter.HandleFunc("/survey-templates", a.wrapFunc(a.adminAPIsHandler.getSurveyTemplates, a.auth.admin.Permissions)).Methods("GET")
+	adminRouter.HandleFunc("/event-survey-subscriptions", a.wrapFunc(a.adminAPIsHandler.getEventSurveySubscriptions, a.auth.admin.Permissions)).Methods("GET")
+	adminRouter.HandleFunc("/surveys/{id}/reminders", a.wrapFunc(a.adminAPIsHandler.getSurveyReminders, a.auth.admin.Permissions)).Methods("GET")
+	adminRouter.HandleFunc("/surveys/{id}/audience", a.wrapFunc(a.adminAPIsHandler.getSurveyAudience, a.auth.admin.Permissions)).Methods("GET")
+	adminRouter.HandleFunc("/surveys/{id}/response-window", a.wrapFunc(a.adminAPIsHandler.setSurveyResponseWindowOverride, a.auth.admin.Permissions)).Methods("PUT")
+	adminRouter.HandleFunc("/surveys/{id}/revisions", a.wrapFunc(a.adminAPIsHandler.getSurveyRevisions, a.auth.admin.Permissions)).Methods("GET")
+	adminRouter.HandleFunc("/surveys/{id}/revisions/diff", a.wrapFunc(a.adminAPIsHandler.getSurveyRevisionDiff, a.auth.admin.Permissions)).Methods("GET")
+	adminRouter.HandleFunc("/surveys/{id}/revisions/{revision:[0-9]+}", a.wrapFunc(a.adminAPIsHandler.getSurveyRevision, a.auth.admin.Permissions)).Methods("GET")
+	adminRouter.HandleFunc("/surveys/{id}/revisions/{revision:[0-9]+}/restore", a.wrapFunc(a.adminAPIsHandler.restoreSurveyRevision, a.auth.admin.Permissions)).Methods("POST")
 	adminRouter.HandleFunc("/surveys/{id}/responses", a.wrapFunc(a.adminAPIsHandler.getAllSurveyResponses, a.auth.admin.User)).Methods("GET")
+	adminRouter.HandleFunc("/surveys/{id}/response", a.wrapStreamFunc(a.adminAPIsHandler.exportSurveyResponses, a.auth.admin.Permissions)).Methods("GET").Queries("format", "{format:csv|xlsx}")
 	adminRouter.HandleFunc("/surveys/{id}/response", a.wrapFunc(a.adminAPIsHandler.getAllSurveysResponses, a.auth.admin.Permissions)).Methods("GET")
 
 	adminRouter.HandleFunc("/alert-contacts", a.wrapFunc(a.adminAPIsHandler.getAlertContacts, a.auth.admin.Permissions)).Methods("GET")
@@ -111,8 +154,11 @@ func (a Adapter) Start() {
 	adminRouter.HandleFunc("/alert-contacts/{id}", a.wrapFunc(a.adminAPIsHandler.updateAlertContact, a.auth.admin.Permissions)).Methods("PUT")
 	adminRouter.HandleFunc("/alert-contacts/{id}", a.wrapFunc(a.adminAPIsHandler.deleteAlertContact, a.auth.admin.Permissions)).Methods("DELETE")
 
+	adminRouter.HandleFunc("/audit-events", a.wrapFunc(a.adminAPIsHandler.getAuditEvents, a.auth.admin.Permissions)).Methods("GET")
+
 	// Analytics APIs
 	analyticsRouter := mainRouter.PathPrefix("/analytics").Subrouter()
+	analyticsRouter.HandleFunc("/survey-responses", a.wrapStreamFunc(a.analyticsAPIsHandler.streamAnonymousSurveyResponses, a.auth.analytics)).Methods("GET").Queries("format", "ndjson")
 	analyticsRouter.HandleFunc("/survey-responses", a.wrapFunc(a.analyticsAPIsHandler.getAnonymousSurveyResponses, a.auth.analytics)).Methods("GET")
 
 	// BB APIs
@@ -122,7 +168,9 @@ func (a Adapter) Start() {
 	// tpsRouter := mainRouter.PathPrefix("/tps").Subrouter()
 
 	// System APIs
-	// systemRouter := mainRouter.PathPrefix("/system").Subrouter()
+	systemRouter := mainRouter.PathPrefix("/system").Subrouter()
+	systemRouter.HandleFunc("/data-keys/{org_id}", a.wrapFunc(a.systemAPIsHandler.getDataKeys, a.auth.system.Permissions)).Methods("GET")
+	systemRouter.HandleFunc("/data-keys/{org_id}/rotate", a.wrapFunc(a.systemAPIsHandler.rotateDataKey, a.auth.system.Permissions)).Methods("POST")
 
 	a.logger.Fatalf("Error serving: %v", http.ListenAndServe(":"+a.port, router))
 }
@@ -198,6 +246,152 @@ func (a Adapter) wrapFunc(handler handlerFunc, authorization tokenauth.Handler)
 	}
 }
 
+func (a Adapter) wrapStreamFunc(handler streamHandlerFunc, authorization tokenauth.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		logObj := a.logger.NewRequestLog(req)
+
+		logObj.RequestReceived()
+
+		var claims *tokenauth.Claims
+		if authorization != nil {
+			responseStatus, authClaims, err := authorization.Check(req)
+			if err != nil {
+				logObj.SendHTTPResponse(w, logObj.HTTPResponseErrorAction(logutils.ActionValidate, logutils.TypeRequest, nil, err, responseStatus, true))
+				return
+			}
+
+			if authClaims != nil {
+				logObj.SetContext("account_id", authClaims.Subject)
+			}
+			claims = authClaims
+		}
+
+		response := handler(logObj, req, claims, w)
+		if response != nil {
+			logObj.SendHTTPResponse(w, *response)
+		}
+		logObj.RequestComplete()
+	}
+}
+
+// surveyResponseErrorResponse generates the HTTP response for an error returned when creating or updating a survey response
+func surveyResponseErrorResponse(l *logs.Log, action logutils.MessageActionType, err error) logs.HTTPResponse {
+	if windowErr, ok := err.(*model.SurveyResponseWindowError); ok {
+		return surveyResponseWindowErrorResponse(l, action, windowErr)
+	}
+	if limitErr, ok := err.(*model.SurveyResponseLimitError); ok {
+		return surveyResponseLimitErrorResponse(l, action, limitErr)
+	}
+	if _, ok := err.(*model.SurveyAudienceError); ok {
+		return l.HTTPResponseErrorAction(action, model.TypeSurveyResponse, nil, err, http.StatusForbidden, true)
+	}
+
+	validationErr, ok := err.(*model.SurveyResponseValidationError)
+	if !ok {
+		return l.HTTPResponseErrorAction(action, model.TypeSurveyResponse, nil, err, http.StatusInternalServerError, true)
+	}
+
+	l.SetContext("status", model.ValidationStatusInvalidSurveyResponse)
+	message := l.LogError(logutils.MessageAction(logutils.StatusError, action, model.TypeSurveyResponse, nil), err)
+	response := struct {
+		Status  string                  `json:"status"`
+		Message string                  `json:"message"`
+		Errors  []model.SurveyDataError `json:"errors"`
+	}{Status: model.ValidationStatusInvalidSurveyResponse, Message: message, Errors: validationErr.Errors}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+	return l.HTTPResponseSuccessStatusJSON(data, http.StatusBadRequest)
+}
+
+// surveyResponsesErrorResponse generates the HTTP response for errors returned when getting the responses to a survey
+func surveyResponsesErrorResponse(l *logs.Log, action logutils.MessageActionType, err error) logs.HTTPResponse {
+	if _, ok := err.(*model.SurveyPrivacyError); ok {
+		return l.HTTPResponseErrorAction(action, model.TypeSurveyResponse, nil, err, http.StatusForbidden, true)
+	}
+	if _, ok := err.(*model.InvalidCursorError); ok {
+		return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("cursor"), err, http.StatusBadRequest, false)
+	}
+	return l.HTTPResponseErrorAction(action, model.TypeSurveyResponse, nil, err, http.StatusInternalServerError, true)
+}
+
+// surveyResponseWindowErrorResponse generates the HTTP response for a survey response submitted outside of the survey response window
+func surveyResponseWindowErrorResponse(l *logs.Log, action logutils.MessageActionType, err *model.SurveyResponseWindowError) logs.HTTPResponse {
+	l.SetContext("status", err.Code)
+	message := l.LogError(logutils.MessageAction(logutils.StatusError, action, model.TypeSurveyResponse, nil), err)
+	response := struct {
+		Status    string     `json:"status"`
+		Message   string     `json:"message"`
+		StartDate *time.Time `json:"start_date"`
+		EndDate   *time.Time `json:"end_date"`
+	}{Status: err.Code, Message: message, StartDate: err.StartDate, EndDate: err.EndDate}
+	data, marshalErr := json.Marshal(response)
+	if marshalErr != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, marshalErr, http.StatusInternalServerError, false)
+	}
+	return l.HTTPResponseSuccessStatusJSON(data, http.StatusForbidden)
+}
+
+// surveyResponseLimitErrorResponse generates the HTTP response for a survey response refused by the survey response policy
+func surveyResponseLimitErrorResponse(l *logs.Log, action logutils.MessageActionType, err *model.SurveyResponseLimitError) logs.HTTPResponse {
+	l.SetContext("status", err.Code)
+	message := l.LogError(logutils.MessageAction(logutils.StatusError, action, model.TypeSurveyResponse, nil), err)
+	response := struct {
+		Status  string                     `json:"status"`
+		Message string                     `json:"message"`
+		Policy  model.SurveyResponsePolicy `json:"policy"`
+	}{Status: err.Code, Message: message, Policy: err.Policy}
+	data, marshalErr := json.Marshal(response)
+	if marshalErr != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, marshalErr, http.StatusInternalServerError, false)
+	}
+	return l.HTTPResponseSuccessStatusJSON(data, http.StatusConflict)
+}
+
+// surveyErrorResponse builds the response for errors returned when saving a survey definition or changing its status. Lint
+// errors are returned with the issues found so the author can fix the definition.
+func surveyErrorResponse(l *logs.Log, action logutils.MessageActionType, err error) logs.HTTPResponse {
+	if _, ok := err.(*model.SurveyStatusError); ok {
+		return l.HTTPResponseErrorAction(action, model.TypeSurvey, nil, err, http.StatusBadRequest, true)
+	}
+	if _, ok := err.(*model.DuplicateKeyError); ok {
+		return l.HTTPResponseErrorAction(action, model.TypeSurvey, nil, err, http.StatusConflict, true)
+	}
+
+	lintErr, ok := err.(*model.SurveyLintError)
+	if !ok {
+		return l.HTTPResponseErrorAction(action, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+	}
+
+	l.SetContext("status", model.ValidationStatusInvalidSurvey)
+	message := l.LogError(logutils.MessageAction(logutils.StatusError, action, model.TypeSurvey, nil), err)
+	response := struct {
+		Status  string                  `json:"status"`
+		Message string                  `json:"message"`
+		Errors  []model.SurveyLintIssue `json:"errors"`
+	}{Status: model.ValidationStatusInvalidSurvey, Message: message, Errors: lintErr.Errors}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+	return l.HTTPResponseSuccessStatusJSON(data, http.StatusBadRequest)
+}
+
+// auditContext returns the actor and the request recorded in the audit log for changes made by the request
+func auditContext(l *logs.Log, claims *tokenauth.Claims) model.AuditContext {
+	actor := model.AuditActor{AccountID: claims.Subject, AppID: claims.AppID, OrgID: claims.OrgID, Admin: claims.Admin, System: claims.System, Service: claims.Service}
+	return model.AuditContext{Actor: actor, RequestID: l.TraceID()}
+}
+
+// claimsPermissions returns the permissions granted by the token
+func claimsPermissions(claims *tokenauth.Claims) []string {
+	if claims.Permissions == "" {
+		return nil
+	}
+	return strings.Split(claims.Permissions, ",")
+}
+
 // NewWebAdapter creates new WebAdapter instance
 func NewWebAdapter(baseURL string, port string, serviceID string, app *core.Application, serviceRegManager *authservice.ServiceRegManager, logger *logs.Logger) Adapter {
 	yamlDoc, err := loadDocsYAML(baseURL)
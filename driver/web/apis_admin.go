@@ -101,7 +101,7 @@ func (h AdminAPIsHandler) createConfig(l *logs.Log, r *http.Request, claims *tok
 	}
 	config := model.Config{Type: requestData.Type, AppID: appID, OrgID: orgID, System: requestData.System, Data: requestData.Data}
 
-	newConfig, err := h.app.Admin.CreateConfig(config, claims)
+	newConfig, err := h.app.Admin.CreateConfig(config, claims, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionCreate, model.TypeConfig, nil, err, http.StatusInternalServerError, true)
 	}
@@ -137,7 +137,7 @@ func (h AdminAPIsHandler) updateConfig(l *logs.Log, r *http.Request, claims *tok
 	}
 	config := model.Config{ID: id, Type: requestData.Type, AppID: appID, OrgID: orgID, System: requestData.System, Data: requestData.Data}
 
-	err = h.app.Admin.UpdateConfig(config, claims)
+	err = h.app.Admin.UpdateConfig(config, claims, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeConfig, nil, err, http.StatusInternalServerError, true)
 	}
@@ -152,7 +152,7 @@ func (h AdminAPIsHandler) deleteConfig(l *logs.Log, r *http.Request, claims *tok
 		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
 	}
 
-	err := h.app.Admin.DeleteConfig(id, claims)
+	err := h.app.Admin.DeleteConfig(id, claims, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeConfig, nil, err, http.StatusInternalServerError, true)
 	}
@@ -302,9 +302,19 @@ func (h AdminAPIsHandler) createSurvey(l *logs.Log, r *http.Request, claims *tok
 
 	item := surveyRequestToSurvey(items)
 
-	createdItem, err := h.app.Admin.CreateSurvey(item, claims.ExternalIDs)
+	strict := false
+	strictRaw := r.URL.Query().Get("strict")
+	if len(strictRaw) > 0 {
+		boolParsed, err := strconv.ParseBool(strictRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("strict"), nil, http.StatusBadRequest, false)
+		}
+		strict = boolParsed
+	}
+
+	createdItem, err := h.app.Admin.CreateSurvey(item, claims.ExternalIDs, strict, auditContext(l, claims))
 	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionCreate, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+		return surveyErrorResponse(l, logutils.ActionCreate, err)
 	}
 
 	data, err := json.Marshal(createdItem)
@@ -316,6 +326,30 @@ func (h AdminAPIsHandler) createSurvey(l *logs.Log, r *http.Request, claims *tok
 
 }
 
+func (h AdminAPIsHandler) validateSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	var items model.SurveyRequest
+	err := json.NewDecoder(r.Body).Decode(&items)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+	items.CreatorID = claims.Subject
+	items.OrgID = claims.OrgID
+	items.AppID = claims.AppID
+	if items.Type == "" {
+		items.Type = "user"
+	}
+	item := surveyRequestToSurvey(items)
+
+	resData := h.app.Admin.ValidateSurvey(item)
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
 func (h AdminAPIsHandler) updateSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -338,9 +372,19 @@ func (h AdminAPIsHandler) updateSurvey(l *logs.Log, r *http.Request, claims *tok
 
 	item := updateSurveyRequestToSurvey(items, id)
 
-	err = h.app.Admin.UpdateSurvey(item, claims.Subject, claims.ExternalIDs)
+	strict := false
+	strictRaw := r.URL.Query().Get("strict")
+	if len(strictRaw) > 0 {
+		boolParsed, err := strconv.ParseBool(strictRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("strict"), nil, http.StatusBadRequest, false)
+		}
+		strict = boolParsed
+	}
+
+	err = h.app.Admin.UpdateSurvey(item, claims.Subject, claims.ExternalIDs, strict, auditContext(l, claims))
 	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+		return surveyErrorResponse(l, logutils.ActionUpdate, err)
 	}
 
 	return l.HTTPResponseSuccess()
@@ -353,7 +397,14 @@ func (h AdminAPIsHandler) deleteSurvey(l *logs.Log, r *http.Request, claims *tok
 		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
 	}
 
-	err := h.app.Admin.DeleteSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs)
+	responsesPolicy := r.URL.Query().Get("responses")
+	switch responsesPolicy {
+	case "", model.DeletedSurveyResponsesKeep, model.DeletedSurveyResponsesCascade, model.DeletedSurveyResponsesArchive:
+	default:
+		return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("responses"), nil, http.StatusBadRequest, false)
+	}
+
+	err := h.app.Admin.DeleteSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, responsesPolicy, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
 	}
@@ -361,6 +412,460 @@ func (h AdminAPIsHandler) deleteSurvey(l *logs.Log, r *http.Request, claims *tok
 	return l.HTTPResponseSuccess()
 }
 
+func (h AdminAPIsHandler) getDeletedSurveys(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	var limit *int
+	limitRaw := r.URL.Query().Get("limit")
+	if len(limitRaw) > 0 {
+		intParsed, err := strconv.Atoi(limitRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("limit"), nil, http.StatusBadRequest, false)
+		}
+		limit = &intParsed
+	}
+	var offset *int
+	offsetRaw := r.URL.Query().Get("offset")
+	if len(offsetRaw) > 0 {
+		intParsed, err := strconv.Atoi(offsetRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("offset"), nil, http.StatusBadRequest, false)
+		}
+		offset = &intParsed
+	}
+
+	resData, err := h.app.Admin.GetDeletedSurveys(claims.OrgID, claims.AppID, limit, offset)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) restoreSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Admin.RestoreSurvey(id, claims.OrgID, claims.AppID, auditContext(l, claims))
+	if err != nil {
+		return surveyErrorResponse(l, logutils.ActionUpdate, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) getSurveyTemplates(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	var filter model.SurveyTemplateFilter
+	inCatalogRaw := r.URL.Query().Get("in_catalog")
+	if len(inCatalogRaw) > 0 {
+		boolParsed, err := strconv.ParseBool(inCatalogRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("in_catalog"), nil, http.StatusBadRequest, false)
+		}
+		filter.InCatalog = &boolParsed
+	}
+	if creatorIDRaw := r.URL.Query().Get("creator_id"); len(creatorIDRaw) > 0 {
+		filter.CreatorID = &creatorIDRaw
+	}
+	if categoryRaw := r.URL.Query().Get("category"); len(categoryRaw) > 0 {
+		filter.Category = &categoryRaw
+	}
+	limitRaw := r.URL.Query().Get("limit")
+	if len(limitRaw) > 0 {
+		intParsed, err := strconv.Atoi(limitRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("limit"), nil, http.StatusBadRequest, false)
+		}
+		filter.Limit = &intParsed
+	}
+	offsetRaw := r.URL.Query().Get("offset")
+	if len(offsetRaw) > 0 {
+		intParsed, err := strconv.Atoi(offsetRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("offset"), nil, http.StatusBadRequest, false)
+		}
+		filter.Offset = &intParsed
+	}
+
+	resData, err := h.app.Admin.GetSurveyTemplates(claims.OrgID, claims.AppID, filter)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyTemplate, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) setSurveyTemplate(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	var item model.SurveyTemplate
+	err := json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+
+	resData, err := h.app.Admin.SetSurveyTemplate(id, claims.OrgID, claims.AppID, claims.Subject, item, auditContext(l, claims))
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeSurveyTemplate, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) removeSurveyTemplate(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Admin.RemoveSurveyTemplate(id, claims.OrgID, claims.AppID, claims.Subject, auditContext(l, claims))
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeSurveyTemplate, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) cloneSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	var overrides model.SurveyCloneOverrides
+	err := json.NewDecoder(r.Body).Decode(&overrides)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+
+	resData, err := h.app.Admin.CloneSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, overrides, auditContext(l, claims))
+	if err != nil {
+		return surveyErrorResponse(l, logutils.ActionCreate, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) getEventSurveySubscriptions(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	var status *string
+	if statusRaw := r.URL.Query().Get("status"); len(statusRaw) > 0 {
+		status = &statusRaw
+	}
+	var limit *int
+	limitRaw := r.URL.Query().Get("limit")
+	if len(limitRaw) > 0 {
+		intParsed, err := strconv.Atoi(limitRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("limit"), nil, http.StatusBadRequest, false)
+		}
+		limit = &intParsed
+	}
+	var offset *int
+	offsetRaw := r.URL.Query().Get("offset")
+	if len(offsetRaw) > 0 {
+		intParsed, err := strconv.Atoi(offsetRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("offset"), nil, http.StatusBadRequest, false)
+		}
+		offset = &intParsed
+	}
+
+	resData, err := h.app.Admin.GetEventSurveySubscriptions(claims.OrgID, claims.AppID, status, limit, offset)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeEventSurveySubscription, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) getSurveyReminders(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	var limit *int
+	limitRaw := r.URL.Query().Get("limit")
+	if len(limitRaw) > 0 {
+		intParsed, err := strconv.Atoi(limitRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("limit"), nil, http.StatusBadRequest, false)
+		}
+		limit = &intParsed
+	}
+	var offset *int
+	offsetRaw := r.URL.Query().Get("offset")
+	if len(offsetRaw) > 0 {
+		intParsed, err := strconv.Atoi(offsetRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("offset"), nil, http.StatusBadRequest, false)
+		}
+		offset = &intParsed
+	}
+
+	resData, err := h.app.Admin.GetSurveyReminders(id, claims.OrgID, claims.AppID, limit, offset)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyReminder, nil, err, http.StatusInternalServerError, true)
+	}
+	if resData == nil {
+		resData = []model.SurveyReminder{}
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) getSurveyAudience(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Admin.GetSurveyAudience(id, claims.OrgID, claims.AppID)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyAudience, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) publishSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Admin.PublishSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, auditContext(l, claims))
+	if err != nil {
+		return surveyErrorResponse(l, logutils.ActionUpdate, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) unpublishSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Admin.UnpublishSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, auditContext(l, claims))
+	if err != nil {
+		return surveyErrorResponse(l, logutils.ActionUpdate, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) setSurveyResponseWindowOverride(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	var items model.SurveyResponseWindowOverrideRequest
+	err := json.NewDecoder(r.Body).Decode(&items)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+
+	resData, err := h.app.Admin.SetSurveyResponseWindowOverride(id, claims.OrgID, claims.AppID, items.Override, auditContext(l, claims))
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) getSurveyRevisions(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	var limit *int
+	limitRaw := r.URL.Query().Get("limit")
+	if len(limitRaw) > 0 {
+		intParsed, err := strconv.Atoi(limitRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("limit"), nil, http.StatusBadRequest, false)
+		}
+		limit = &intParsed
+	}
+	var offset *int
+	offsetRaw := r.URL.Query().Get("offset")
+	if len(offsetRaw) > 0 {
+		intParsed, err := strconv.Atoi(offsetRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("offset"), nil, http.StatusBadRequest, false)
+		}
+		offset = &intParsed
+	}
+
+	resData, err := h.app.Admin.GetSurveyRevisions(id, claims.OrgID, claims.AppID, limit, offset)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyRevision, nil, err, http.StatusInternalServerError, true)
+	}
+	if resData == nil {
+		resData = []model.SurveyRevision{}
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) getSurveyRevision(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+	revision, err := strconv.Atoi(vars["revision"])
+	if err != nil {
+		return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypePathParam, logutils.StringArgs("revision"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Admin.GetSurveyRevision(id, claims.OrgID, claims.AppID, revision)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyRevision, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) getSurveyRevisionDiff(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("from"), nil, http.StatusBadRequest, false)
+	}
+	to, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("to"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Admin.GetSurveyRevisionDiff(id, claims.OrgID, claims.AppID, from, to)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyRevision, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h AdminAPIsHandler) restoreSurveyRevision(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+	revision, err := strconv.Atoi(vars["revision"])
+	if err != nil {
+		return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypePathParam, logutils.StringArgs("revision"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Admin.RestoreSurveyRevision(id, claims.OrgID, claims.AppID, revision, claims.Subject, auditContext(l, claims))
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
 func (h AdminAPIsHandler) getAllSurveyResponses(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -408,9 +913,19 @@ func (h AdminAPIsHandler) getAllSurveyResponses(l *logs.Log, r *http.Request, cl
 		offset = intParsed
 	}
 
-	resData, err := h.app.Admin.GetAllSurveyResponses(claims.OrgID, claims.AppID, id, claims.Subject, claims.ExternalIDs, startDate, endDate, &limit, &offset)
+	validate := false
+	validateRaw := r.URL.Query().Get("validate")
+	if len(validateRaw) > 0 {
+		boolParsed, err := strconv.ParseBool(validateRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("validate"), nil, http.StatusBadRequest, false)
+		}
+		validate = boolParsed
+	}
+
+	resData, err := h.app.Admin.GetAllSurveyResponses(claims.OrgID, claims.AppID, id, claims.Subject, claims.ExternalIDs, startDate, endDate, &limit, &offset, validate)
 	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+		return surveyResponsesErrorResponse(l, logutils.ActionGet, err)
 	}
 
 	data, err := json.Marshal(resData)
@@ -466,7 +981,17 @@ func (h AdminAPIsHandler) getAllSurveysResponses(l *logs.Log, r *http.Request, c
 		offset = intParsed
 	}
 
-	resData, err := h.app.Admin.GetAllSurveysResponses(claims.OrgID, claims.AppID, id, claims.Subject, claims.ExternalIDs, startDate, endDate, &limit, &offset)
+	validate := false
+	validateRaw := r.URL.Query().Get("validate")
+	if len(validateRaw) > 0 {
+		boolParsed, err := strconv.ParseBool(validateRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("validate"), nil, http.StatusBadRequest, false)
+		}
+		validate = boolParsed
+	}
+
+	resData, err := h.app.Admin.GetAllSurveysResponses(claims.OrgID, claims.AppID, id, claims.Subject, claims.ExternalIDs, startDate, endDate, &limit, &offset, validate)
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
 	}
@@ -477,6 +1002,43 @@ func (h AdminAPIsHandler) getAllSurveysResponses(l *logs.Log, r *http.Request, c
 	}
 	return l.HTTPResponseSuccessJSON(data)
 }
+
+func (h AdminAPIsHandler) exportSurveyResponses(l *logs.Log, r *http.Request, claims *tokenauth.Claims, w http.ResponseWriter) *logs.HTTPResponse {
+	vars := mux.Vars(r)
+	surveyID := vars["id"]
+
+	startDateRaw := r.URL.Query().Get("start_date")
+	var startDate *time.Time
+	if len(startDateRaw) > 0 {
+		dateParsed, err := time.Parse(time.RFC3339, startDateRaw)
+		if err != nil {
+			response := l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("start_date"), nil, http.StatusBadRequest, false)
+			return &response
+		}
+		startDate = &dateParsed
+	}
+
+	endDateRaw := r.URL.Query().Get("end_date")
+	var endDate *time.Time
+	if len(endDateRaw) > 0 {
+		dateParsed, err := time.Parse(time.RFC3339, endDateRaw)
+		if err != nil {
+			response := l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("end_date"), nil, http.StatusBadRequest, false)
+			return &response
+		}
+		endDate = &dateParsed
+	}
+
+	writer, err := newExportWriter(w, vars["format"], "survey-"+surveyID+"-responses")
+	if err != nil {
+		response := l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("format"), nil, http.StatusBadRequest, false)
+		return &response
+	}
+
+	err = h.app.Admin.ExportSurveyResponses(claims.OrgID, claims.AppID, surveyID, startDate, endDate, writer)
+	return finishExport(l, writer, err)
+}
+
 func (h AdminAPIsHandler) getAlertContacts(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
 	resData, err := h.app.Admin.GetAlertContacts(claims.OrgID, claims.AppID)
 	if err != nil {
@@ -521,7 +1083,7 @@ func (h AdminAPIsHandler) createAlertContact(l *logs.Log, r *http.Request, claim
 	item.OrgID = claims.OrgID
 	item.AppID = claims.AppID
 
-	createdItem, err := h.app.Admin.CreateAlertContact(item)
+	createdItem, err := h.app.Admin.CreateAlertContact(item, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionCreate, model.TypeAlertContact, nil, err, http.StatusInternalServerError, true)
 	}
@@ -551,7 +1113,7 @@ func (h AdminAPIsHandler) updateAlertContact(l *logs.Log, r *http.Request, claim
 	item.OrgID = claims.OrgID
 	item.AppID = claims.AppID
 
-	err = h.app.Admin.UpdateAlertContact(item)
+	err = h.app.Admin.UpdateAlertContact(item, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeAlertContact, nil, err, http.StatusInternalServerError, true)
 	}
@@ -566,7 +1128,7 @@ func (h AdminAPIsHandler) deleteAlertContact(l *logs.Log, r *http.Request, claim
 		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
 	}
 
-	err := h.app.Admin.DeleteAlertContact(id, claims.OrgID, claims.AppID)
+	err := h.app.Admin.DeleteAlertContact(id, claims.OrgID, claims.AppID, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeAlertContact, nil, err, http.StatusInternalServerError, true)
 	}
@@ -574,6 +1136,80 @@ func (h AdminAPIsHandler) deleteAlertContact(l *logs.Log, r *http.Request, claim
 	return l.HTTPResponseSuccess()
 }
 
+func (h AdminAPIsHandler) getAuditEvents(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	var filter model.AuditEventFilter
+
+	entity := r.URL.Query().Get("entity")
+	if len(entity) > 0 {
+		switch entity {
+		case model.AuditEntitySurvey, model.AuditEntitySurveyResponse, model.AuditEntityAlertContact, model.AuditEntityConfig:
+			filter.Entity = &entity
+		default:
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("entity"), nil, http.StatusBadRequest, false)
+		}
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if len(entityID) > 0 {
+		filter.EntityID = &entityID
+	}
+
+	actorID := r.URL.Query().Get("actor_id")
+	if len(actorID) > 0 {
+		filter.ActorID = &actorID
+	}
+
+	startDateRaw := r.URL.Query().Get("start_date")
+	if len(startDateRaw) > 0 {
+		dateParsed, err := time.Parse(time.RFC3339, startDateRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("start_date"), nil, http.StatusBadRequest, false)
+		}
+		filter.StartDate = &dateParsed
+	}
+
+	endDateRaw := r.URL.Query().Get("end_date")
+	if len(endDateRaw) > 0 {
+		dateParsed, err := time.Parse(time.RFC3339, endDateRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("end_date"), nil, http.StatusBadRequest, false)
+		}
+		filter.EndDate = &dateParsed
+	}
+
+	limit := 20
+	limitRaw := r.URL.Query().Get("limit")
+	if len(limitRaw) > 0 {
+		intParsed, err := strconv.Atoi(limitRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("limit"), nil, http.StatusBadRequest, false)
+		}
+		limit = intParsed
+	}
+	filter.Limit = &limit
+
+	offsetRaw := r.URL.Query().Get("offset")
+	if len(offsetRaw) > 0 {
+		intParsed, err := strconv.Atoi(offsetRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("offset"), nil, http.StatusBadRequest, false)
+		}
+		filter.Offset = &intParsed
+	}
+
+	resData, err := h.app.Admin.GetAuditEvents(claims.OrgID, claims.AppID, filter)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeAuditEvent, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
 // NewAdminAPIsHandler creates new rest Handler instance
 func NewAdminAPIsHandler(app *core.Application) AdminAPIsHandler {
 	return AdminAPIsHandler{app: app}
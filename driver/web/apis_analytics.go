@@ -34,6 +34,46 @@ type AnalyticsAPIsHandler struct {
 }
 
 func (h AnalyticsAPIsHandler) getAnonymousSurveyResponses(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	surveyTypes, startDate, endDate, errResponse := getAnonymousSurveyResponsesFilter(l, r)
+	if errResponse != nil {
+		return *errResponse
+	}
+
+	resData, err := h.app.Analytics.GetAnonymousSurveyResponses(surveyTypes, startDate, endDate)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyResponse, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+// anonymousSurveyResponseLine is a line of the streamed anonymous survey responses
+type anonymousSurveyResponseLine struct {
+	model.SurveyResponseAnonymous
+	Cursor string `json:"cursor,omitempty"`
+}
+
+func (h AnalyticsAPIsHandler) streamAnonymousSurveyResponses(l *logs.Log, r *http.Request, claims *tokenauth.Claims, w http.ResponseWriter) *logs.HTTPResponse {
+	surveyTypes, startDate, endDate, errResponse := getAnonymousSurveyResponsesFilter(l, r)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	writer := &ndjsonWriter{w: w}
+	err := h.app.Analytics.StreamAnonymousSurveyResponses(surveyTypes, startDate, endDate, r.URL.Query().Get("cursor"),
+		func(surveyResponse model.SurveyResponseAnonymous, cursor string) error {
+			return writer.WriteLine(anonymousSurveyResponseLine{SurveyResponseAnonymous: surveyResponse, Cursor: cursor})
+		})
+	return finishExport(l, writer, err)
+}
+
+// getAnonymousSurveyResponsesFilter parses the filter query params of the anonymous survey responses APIs
+func getAnonymousSurveyResponsesFilter(l *logs.Log, r *http.Request) ([]string, *time.Time, *time.Time, *logs.HTTPResponse) {
 	surveyTypesRaw := r.URL.Query().Get("survey_types")
 	var surveyTypes []string
 	if len(surveyTypesRaw) > 0 {
@@ -45,7 +85,8 @@ func (h AnalyticsAPIsHandler) getAnonymousSurveyResponses(l *logs.Log, r *http.R
 	if len(timeOffsetRaw) > 0 {
 		intParsed, err := strconv.Atoi(timeOffsetRaw)
 		if err != nil {
-			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("time_offset"), nil, http.StatusBadRequest, false)
+			response := l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("time_offset"), nil, http.StatusBadRequest, false)
+			return nil, nil, nil, &response
 		}
 		timeOffset = intParsed
 	}
@@ -55,11 +96,13 @@ func (h AnalyticsAPIsHandler) getAnonymousSurveyResponses(l *logs.Log, r *http.R
 	if len(startDateRaw) > 0 {
 		dateParsed, err := time.Parse(time.RFC3339, startDateRaw)
 		if err != nil {
-			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("start_date"), nil, http.StatusBadRequest, false)
+			response := l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("start_date"), nil, http.StatusBadRequest, false)
+			return nil, nil, nil, &response
 		}
 		startDate = &dateParsed
 	} else if timeOffset == 0 {
-		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypeQueryParam, &logutils.ListArgs{"start_date", "time_offset"}, nil, http.StatusBadRequest, false)
+		response := l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypeQueryParam, &logutils.ListArgs{"start_date", "time_offset"}, nil, http.StatusBadRequest, false)
+		return nil, nil, nil, &response
 	}
 
 	endDateRaw := r.URL.Query().Get("end_date")
@@ -67,11 +110,13 @@ func (h AnalyticsAPIsHandler) getAnonymousSurveyResponses(l *logs.Log, r *http.R
 	if len(endDateRaw) > 0 {
 		dateParsed, err := time.Parse(time.RFC3339, endDateRaw)
 		if err != nil {
-			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("end_date"), nil, http.StatusBadRequest, false)
+			response := l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("end_date"), nil, http.StatusBadRequest, false)
+			return nil, nil, nil, &response
 		}
 		endDate = &dateParsed
 	} else if timeOffset == 0 {
-		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypeQueryParam, &logutils.ListArgs{"end_date", "time_offset"}, nil, http.StatusBadRequest, false)
+		response := l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypeQueryParam, &logutils.ListArgs{"end_date", "time_offset"}, nil, http.StatusBadRequest, false)
+		return nil, nil, nil, &response
 	}
 
 	if startDate == nil || endDate == nil {
@@ -81,17 +126,7 @@ func (h AnalyticsAPIsHandler) getAnonymousSurveyResponses(l *logs.Log, r *http.R
 		endDate = &now
 	}
 
-	resData, err := h.app.Analytics.GetAnonymousSurveyResponses(surveyTypes, startDate, endDate)
-	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyResponse, nil, err, http.StatusInternalServerError, true)
-	}
-
-	data, err := json.Marshal(resData)
-	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
-	}
-
-	return l.HTTPResponseSuccessJSON(data)
+	return surveyTypes, startDate, endDate, nil
 }
 
 // NewAnalyticsAPIsHandler creates new analytics API handler instance
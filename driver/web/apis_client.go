@@ -41,8 +41,11 @@ func (h ClientAPIsHandler) getSurvey(l *logs.Log, r *http.Request, claims *token
 		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
 	}
 
-	resData, err := h.app.Client.GetSurvey(id, claims.OrgID, claims.AppID)
+	resData, err := h.app.Client.GetSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, claimsPermissions(claims))
 	if err != nil {
+		if _, ok := err.(*model.SurveyAudienceError); ok {
+			return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err, http.StatusForbidden, true)
+		}
 		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
 	}
 
@@ -143,7 +146,7 @@ func (h ClientAPIsHandler) getSurveys(l *logs.Log, r *http.Request, claims *toke
 	}
 	filter := surveyTimeFilter(&timeFilterItems)
 
-	surveys, surverysRsponse, err := h.app.Client.GetSurveys(claims.OrgID, claims.AppID, &claims.Subject, nil, surveyIDs, surveyTypes, calendarEventID,
+	surveys, surverysRsponse, err := h.app.Client.GetSurveys(claims.OrgID, claims.AppID, &claims.Subject, claims.ExternalIDs, claimsPermissions(claims), nil, surveyIDs, surveyTypes, calendarEventID,
 		&limit, &offset, filter, public, archived, completed)
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
@@ -172,9 +175,19 @@ func (h ClientAPIsHandler) createSurvey(l *logs.Log, r *http.Request, claims *to
 	items.Type = "user"
 	item := surveyRequestToSurvey(items)
 
-	createdItem, err := h.app.Client.CreateSurvey(item, claims.ExternalIDs)
+	strict := false
+	strictRaw := r.URL.Query().Get("strict")
+	if len(strictRaw) > 0 {
+		boolParsed, err := strconv.ParseBool(strictRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("strict"), nil, http.StatusBadRequest, false)
+		}
+		strict = boolParsed
+	}
+
+	createdItem, err := h.app.Client.CreateSurvey(item, claims.ExternalIDs, strict, auditContext(l, claims))
 	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionCreate, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+		return surveyErrorResponse(l, logutils.ActionCreate, err)
 	}
 
 	data, err := json.Marshal(createdItem)
@@ -186,6 +199,28 @@ func (h ClientAPIsHandler) createSurvey(l *logs.Log, r *http.Request, claims *to
 
 }
 
+func (h ClientAPIsHandler) validateSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	var items model.SurveyRequest
+	err := json.NewDecoder(r.Body).Decode(&items)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+	items.CreatorID = claims.Subject
+	items.OrgID = claims.OrgID
+	items.AppID = claims.AppID
+	items.Type = "user"
+	item := surveyRequestToSurvey(items)
+
+	resData := h.app.Client.ValidateSurvey(item)
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
 func (h ClientAPIsHandler) updateSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -205,9 +240,19 @@ func (h ClientAPIsHandler) updateSurvey(l *logs.Log, r *http.Request, claims *to
 
 	item := updateSurveyRequestToSurvey(items, id)
 
-	err = h.app.Client.UpdateSurvey(item, claims.Subject, claims.ExternalIDs)
+	strict := false
+	strictRaw := r.URL.Query().Get("strict")
+	if len(strictRaw) > 0 {
+		boolParsed, err := strconv.ParseBool(strictRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("strict"), nil, http.StatusBadRequest, false)
+		}
+		strict = boolParsed
+	}
+
+	err = h.app.Client.UpdateSurvey(item, claims.Subject, claims.ExternalIDs, strict, auditContext(l, claims))
 	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+		return surveyErrorResponse(l, logutils.ActionUpdate, err)
 	}
 
 	return l.HTTPResponseSuccess()
@@ -220,7 +265,7 @@ func (h ClientAPIsHandler) deleteSurvey(l *logs.Log, r *http.Request, claims *to
 		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
 	}
 
-	err := h.app.Client.DeleteSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs)
+	err := h.app.Client.DeleteSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
 	}
@@ -228,6 +273,305 @@ func (h ClientAPIsHandler) deleteSurvey(l *logs.Log, r *http.Request, claims *to
 	return l.HTTPResponseSuccess()
 }
 
+func (h ClientAPIsHandler) publishSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Client.PublishSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, auditContext(l, claims))
+	if err != nil {
+		return surveyErrorResponse(l, logutils.ActionUpdate, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) getSurveyTemplates(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	mine := false
+	mineRaw := r.URL.Query().Get("mine")
+	if len(mineRaw) > 0 {
+		boolParsed, err := strconv.ParseBool(mineRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("mine"), nil, http.StatusBadRequest, false)
+		}
+		mine = boolParsed
+	}
+	var category *string
+	if categoryRaw := r.URL.Query().Get("category"); len(categoryRaw) > 0 {
+		category = &categoryRaw
+	}
+	var limit *int
+	limitRaw := r.URL.Query().Get("limit")
+	if len(limitRaw) > 0 {
+		intParsed, err := strconv.Atoi(limitRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("limit"), nil, http.StatusBadRequest, false)
+		}
+		limit = &intParsed
+	}
+	var offset *int
+	offsetRaw := r.URL.Query().Get("offset")
+	if len(offsetRaw) > 0 {
+		intParsed, err := strconv.Atoi(offsetRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("offset"), nil, http.StatusBadRequest, false)
+		}
+		offset = &intParsed
+	}
+
+	resData, err := h.app.Client.GetSurveyTemplates(claims.OrgID, claims.AppID, claims.Subject, mine, category, limit, offset)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyTemplate, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) setSurveyTemplate(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	var item model.SurveyTemplate
+	err := json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+
+	resData, err := h.app.Client.SetSurveyTemplate(id, claims.OrgID, claims.AppID, claims.Subject, item, auditContext(l, claims))
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeSurveyTemplate, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) removeSurveyTemplate(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Client.RemoveSurveyTemplate(id, claims.OrgID, claims.AppID, claims.Subject, auditContext(l, claims))
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeSurveyTemplate, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) cloneSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	var overrides model.SurveyCloneOverrides
+	err := json.NewDecoder(r.Body).Decode(&overrides)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+
+	resData, err := h.app.Client.CloneSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, overrides, auditContext(l, claims))
+	if err != nil {
+		return surveyErrorResponse(l, logutils.ActionCreate, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) getEventSurveySubscription(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	eventID := vars["event_id"]
+	if len(eventID) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("event_id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Client.GetEventSurveySubscription(eventID, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeEventSurveySubscription, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) subscribeEventSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	eventID := vars["event_id"]
+	if len(eventID) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("event_id"), nil, http.StatusBadRequest, false)
+	}
+
+	var item model.EventSurveySubscription
+	err := json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+	item.CalendarEventID = eventID
+	item.OrgID = claims.OrgID
+	item.AppID = claims.AppID
+	item.CreatorID = claims.Subject
+
+	resData, err := h.app.Client.SubscribeEventSurvey(item, claims.ExternalIDs)
+	if err != nil {
+		if _, ok := err.(*model.DuplicateKeyError); ok {
+			return l.HTTPResponseErrorAction(logutils.ActionSave, model.TypeEventSurveySubscription, nil, err, http.StatusConflict, true)
+		}
+		return l.HTTPResponseErrorAction(logutils.ActionSave, model.TypeEventSurveySubscription, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) unsubscribeEventSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	eventID := vars["event_id"]
+	if len(eventID) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("event_id"), nil, http.StatusBadRequest, false)
+	}
+
+	err := h.app.Client.UnsubscribeEventSurvey(eventID, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeEventSurveySubscription, nil, err, http.StatusInternalServerError, true)
+	}
+
+	return l.HTTPResponseSuccess()
+}
+
+func (h ClientAPIsHandler) getSurveyReminderMutes(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	resData, err := h.app.Client.GetSurveyReminderMutes(claims.OrgID, claims.AppID, claims.Subject)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyReminderMute, nil, err, http.StatusInternalServerError, true)
+	}
+	if resData == nil {
+		resData = []model.SurveyReminderMute{}
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) muteSurveyReminders(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	surveyID := r.URL.Query().Get("survey_id")
+
+	resData, err := h.app.Client.MuteSurveyReminders(claims.OrgID, claims.AppID, claims.Subject, surveyID)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionInsert, model.TypeSurveyReminderMute, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) unmuteSurveyReminders(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	surveyID := r.URL.Query().Get("survey_id")
+
+	err := h.app.Client.UnmuteSurveyReminders(claims.OrgID, claims.AppID, claims.Subject, surveyID)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeSurveyReminderMute, nil, err, http.StatusInternalServerError, true)
+	}
+
+	return l.HTTPResponseSuccess()
+}
+
+func (h ClientAPIsHandler) unpublishSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Client.UnpublishSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, auditContext(l, claims))
+	if err != nil {
+		return surveyErrorResponse(l, logutils.ActionUpdate, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) evaluateSurvey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	var item model.Survey
+	err := json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+
+	resData, err := h.app.Client.EvaluateSurvey(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, claimsPermissions(claims), item.Data)
+	if err != nil {
+		if _, ok := err.(*model.SurveyAudienceError); ok {
+			return l.HTTPResponseErrorAction(logutils.ActionApply, model.TypeSurveyEvaluation, nil, err, http.StatusForbidden, true)
+		}
+		return l.HTTPResponseErrorAction(logutils.ActionApply, model.TypeSurveyEvaluation, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
 func (h ClientAPIsHandler) getAllSurveyResponses(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
 	vars := mux.Vars(r)
 	surveyID := vars["id"]
@@ -272,9 +616,97 @@ func (h ClientAPIsHandler) getAllSurveyResponses(l *logs.Log, r *http.Request, c
 		offset = intParsed
 	}
 
-	resData, err := h.app.Client.GetAllSurveyResponses(claims.OrgID, claims.AppID, claims.Subject, surveyID, startDate, endDate, &limit, &offset, claims.ExternalIDs)
+	validate := false
+	validateRaw := r.URL.Query().Get("validate")
+	if len(validateRaw) > 0 {
+		boolParsed, err := strconv.ParseBool(validateRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("validate"), nil, http.StatusBadRequest, false)
+		}
+		validate = boolParsed
+	}
+
+	resData, err := h.app.Client.GetAllSurveyResponses(claims.OrgID, claims.AppID, claims.Subject, surveyID, startDate, endDate, &limit, &offset, claims.ExternalIDs, validate)
 	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
+		return surveyResponsesErrorResponse(l, logutils.ActionGet, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) exportSurveyResponses(l *logs.Log, r *http.Request, claims *tokenauth.Claims, w http.ResponseWriter) *logs.HTTPResponse {
+	vars := mux.Vars(r)
+	surveyID := vars["id"]
+
+	startDateRaw := r.URL.Query().Get("start_date")
+	var startDate *time.Time
+	if len(startDateRaw) > 0 {
+		dateParsed, err := time.Parse(time.RFC3339, startDateRaw)
+		if err != nil {
+			response := l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("start_date"), nil, http.StatusBadRequest, false)
+			return &response
+		}
+		startDate = &dateParsed
+	}
+
+	endDateRaw := r.URL.Query().Get("end_date")
+	var endDate *time.Time
+	if len(endDateRaw) > 0 {
+		dateParsed, err := time.Parse(time.RFC3339, endDateRaw)
+		if err != nil {
+			response := l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("end_date"), nil, http.StatusBadRequest, false)
+			return &response
+		}
+		endDate = &dateParsed
+	}
+
+	writer, err := newExportWriter(w, vars["format"], "survey-"+surveyID+"-responses")
+	if err != nil {
+		response := l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("format"), nil, http.StatusBadRequest, false)
+		return &response
+	}
+
+	err = h.app.Client.ExportSurveyResponses(claims.OrgID, claims.AppID, claims.Subject, surveyID, startDate, endDate, claims.ExternalIDs, writer)
+	return finishExport(l, writer, err)
+}
+
+func (h ClientAPIsHandler) getSurveyResults(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	startDateRaw := r.URL.Query().Get("start_date")
+	var startDate *time.Time
+	if len(startDateRaw) > 0 {
+		dateParsed, err := time.Parse(time.RFC3339, startDateRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("start_date"), nil, http.StatusBadRequest, false)
+		}
+		startDate = &dateParsed
+	}
+
+	endDateRaw := r.URL.Query().Get("end_date")
+	var endDate *time.Time
+	if len(endDateRaw) > 0 {
+		dateParsed, err := time.Parse(time.RFC3339, endDateRaw)
+		if err != nil {
+			return l.HTTPResponseErrorData(logutils.StatusInvalid, logutils.TypeQueryParam, logutils.StringArgs("end_date"), nil, http.StatusBadRequest, false)
+		}
+		endDate = &dateParsed
+	}
+
+	interval := r.URL.Query().Get("interval")
+
+	resData, err := h.app.Client.GetSurveyResults(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, startDate, endDate, interval)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyResults, nil, err, http.StatusInternalServerError, true)
 	}
 
 	data, err := json.Marshal(resData)
@@ -378,9 +810,13 @@ func (h ClientAPIsHandler) createSurveyResponse(l *logs.Log, r *http.Request, cl
 	item.AppID = claims.AppID
 	item.CreatorID = claims.Subject
 
-	createdItem, err := h.app.Client.CreateSurveyResponse(model.SurveyResponse{UserID: claims.Subject, AppID: claims.AppID, OrgID: claims.OrgID, Survey: item}, claims.ExternalIDs)
+	// retries of the same request use the same idempotency key so they do not create duplicate responses
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+
+	createdItem, err := h.app.Client.CreateSurveyResponse(model.SurveyResponse{UserID: claims.Subject, AppID: claims.AppID, OrgID: claims.OrgID, Survey: item,
+		IdempotencyKey: idempotencyKey}, claims.ExternalIDs, claimsPermissions(claims), auditContext(l, claims))
 	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionCreate, model.TypeSurveyResponse, nil, err, http.StatusInternalServerError, true)
+		return surveyResponseErrorResponse(l, logutils.ActionCreate, err)
 	}
 
 	data, err := json.Marshal(createdItem)
@@ -404,19 +840,88 @@ func (h ClientAPIsHandler) updateSurveyResponse(l *logs.Log, r *http.Request, cl
 		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
 	}
 
-	item.ID = id
 	item.OrgID = claims.OrgID
 	item.AppID = claims.AppID
 	item.CreatorID = claims.Subject
 
-	err = h.app.Client.UpdateSurveyResponse(model.SurveyResponse{UserID: claims.Subject, AppID: claims.AppID, OrgID: claims.OrgID, Survey: item})
+	err = h.app.Client.UpdateSurveyResponse(model.SurveyResponse{ID: id, UserID: claims.Subject, AppID: claims.AppID, OrgID: claims.OrgID, Survey: item}, claims.ExternalIDs, claimsPermissions(claims), auditContext(l, claims))
 	if err != nil {
-		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeSurveyResponse, nil, err, http.StatusInternalServerError, true)
+		return surveyResponseErrorResponse(l, logutils.ActionUpdate, err)
 	}
 
 	return l.HTTPResponseSuccess()
 }
 
+func (h ClientAPIsHandler) getSurveyResponseDraft(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Client.GetSurveyResponseDraft(id, claims.OrgID, claims.AppID, claims.Subject)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurveyResponse, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) saveSurveyResponseDraft(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+	key := vars["key"]
+	if len(key) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("key"), nil, http.StatusBadRequest, false)
+	}
+
+	var item model.SurveyData
+	err := json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionDecode, logutils.TypeRequestBody, nil, err, http.StatusBadRequest, true)
+	}
+
+	resData, err := h.app.Client.SaveSurveyResponseDraft(id, claims.OrgID, claims.AppID, claims.Subject, key, item, claims.ExternalIDs, claimsPermissions(claims), auditContext(l, claims))
+	if err != nil {
+		return surveyResponseErrorResponse(l, logutils.ActionSave, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h ClientAPIsHandler) finalizeSurveyResponseDraft(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.Client.FinalizeSurveyResponseDraft(id, claims.OrgID, claims.AppID, claims.Subject, claims.ExternalIDs, claimsPermissions(claims), auditContext(l, claims))
+	if err != nil {
+		return surveyResponseErrorResponse(l, logutils.ActionUpdate, err)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
 func (h ClientAPIsHandler) deleteSurveyResponse(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -424,7 +929,7 @@ func (h ClientAPIsHandler) deleteSurveyResponse(l *logs.Log, r *http.Request, cl
 		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("id"), nil, http.StatusBadRequest, false)
 	}
 
-	err := h.app.Client.DeleteSurveyResponse(id, claims.OrgID, claims.AppID, claims.Subject)
+	err := h.app.Client.DeleteSurveyResponse(id, claims.OrgID, claims.AppID, claims.Subject, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeSurveyResponse, nil, err, http.StatusInternalServerError, true)
 	}
@@ -462,7 +967,7 @@ func (h ClientAPIsHandler) deleteSurveyResponses(l *logs.Log, r *http.Request, c
 		endDate = &dateParsed
 	}
 
-	err := h.app.Client.DeleteSurveyResponses(claims.OrgID, claims.AppID, claims.Subject, surveyIDs, surveyTypes, startDate, endDate)
+	err := h.app.Client.DeleteSurveyResponses(claims.OrgID, claims.AppID, claims.Subject, surveyIDs, surveyTypes, startDate, endDate, auditContext(l, claims))
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionDelete, model.TypeSurveyResponse, nil, err, http.StatusInternalServerError, true)
 	}
@@ -480,12 +985,17 @@ func (h ClientAPIsHandler) createSurveyAlert(l *logs.Log, r *http.Request, claim
 	item.OrgID = claims.OrgID
 	item.AppID = claims.AppID
 
-	err = h.app.Client.CreateSurveyAlert(item)
+	resData, err := h.app.Client.CreateSurveyAlert(item)
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionCreate, model.TypeSurveyAlert, nil, err, http.StatusInternalServerError, true)
 	}
 
-	return l.HTTPResponseSuccess()
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
 }
 
 func (h ClientAPIsHandler) getCreatorSurveys(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
@@ -574,7 +1084,7 @@ func (h ClientAPIsHandler) getCreatorSurveys(l *logs.Log, r *http.Request, claim
 	}
 	filter := surveyTimeFilter(&timeFilterItems)
 
-	resData, _, err := h.app.Client.GetSurveys(claims.OrgID, claims.AppID, &claims.Subject, &claims.Subject, surveyIDs, surveyTypes, "", &limit, &offset, filter, public, archived, completed)
+	resData, _, err := h.app.Client.GetSurveys(claims.OrgID, claims.AppID, &claims.Subject, claims.ExternalIDs, claimsPermissions(claims), &claims.Subject, surveyIDs, surveyTypes, "", &limit, &offset, filter, public, archived, completed)
 	if err != nil {
 		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeSurvey, nil, err, http.StatusInternalServerError, true)
 	}
@@ -14,13 +14,63 @@
 
 package web
 
-import "application/core"
+import (
+	"application/core"
+	"application/core/model"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rokwire/core-auth-library-go/v3/tokenauth"
+	"github.com/rokwire/logging-library-go/v2/logs"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
 
 // SystemAPIsHandler handles the rest system admin APIs implementation
 type SystemAPIsHandler struct {
 	app *core.Application
 }
 
+func (h SystemAPIsHandler) getDataKeys(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	params := mux.Vars(r)
+	orgID := params["org_id"]
+	if len(orgID) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("org_id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.System.GetDataKeys(orgID)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionGet, model.TypeDataKey, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
+func (h SystemAPIsHandler) rotateDataKey(l *logs.Log, r *http.Request, claims *tokenauth.Claims) logs.HTTPResponse {
+	params := mux.Vars(r)
+	orgID := params["org_id"]
+	if len(orgID) <= 0 {
+		return l.HTTPResponseErrorData(logutils.StatusMissing, logutils.TypePathParam, logutils.StringArgs("org_id"), nil, http.StatusBadRequest, false)
+	}
+
+	resData, err := h.app.System.RotateDataKey(orgID)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionUpdate, model.TypeDataKeyRotation, nil, err, http.StatusInternalServerError, true)
+	}
+
+	data, err := json.Marshal(resData)
+	if err != nil {
+		return l.HTTPResponseErrorAction(logutils.ActionMarshal, logutils.TypeResponseBody, nil, err, http.StatusInternalServerError, false)
+	}
+
+	return l.HTTPResponseSuccessJSON(data)
+}
+
 // NewSystemAPIsHandler creates new system admin API handler instance
 func NewSystemAPIsHandler(app *core.Application) SystemAPIsHandler {
 	return SystemAPIsHandler{app: app}
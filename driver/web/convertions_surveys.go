@@ -2,6 +2,7 @@ package web
 
 import (
 	"application/core/model"
+	"application/utils"
 	"sort"
 	"time"
 )
@@ -25,7 +26,8 @@ func surveyRequestToSurvey(item model.SurveyRequest) model.Survey {
 		SurveyStats: item.SurveyStats, Sensitive: item.Sensitive, Anonymous: item.Anonymous, DefaultDataKey: item.DefaultDataKey,
 		DefaultDataKeyRule: item.DefaultDataKeyRule, Constants: item.Constants, Strings: item.Strings, SubRules: item.SubRules,
 		ResponseKeys: item.ResponseKeys, CalendarEventID: item.CalendarEventID, StartDate: startValue, EndDate: endValue,
-		Public: item.Public, Archived: item.Archived, EstimatedCompletionTime: item.EstimatedCompletionTime}
+		Public: item.Public, Archived: item.Archived, EstimatedCompletionTime: item.EstimatedCompletionTime, Status: utils.GetString(item.Status),
+		GracePeriod: item.GracePeriod, ResponsePolicy: item.ResponsePolicy, Recurrence: item.Recurrence, ReminderOffsets: item.ReminderOffsets, Audience: item.Audience, AlertRules: item.AlertRules}
 }
 
 func getSurvey(item model.Survey) model.Survey {
@@ -35,7 +37,9 @@ func getSurvey(item model.Survey) model.Survey {
 		SurveyStats: item.SurveyStats, Sensitive: item.Sensitive, Anonymous: item.Anonymous, DefaultDataKey: item.DefaultDataKey,
 		DefaultDataKeyRule: item.DefaultDataKeyRule, Constants: item.Constants, Strings: item.Strings, SubRules: item.SubRules,
 		ResponseKeys: item.ResponseKeys, CalendarEventID: item.CalendarEventID, StartDate: item.StartDate, EndDate: item.EndDate,
-		Public: item.Public, Archived: item.Archived, EstimatedCompletionTime: item.EstimatedCompletionTime}
+		Public: item.Public, Archived: item.Archived, EstimatedCompletionTime: item.EstimatedCompletionTime, Status: item.Status,
+		GracePeriod: item.GracePeriod, ResponseWindowOverride: item.ResponseWindowOverride, ResponsePolicy: item.ResponsePolicy,
+		Recurrence: item.Recurrence, ReminderOffsets: item.ReminderOffsets}
 }
 
 func getSurveys(items []model.Survey) []model.Survey {
@@ -66,19 +70,37 @@ func updateSurveyRequestToSurvey(item model.SurveyRequest, id string) model.Surv
 		SurveyStats: item.SurveyStats, Sensitive: item.Sensitive, Anonymous: item.Anonymous, DefaultDataKey: item.DefaultDataKey,
 		DefaultDataKeyRule: item.DefaultDataKeyRule, Constants: item.Constants, Strings: item.Strings, SubRules: item.SubRules,
 		ResponseKeys: item.ResponseKeys, CalendarEventID: item.CalendarEventID, StartDate: startValue, EndDate: endValue,
-		Public: item.Public, Archived: item.Archived, EstimatedCompletionTime: item.EstimatedCompletionTime}
+		Public: item.Public, Archived: item.Archived, EstimatedCompletionTime: item.EstimatedCompletionTime, GracePeriod: item.GracePeriod, ResponsePolicy: item.ResponsePolicy,
+		Recurrence: item.Recurrence, ReminderOffsets: item.ReminderOffsets, Audience: item.Audience, AlertRules: item.AlertRules}
 }
 
+// getSurveysResData marks the surveys the user has completed or started. Only the responses in the current response window
+// count for recurring surveys.
 func getSurveysResData(items []model.Survey, surveyResponses []model.SurveyResponse, completed *bool) []model.SurveysResponseData {
 	var list []model.SurveysResponseData
 
+	now := time.Now().UTC()
 	for _, item := range items {
-		var isCompleted bool
+		var isCompleted, isInProgress bool
+
+		var window *model.SurveyWindow
+		if item.Recurrence != nil {
+			window = item.ResponseRecurrenceWindow(now)
+			if window == nil {
+				_, window = item.RecurrenceWindows(now)
+			}
+		}
 
 		for _, surveyResponse := range surveyResponses {
 			if item.ID == surveyResponse.Survey.ID {
-				isCompleted = true
-				break
+				if item.Recurrence != nil && (window == nil || surveyResponse.DateCreated.Before(window.Start)) {
+					continue
+				}
+				if surveyResponse.InProgress() {
+					isInProgress = true
+				} else {
+					isCompleted = true
+				}
 			}
 		}
 
@@ -111,7 +133,11 @@ func getSurveysResData(items []model.Survey, surveyResponses []model.SurveyRespo
 				Archived:                item.Archived,
 				EstimatedCompletionTime: item.EstimatedCompletionTime,
 				Completed:               &isCompleted,
+				InProgress:              &isInProgress,
+				Status:                  item.CurrentStatus(),
 				DateCreated:             item.DateCreated,
+				Recurrence:              item.Recurrence,
+				Window:                  window,
 			})
 		}
 	}
@@ -0,0 +1,288 @@
+// Copyright 2022 Board of Trustees of the University of Illinois.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"application/core/model"
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/rokwire/logging-library-go/v2/logs"
+	"github.com/rokwire/logging-library-go/v2/logutils"
+)
+
+const (
+	exportFormatCSV  string = "csv"
+	exportFormatXLSX string = "xlsx"
+
+	// exportFlushRows is the number of rows written between flushes of the response
+	exportFlushRows int = 100
+)
+
+// streamWriter writes a streamed body to the HTTP response. The response headers are written with the first data, so
+// errors returned before that can still be sent as an error response.
+type streamWriter interface {
+	Close() error
+	Started() bool
+}
+
+// exportWriter writes an export to the HTTP response. The response headers are written with the header row.
+type exportWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []string) error
+	streamWriter
+}
+
+// newExportWriter returns the export writer for the provided format
+func newExportWriter(w http.ResponseWriter, format string, filename string) (exportWriter, error) {
+	switch format {
+	case exportFormatCSV:
+		return &csvExportWriter{w: w, filename: filename + ".csv"}, nil
+	case exportFormatXLSX:
+		return &xlsxExportWriter{w: w, filename: filename + ".xlsx"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %s", format)
+	}
+}
+
+// startExport writes the headers of an export response
+func startExport(w http.ResponseWriter, contentType string, filename string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+}
+
+// flushExport sends the rows written so far to the client
+func flushExport(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// csvExportWriter writes an export as CSV
+type csvExportWriter struct {
+	w        http.ResponseWriter
+	filename string
+
+	csv  *csv.Writer
+	rows int
+}
+
+func (e *csvExportWriter) WriteHeader(columns []string) error {
+	startExport(e.w, "text/csv; charset=utf-8", e.filename)
+	e.csv = csv.NewWriter(e.w)
+	return e.csv.Write(columns)
+}
+
+func (e *csvExportWriter) WriteRow(values []string) error {
+	err := e.csv.Write(values)
+	if err != nil {
+		return err
+	}
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		e.csv.Flush()
+		flushExport(e.w)
+	}
+	return e.csv.Error()
+}
+
+func (e *csvExportWriter) Close() error {
+	if e.csv == nil {
+		return nil
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func (e *csvExportWriter) Started() bool {
+	return e.csv != nil
+}
+
+// xlsxParts are the parts of a workbook with a single worksheet, apart from the worksheet itself
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Responses" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxExportWriter writes an export as an XLSX workbook. The worksheet is the last part of the archive, so its rows are
+// streamed as they are written. All values are written as inline strings.
+type xlsxExportWriter struct {
+	w        http.ResponseWriter
+	filename string
+
+	zip   *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+func (e *xlsxExportWriter) WriteHeader(columns []string) error {
+	startExport(e.w, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", e.filename)
+	e.zip = zip.NewWriter(e.w)
+	for _, part := range xlsxParts {
+		writer, err := e.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(writer, part.content)
+		if err != nil {
+			return err
+		}
+	}
+
+	sheet, err := e.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	e.sheet = sheet
+	_, err = io.WriteString(e.sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return err
+	}
+	return e.writeRow(columns)
+}
+
+func (e *xlsxExportWriter) WriteRow(values []string) error {
+	err := e.writeRow(values)
+	if err != nil {
+		return err
+	}
+	if e.rows%exportFlushRows == 0 {
+		err = e.zip.Flush()
+		if err != nil {
+			return err
+		}
+		flushExport(e.w)
+	}
+	return nil
+}
+
+func (e *xlsxExportWriter) writeRow(values []string) error {
+	e.rows++
+	var row strings.Builder
+	fmt.Fprintf(&row, `<row r="%d">`, e.rows)
+	for _, value := range values {
+		row.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		err := xml.EscapeText(&row, []byte(value))
+		if err != nil {
+			return err
+		}
+		row.WriteString(`</t></is></c>`)
+	}
+	row.WriteString(`</row>`)
+	_, err := io.WriteString(e.sheet, row.String())
+	return err
+}
+
+func (e *xlsxExportWriter) Close() error {
+	if e.zip == nil {
+		return nil
+	}
+	_, err := io.WriteString(e.sheet, `</sheetData></worksheet>`)
+	if err != nil {
+		return err
+	}
+	return e.zip.Close()
+}
+
+func (e *xlsxExportWriter) Started() bool {
+	return e.zip != nil
+}
+
+// ndjsonWriter writes newline-delimited JSON to the HTTP response
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	encoder *json.Encoder
+	lines   int
+}
+
+func (e *ndjsonWriter) WriteLine(value interface{}) error {
+	if e.encoder == nil {
+		e.start()
+	}
+	err := e.encoder.Encode(value)
+	if err != nil {
+		return err
+	}
+	e.lines++
+	if e.lines%exportFlushRows == 0 {
+		flushExport(e.w)
+	}
+	return nil
+}
+
+func (e *ndjsonWriter) start() {
+	e.w.Header().Set("Content-Type", "application/x-ndjson")
+	e.w.WriteHeader(http.StatusOK)
+	e.encoder = json.NewEncoder(e.w)
+}
+
+func (e *ndjsonWriter) Close() error {
+	if e.encoder == nil {
+		// nothing matched, send an empty stream
+		e.start()
+	}
+	flushExport(e.w)
+	return nil
+}
+
+func (e *ndjsonWriter) Started() bool {
+	return e.encoder != nil
+}
+
+// finishExport completes the export and returns the response to send, if any. Errors that occur before the export has
+// started are sent as an error response. Errors after that abort the response, so the client does not receive a truncated export.
+func finishExport(l *logs.Log, writer streamWriter, err error) *logs.HTTPResponse {
+	if err == nil {
+		err = writer.Close()
+		if err == nil {
+			return nil
+		}
+	}
+	if !writer.Started() {
+		response := surveyResponsesErrorResponse(l, logutils.ActionGet, err)
+		return &response
+	}
+
+	l.LogError(logutils.MessageAction(logutils.StatusError, logutils.ActionSend, model.TypeSurveyResponse, nil), err)
+	panic(http.ErrAbortHandler)
+}
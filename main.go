@@ -19,7 +19,9 @@ import (
 	"application/driven/calendar"
 	corebb "application/driven/core"
 	"application/driven/notifications"
+	"application/driven/sms"
 	"application/driven/storage"
+	"application/driven/webhooks"
 	"application/driver/web"
 	"strings"
 
@@ -59,7 +61,8 @@ func main() {
 	mongoDBAuth := envLoader.GetAndLogEnvVar(envPrefix+"MONGO_AUTH", true, true)
 	mongoDBName := envLoader.GetAndLogEnvVar(envPrefix+"MONGO_DATABASE", true, false)
 	mongoTimeout := envLoader.GetAndLogEnvVar(envPrefix+"MONGO_TIMEOUT", false, false)
-	storageAdapter := storage.NewStorageAdapter(mongoDBAuth, mongoDBName, mongoTimeout, logger)
+	dataEncryptionKey := envLoader.GetAndLogEnvVar(envPrefix+"DATA_ENCRYPTION_KEY", false, true)
+	storageAdapter := storage.NewStorageAdapter(mongoDBAuth, mongoDBName, mongoTimeout, dataEncryptionKey, logger)
 	err := storageAdapter.Start()
 	if err != nil {
 		logger.Fatalf("Cannot start the mongoDB adapter: %v", err)
@@ -140,12 +143,21 @@ func main() {
 		logger.Fatalf("Error initializing calendar adapter: %v", err)
 	}
 
+	// Webhooks adapter
+	webhooksAdapter := webhooks.NewWebhooksAdapter()
+
+	// SMS adapter
+	smsProviderURL := envLoader.GetAndLogEnvVar(envPrefix+"SMS_PROVIDER_URL", false, false)
+	smsProviderToken := envLoader.GetAndLogEnvVar(envPrefix+"SMS_PROVIDER_TOKEN", false, true)
+	smsFromPhone := envLoader.GetAndLogEnvVar(envPrefix+"SMS_FROM_PHONE", false, false)
+	smsAdapter := sms.NewSMSAdapter(smsProviderURL, smsProviderToken, smsFromPhone)
+
 	//core adapter
 	coreAdapter := corebb.NewCoreAdapter(coreBBBaseURL, serviceAccountManager)
 
 	// Application
 	application := core.NewApplication(Version, Build, storageAdapter, notificationsAdapter,
-		calendarAdapter, coreAdapter, serviceID, logger)
+		calendarAdapter, webhooksAdapter, smsAdapter, coreAdapter, serviceID, logger)
 	application.Start()
 
 	// Web adapter
@@ -16,6 +16,7 @@ package utils
 
 import (
 	"crypto/sha256"
+	"reflect"
 	"time"
 )
 
@@ -48,3 +49,73 @@ func SHA256Hash(data []byte) []byte {
 	hash := sha256.Sum256(data)
 	return hash[:]
 }
+
+// ValuesEqual compares two values, treating all numeric types as equal if they have the same value
+func ValuesEqual(a interface{}, b interface{}) bool {
+	aNumber, aOk := ToFloat64(a)
+	bNumber, bOk := ToFloat64(b)
+	if aOk && bOk {
+		return aNumber == bNumber
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// ToFloat64 converts a numeric value of any type to a float64
+func ToFloat64(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case float32:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case int32:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	}
+	return 0, false
+}
+
+// NormalizeValue converts lists and documents (eg. as decoded from storage) to []interface{} and map[string]interface{}
+func NormalizeValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		normalized := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			normalized[iter.Key().String()] = NormalizeValue(iter.Value().Interface())
+		}
+		return normalized
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		// ordered documents are stored as a list of key/value elements
+		if elem := rv.Type().Elem(); elem.Kind() == reflect.Struct {
+			keyField, hasKey := elem.FieldByName("Key")
+			_, hasValue := elem.FieldByName("Value")
+			if hasKey && hasValue && keyField.Type.Kind() == reflect.String {
+				normalized := make(map[string]interface{}, rv.Len())
+				for i := 0; i < rv.Len(); i++ {
+					normalized[rv.Index(i).FieldByName("Key").String()] = NormalizeValue(rv.Index(i).FieldByName("Value").Interface())
+				}
+				return normalized
+			}
+		}
+		normalized := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			normalized[i] = NormalizeValue(rv.Index(i).Interface())
+		}
+		return normalized
+	}
+	return value
+}